@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"io"
 	"log"
 	"net/http"
 	"os"
@@ -18,7 +19,12 @@ import (
 	// DART
 	dartAPI "dx-unified/internal/dart/api"
 	dartDB "dx-unified/internal/dart/database"
+	dartModels "dx-unified/internal/dart/models"
 	dartScheduler "dx-unified/internal/dart/scheduler"
+	dartSearch "dx-unified/internal/dart/search"
+	dartStorage "dx-unified/internal/dart/storage"
+	"dx-unified/internal/dart/taxonomy"
+	"dx-unified/pkg/dart"
 
 	// Judal
 	judalAPI "dx-unified/internal/judal/api"
@@ -38,8 +44,21 @@ import (
 	"dx-unified/internal/news/fetcher"
 	"dx-unified/internal/news/fetcher/naver"
 	"dx-unified/internal/news/fetcher/newsapi"
+	"dx-unified/internal/news/fetcher/rss"
 	"dx-unified/internal/news/pipeline"
+	"dx-unified/internal/news/quota"
+	"dx-unified/internal/news/registry"
+	"dx-unified/internal/news/sink"
+	newsStorePkg "dx-unified/internal/news/store"
 	newsMeili "dx-unified/internal/news/store/meili"
+	newsSQLite "dx-unified/internal/news/store/sqlite"
+	"dx-unified/internal/news/trending"
+
+	// Reports and calendars
+	"dx-unified/internal/calendar"
+	calendarAPI "dx-unified/internal/calendar/api"
+	"dx-unified/internal/report"
+	reportAPI "dx-unified/internal/report/api"
 
 	"github.com/gin-gonic/gin"
 )
@@ -59,6 +78,47 @@ func main() {
 			log.Printf("[DART] Failed to initialize database: %v", err)
 		} else {
 			log.Println("[DART] Database initialized")
+			// Classify filings stored before the taxonomy existed and link
+			// their corrections
+			go func() {
+				if n, err := taxonomy.Reclassify(dartDB.DB, false); err != nil {
+					log.Printf("[DART] Reclassify failed: %v", err)
+				} else if n > 0 {
+					log.Printf("[DART] Classified %d filings", n)
+				}
+				if n, err := taxonomy.LinkCorrections(dartDB.DB, nil); err != nil {
+					log.Printf("[DART] Linking corrections failed: %v", err)
+				} else if n > 0 {
+					log.Printf("[DART] Linked %d corrections to their originals", n)
+				}
+			}()
+		}
+	}
+
+	// OpenDART daily request budget shared by all DART jobs
+	var dartBudget *dart.Budget
+	if dartDB.DB != nil {
+		dartBudget = dart.NewBudget(cfg.DartBudgetPath, cfg.DartDailyLimit)
+	}
+
+	// Content-addressed DART document storage (local or S3-compatible)
+	var dartBlobs *dartStorage.Blobs
+	if dartDB.DB != nil {
+		if store, err := dartStorage.NewStore(cfg); err != nil {
+			log.Printf("[DART] Failed to initialize document store: %v", err)
+		} else {
+			dartBlobs = dartStorage.NewBlobs(store)
+			log.Printf("[DART] Document store: %s", store.URI(""))
+			// Move documents downloaded before blob storage into the store
+			go func() {
+				res, err := dartStorage.MigrateDocuments(dartDB.DB, dartBlobs, 0)
+				if err != nil {
+					log.Printf("[DART] Document migration failed: %v", err)
+				} else if res.Migrated+res.Deduplicated+res.Requeued+res.Failed > 0 {
+					log.Printf("[DART] Migrated %d documents (%d deduplicated, %d requeued, %d failed)",
+						res.Migrated, res.Deduplicated, res.Requeued, res.Failed)
+				}
+			}()
 		}
 	}
 
@@ -76,15 +136,26 @@ func main() {
 		log.Println("[CANDLE] DuckDB initialized with Hive partition support")
 	}
 
-	// News Store (Meilisearch)
-	var newsStore *newsMeili.Store
-	if cfg.MeiliHost != "" {
-		var err error
-		newsStore, err = newsMeili.New(cfg.MeiliHost, cfg.MeiliAPIKey)
+	// News Store (Meilisearch or SQLite FTS5)
+	var newsStore newsStorePkg.NewsStore
+	switch cfg.NewsStoreBackend {
+	case newsStorePkg.BackendSQLite:
+		sqliteStore, err := newsSQLite.New(cfg.NewsDBPath)
 		if err != nil {
-			log.Printf("[NEWS] Failed to initialize Meilisearch: %v", err)
+			log.Printf("[NEWS] Failed to initialize SQLite store: %v", err)
 		} else {
-			log.Println("[NEWS] Meilisearch connected")
+			newsStore = sqliteStore
+			log.Println("[NEWS] SQLite store initialized")
+		}
+	default:
+		if cfg.MeiliHost != "" {
+			meiliStore, err := newsMeili.New(cfg.MeiliHost, cfg.MeiliAPIKey)
+			if err != nil {
+				log.Printf("[NEWS] Failed to initialize Meilisearch: %v", err)
+			} else {
+				newsStore = meiliStore
+				log.Println("[NEWS] Meilisearch connected")
+			}
 		}
 	}
 
@@ -130,13 +201,85 @@ func main() {
 		candleSvc = candles.NewService(kiwoomClient, alpacaClient)
 	}
 
+	// Full-text search over filings in the DART DB (needs the sqlite_fts5 tag)
+	var dartIndex *dartSearch.Index
+	if dartDB.DB != nil {
+		idx, err := dartSearch.New(dartDB.DB, dartBlobs)
+		if err != nil {
+			log.Printf("[DART] Search disabled: %v", err)
+		} else {
+			dartIndex = idx
+		}
+	}
+
+	// DART Jobs (also run backfills requested through the API). Without a
+	// document store only the document download and extraction are off.
+	var dartJobs *dartScheduler.DartJobs
+	if cfg.DartAPIKey != "" && dartDB.DB != nil {
+		dartJobs = dartScheduler.NewDartJobs(cfg.DartAPIKey, cfg.StorageDir, dartBudget, dartBlobs)
+	}
+
 	// News Service
 	var newsProcessor *pipeline.Processor
+	var trendTracker *trending.Tracker
+	var newsQuota *quota.Manager
+	var newsRegistry *registry.Registry
+	var newsParquet *sink.Parquet
 	if newsStore != nil {
 		naverFetcher := naver.New(cfg)
 		newsapiFetcher := newsapi.New(cfg)
-		fetchers := []fetcher.Fetcher{naverFetcher, newsapiFetcher}
+		fetchers := []fetcher.Fetcher{naverFetcher, newsapiFetcher, rss.New(cfg)}
 		newsProcessor = pipeline.NewProcessor(cfg, newsStore, fetchers)
+
+		// Queries and feeds managed at runtime via /news/sources and /news/queries
+		var err error
+		newsRegistry, err = registry.New(cfg.NewsRegistryPath, cfg)
+		if err != nil {
+			log.Printf("[NEWS] Failed to load source registry, using configured queries: %v", err)
+		} else {
+			newsProcessor.SetRegistry(newsRegistry)
+		}
+
+		// Analytics copy of processed articles, joinable with candles in DuckDB
+		newsParquet = sink.NewParquet(cfg.NewsParquetDir)
+		if candleDB.DB != nil {
+			if err := newsParquet.AttachDuckDB(candleDB.DB); err != nil {
+				log.Printf("[NEWS] Failed to register %s view: %v", sink.ViewName, err)
+			}
+		}
+		newsProcessor.SetParquetSink(newsParquet)
+
+		// Per-source request budgets (NewsAPI ~100/day, Naver daily cap)
+		newsQuota = quota.NewManager(cfg.NewsQuotaPath, cfg.NewsDailyBudgets, cfg.NewsMonthlyBudgets)
+		newsProcessor.SetQuota(newsQuota)
+
+		// Trending keywords; listed company names from DART become
+		// dictionary nouns that resolve to tickers
+		trendDict := trending.NewDictionary(cfg.NewsTrendingTerms)
+		if dartDB.DB != nil {
+			var corps []dartModels.Corp
+			if err := dartDB.DB.Where("stock_code <> ''").Find(&corps).Error; err != nil {
+				log.Printf("[NEWS] Failed to load corp names for trending: %v", err)
+			}
+			names := make(map[string]string, len(corps))
+			for _, corp := range corps {
+				names[corp.CorpName] = corp.StockCode
+			}
+			trendDict.AddTickers(names)
+		}
+		trendTracker = trending.NewTracker(cfg.NewsTrendingPath, trendDict)
+		newsProcessor.SetTrendTracker(trendTracker)
+
+		if trendTracker.Empty() {
+			go func() {
+				n, err := trendTracker.Backfill(newsStore)
+				if err != nil {
+					log.Printf("[NEWS] Trending backfill failed: %v", err)
+					return
+				}
+				log.Printf("[NEWS] Trending backfilled with %d articles", n)
+			}()
+		}
 	}
 
 	// ========== Register API Routes ==========
@@ -144,6 +287,16 @@ func main() {
 	// DART API (/dart/*)
 	if dartDB.DB != nil {
 		dartHandler := dartAPI.NewHandler(dartDB.GetDB())
+		if dartBlobs != nil {
+			dartHandler.SetDocumentStore(dartBlobs)
+		}
+		if dartIndex != nil {
+			dartHandler.SetSearchIndex(dartIndex)
+		}
+		if dartJobs != nil {
+			dartHandler.SetBackfillRunner(dartJobs.RunBackfills)
+			dartHandler.SetOwnershipRefresher(dartJobs.RefreshOwnership)
+		}
 		dartHandler.RegisterRoutes(r.Group(""))
 		log.Println("[DART] API routes registered")
 	}
@@ -164,15 +317,47 @@ func main() {
 
 	// News API (/news/*)
 	if newsStore != nil {
-		newsHandler := newsAPI.NewHandler(newsStore)
+		newsHandler := newsAPI.NewHandler(newsStore, newsProcessor)
 		newsHandler.RegisterRoutes(r.Group(""))
+
+		newsAnalysisHandler := newsAPI.NewAnalysisHandler(cfg, newsStore)
+		newsAnalysisHandler.RegisterRoutes(r.Group(""))
+
+		newsTrendingHandler := newsAPI.NewTrendingHandler(trendTracker)
+		newsTrendingHandler.RegisterRoutes(r.Group(""))
+
+		if newsRegistry != nil {
+			newsSourceHandler := newsAPI.NewSourceHandler(newsRegistry)
+			newsSourceHandler.RegisterRoutes(r.Group(""))
+		}
+
+		newsSearchAdminHandler := newsAPI.NewSearchAdminHandler(newsStore, newsProcessor)
+		newsSearchAdminHandler.RegisterRoutes(r.Group(""))
 		log.Println("[NEWS] API routes registered")
 	}
 
+	// Report API (/reports/*)
+	// Every source is optional; missing ones show up as report warnings
+	var reportJudal *judalDB.Repository
+	if judalDB.DB != nil {
+		reportJudal = judalDB.NewRepository()
+	}
+	dailyReports := report.NewBuilder(cfg.StorageDir, reportJudal, dartDB.GetDB(), newsStore, newsQuota)
+	reportHandler := reportAPI.NewHandler(dailyReports)
+	reportHandler.RegisterRoutes(r.Group(""))
+	log.Println("[REPORT] API routes registered")
+
+	// Calendar API (/calendar/*)
+	// Built from the events extracted from DART filings; themes need Judal
+	calendarService := calendar.NewService(dartDB.GetDB(), reportJudal)
+	calendarHandler := calendarAPI.NewHandler(calendarService)
+	calendarHandler.RegisterRoutes(r.Group(""))
+	log.Println("[CALENDAR] API routes registered")
+
 	// Admin API (/admin/*)
 	// Inject Judal Repo and Dart DB
 	adminRepo := judalDB.NewRepository() // Creates repo using global DB instance
-	adminHandler := adminAPI.NewHandler(adminRepo, dartDB.GetDB())
+	adminHandler := adminAPI.NewHandler(adminRepo, dartDB.GetDB(), newsStore, newsQuota, dartBudget)
 	adminHandler.RegisterRoutes(r.Group(""))
 	log.Println("[ADMIN] API routes registered")
 
@@ -180,13 +365,33 @@ func main() {
 
 	sched := scheduler.New()
 
+	// Cancelled on shutdown so long-running jobs stop promptly
+	jobCtx, cancelJobs := context.WithCancel(context.Background())
+	defer cancelJobs()
+
+	// Persists the OpenDART request counters
+	if dartBudget != nil {
+		go dartBudget.Run(jobCtx)
+	}
+
 	// DART Jobs
-	if cfg.DartAPIKey != "" && dartDB.DB != nil {
-		dartJobs := dartScheduler.NewDartJobs(cfg.DartAPIKey, cfg.StorageDir)
+	if dartJobs != nil {
 		go dartJobs.InitialSetup()
 		sched.AddJob("DART-FetchFilings", "@hourly", dartJobs.FetchFilings)
-		sched.AddJob("DART-DownloadDocs", "@every 5m", dartJobs.DownloadDocuments)
+		if dartBlobs != nil {
+			sched.AddJob("DART-DownloadDocs", "@every 5m", dartJobs.DownloadDocuments)
+			sched.AddJob("DART-ExtractEvents", "@every 10m", dartJobs.ExtractEvents)
+		} else {
+			log.Println("[DART] Document download and event extraction disabled: no document store")
+		}
 		sched.AddJob("DART-UpdateCorpCodes", "@weekly", dartJobs.UpdateCorpCodes)
+		// Resumes backfills paused by the request budget and interrupted ones
+		sched.AddJob("DART-Backfill", "@every 10m", dartJobs.RunBackfills)
+		// Holdings of corps with new ownership filings
+		sched.AddJob("DART-Ownership", "@every 30m", dartJobs.FetchOwnership)
+	}
+	if dartIndex != nil {
+		sched.AddJob("DART-SearchIndex", "@every 10m", dartIndex.Run)
 	}
 
 	// Judal Jobs (Themes)
@@ -206,10 +411,22 @@ func main() {
 	// News Jobs (Default every 15 mins or from config)
 	if newsProcessor != nil {
 		sched.AddJob("News-Fetch", cfg.NewsFetchCron, func() {
-			newsProcessor.Run()
+			newsProcessor.Run(jobCtx)
+		})
+	}
+
+	// One Parquet file per month instead of one per run and month
+	if newsParquet != nil {
+		sched.AddJob("News-ParquetCompact", "CRON_TZ=Asia/Seoul 30 3 * * *", func() {
+			if err := newsParquet.Compact(); err != nil {
+				log.Printf("[NEWS] Parquet compaction failed: %v", err)
+			}
 		})
 	}
 
+	// Report Jobs (after the KRX close on weekdays)
+	sched.AddJob("Report-Daily", cfg.ReportDailyCron, dailyReports.RunScheduled)
+
 	sched.Start()
 
 	// ========== Start HTTP Server ==========
@@ -227,8 +444,17 @@ func main() {
 		log.Println("")
 		log.Println("  DART (/dart/*):")
 		log.Println("    GET  /dart/corps               - List corporations")
-		log.Println("    GET  /dart/filings             - List filings")
+		log.Println("    GET  /dart/corps/:corp_code/history - Name, stock code and listing status changes")
+		log.Println("    GET  /dart/filings             - List filings (type, category, corp_cls, correction, q)")
 		log.Println("    GET  /dart/filings/:rcept_no   - Get filing detail")
+		log.Println("    GET  /dart/search?q=           - Full-text search of filings (corp_code, type, date_from, date_to)")
+		log.Println("    GET  /dart/taxonomy            - Filing types and categories (POST /reclassify)")
+		log.Println("    GET  /dart/ownership/:corp_code - Holdings by holder over time (POST :corp_code/refresh)")
+		log.Println("    GET  /dart/ownership/trades    - Recent insider buys/sells")
+		log.Println("    GET  /dart/documents?state=failed - Document downloads (POST :id/retry)")
+		log.Println("    GET  /dart/documents/:id/raw   - Download a stored document zip")
+		log.Println("    POST /dart/backfill            - Backfill filings by date range (GET :id for progress)")
+		log.Println("    GET  /dart/gaps                - Weekdays without fetched filings")
 		log.Println("")
 		log.Println("  JUDAL (/judal/*):")
 		log.Println("    GET  /judal/themes             - List themes")
@@ -249,6 +475,23 @@ func main() {
 		log.Println("    GET  /news/articles/:id        - Get article")
 		log.Println("    GET  /news/search?q=...        - Search articles")
 		log.Println("    GET  /news/runs                - Get batch runs")
+		log.Println("    POST /news/reprocess           - Replay archived raw articles in the background")
+		log.Println("    GET  /news/reprocess/:id       - Reprocess job status and report")
+		log.Println("    GET  /news/analysis/event-study - Price reaction to news")
+		log.Println("    GET  /news/trending?window=6h  - Spiking keywords")
+		log.Println("    GET  /news/sources             - Sources and feeds (POST, PATCH/DELETE :name)")
+		log.Println("    GET  /news/queries             - Search queries (POST, PATCH/DELETE :id)")
+		log.Println("    GET  /admin/search/synonyms    - Search synonyms (PUT replace, POST add group)")
+		log.Println("    GET  /admin/search/migrations  - Index versions and migrations (POST start)")
+		log.Println("    POST /admin/search/migrations/:id/rollback - Swap the previous index back")
+		log.Println("")
+		log.Println("  CALENDAR (/calendar/*):")
+		log.Println("    GET  /calendar/dividends       - Cash dividends by record/payment date (?format=ics)")
+		log.Println("    GET  /calendar/earnings        - Preliminary earnings by announcement date (?format=ics)")
+		log.Println("")
+		log.Println("  REPORT (/reports/*):")
+		log.Println("    GET  /reports/daily            - Generated digest dates")
+		log.Println("    GET  /reports/daily/:date      - Daily digest (?format=html|md|json, POST generates)")
 		log.Println("")
 
 		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
@@ -264,8 +507,15 @@ func main() {
 
 	log.Println("Shutting down server...")
 
+	cancelJobs()
 	sched.Stop()
 
+	if dartBudget != nil {
+		if err := dartBudget.Save(); err != nil {
+			log.Printf("[DART] Failed to save request budget: %v", err)
+		}
+	}
+
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
@@ -273,6 +523,11 @@ func main() {
 		log.Printf("Server shutdown error: %v", err)
 	}
 
+	// Stops a running index migration and closes the SQLite news store
+	if closer, ok := newsStore.(io.Closer); ok {
+		closer.Close()
+	}
+
 	// Close databases
 	if judalDB.DB != nil {
 		judalDB.CloseDB()
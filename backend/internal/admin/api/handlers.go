@@ -7,7 +7,10 @@ import (
 	"time"
 
 	"dx-unified/internal/judal/database"
+	"dx-unified/internal/news/quota"
+	newsStore "dx-unified/internal/news/store"
 	"dx-unified/internal/shared/config"
+	"dx-unified/pkg/dart"
 
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
@@ -16,12 +19,18 @@ import (
 type Handler struct {
 	JudalRepo *database.Repository
 	DartDB    *gorm.DB
+	NewsStore newsStore.NewsStore // nil when news is disabled
+	NewsQuota *quota.Manager
+	DartQuota *dart.Budget
 }
 
-func NewHandler(judalRepo *database.Repository, dartDB *gorm.DB) *Handler {
+func NewHandler(judalRepo *database.Repository, dartDB *gorm.DB, news newsStore.NewsStore, newsQuota *quota.Manager, dartQuota *dart.Budget) *Handler {
 	return &Handler{
 		JudalRepo: judalRepo,
 		DartDB:    dartDB,
+		NewsStore: news,
+		NewsQuota: newsQuota,
+		DartQuota: dartQuota,
 	}
 }
 
@@ -82,20 +91,28 @@ func (h *Handler) GetStatus(c *gin.Context) {
 		} else {
 			status["dart"] = map[string]interface{}{"status": "no_data"}
 		}
+		// Remaining OpenDART requests today and paused job priorities
+		if h.DartQuota != nil {
+			status["dart"].(map[string]interface{})["budget"] = h.DartQuota.Report()
+		}
 	}
 
 	// 3. News Status
-	// Since we don't have direct access to MeiliStore here easily without modifying main.go heavily,
-	// We can check if there is a 'news_runs' log in Judal DB or just skip for now?
-	// User asked for "naver news, newsapi".
-	// The `Processor` saves run logs to Meilisearch.
-	// For simplicity, let's just use the current time if we can't check, or maybe check a file?
-	// Actually, let's leave generic "News" as "Check /news/runs" if implemented, or implement it fully.
-	// Since I can't easily inject NewsStore here without breaking imports cycle or adding more deps.
-	// I'll skip detailed news status for this first pass, or just add a placeholder.
-	// WAIT: user specifically asked for "how long ago data was downloaded".
-	// Providing "Judal" and "Dart" is good start.
-	// To support News, I should inject NewsStore.
+	// Latest batch run plus request budgets and upstream errors per source
+	if h.NewsStore != nil {
+		news := map[string]interface{}{}
+		if runs, err := h.NewsStore.ListRuns(1); err == nil && len(runs) > 0 {
+			news["latest_run"] = runs[0]
+			news["since_last_update"] = time.Since(runs[0].EndedAt).String()
+			news["minutes_ago"] = int(time.Since(runs[0].EndedAt).Minutes())
+		} else {
+			news["status"] = "no_data"
+		}
+		if h.NewsQuota != nil {
+			news["sources"] = h.NewsQuota.Report()
+		}
+		status["news"] = news
+	}
 
 	c.JSON(http.StatusOK, gin.H{
 		"timestamp": now,
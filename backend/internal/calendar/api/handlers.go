@@ -0,0 +1,155 @@
+package api
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"time"
+
+	"dx-unified/internal/calendar"
+	"dx-unified/internal/shared/timeutil"
+
+	"github.com/gin-gonic/gin"
+)
+
+const maxRangeDays = 366
+
+// Handler serves the dividend and earnings calendars
+type Handler struct {
+	service *calendar.Service
+}
+
+// NewHandler creates a new calendar handler
+func NewHandler(service *calendar.Service) *Handler {
+	return &Handler{service: service}
+}
+
+// RegisterRoutes registers all calendar routes
+func (h *Handler) RegisterRoutes(rg *gin.RouterGroup) {
+	cal := rg.Group("/calendar")
+	{
+		cal.GET("/dividends", h.GetDividends)
+		cal.GET("/earnings", h.GetEarnings)
+	}
+}
+
+// GetDividends returns cash dividends by record date, or payment date with
+// by=payment. Filters: date_from, date_to (YYYY-MM-DD, default today to 90
+// days ahead), market, theme; format=ics for an iCalendar feed.
+func (h *Handler) GetDividends(c *gin.Context) {
+	by := c.DefaultQuery("by", "record")
+	if by != "record" && by != "payment" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "by must be record or payment"})
+		return
+	}
+	f, ok := h.filter(c, timeutil.Today(), timeutil.Today().AddDate(0, 0, 90))
+	if !ok {
+		return
+	}
+
+	dividends, err := h.service.Dividends(f, by)
+	if err != nil {
+		h.fail(c, err)
+		return
+	}
+	if c.Query("format") == "ics" {
+		var buf bytes.Buffer
+		if err := calendar.DividendsICS(&buf, dividends); err != nil {
+			h.fail(c, err)
+			return
+		}
+		h.ics(c, "dividends.ics", buf.Bytes())
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"data":      dividends,
+		"total":     len(dividends),
+		"date_from": f.From.Format("2006-01-02"),
+		"date_to":   f.To.Format("2006-01-02"),
+		"by":        by,
+	})
+}
+
+// GetEarnings returns preliminary earnings by announcement date. Filters:
+// date_from, date_to (YYYY-MM-DD, default the last 30 days), market, theme;
+// format=ics for an iCalendar feed.
+func (h *Handler) GetEarnings(c *gin.Context) {
+	f, ok := h.filter(c, timeutil.Today().AddDate(0, 0, -30), timeutil.Today())
+	if !ok {
+		return
+	}
+
+	earnings, err := h.service.Earnings(f)
+	if err != nil {
+		h.fail(c, err)
+		return
+	}
+	if c.Query("format") == "ics" {
+		var buf bytes.Buffer
+		if err := calendar.EarningsICS(&buf, earnings); err != nil {
+			h.fail(c, err)
+			return
+		}
+		h.ics(c, "earnings.ics", buf.Bytes())
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"data":      earnings,
+		"total":     len(earnings),
+		"date_from": f.From.Format("2006-01-02"),
+		"date_to":   f.To.Format("2006-01-02"),
+	})
+}
+
+func (h *Handler) filter(c *gin.Context, from, to time.Time) (calendar.Filter, bool) {
+	var err error
+	if s := c.Query("date_from"); s != "" {
+		if from, err = timeutil.ParseDate(s); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return calendar.Filter{}, false
+		}
+	}
+	if s := c.Query("date_to"); s != "" {
+		if to, err = timeutil.ParseDate(s); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return calendar.Filter{}, false
+		}
+	}
+	if to.Before(from) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "date_to is before date_from"})
+		return calendar.Filter{}, false
+	}
+	if to.Sub(from) > maxRangeDays*24*time.Hour {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "range is limited to 366 days"})
+		return calendar.Filter{}, false
+	}
+
+	markets, err := calendar.MarketCodes(c.Query("market"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return calendar.Filter{}, false
+	}
+	if format := c.Query("format"); format != "" && format != "json" && format != "ics" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "format must be json or ics"})
+		return calendar.Filter{}, false
+	}
+	return calendar.Filter{From: from, To: to, Markets: markets, Theme: c.Query("theme")}, true
+}
+
+// ics sends a rendered iCalendar feed as a download
+func (h *Handler) ics(c *gin.Context, filename string, data []byte) {
+	c.Header("Content-Disposition", `attachment; filename="`+filename+`"`)
+	c.Data(http.StatusOK, "text/calendar; charset=utf-8", data)
+}
+
+func (h *Handler) fail(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, calendar.ErrNoDART), errors.Is(err, calendar.ErrNoJudal):
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": err.Error()})
+		return
+	case errors.Is(err, calendar.ErrUnknownTheme):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+}
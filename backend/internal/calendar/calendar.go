@@ -0,0 +1,333 @@
+package calendar
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	candleDB "dx-unified/internal/candle/database"
+	"dx-unified/internal/dart/extract"
+	"dx-unified/internal/dart/taxonomy"
+	judalDB "dx-unified/internal/judal/database"
+	"dx-unified/internal/shared/timeutil"
+
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrNoDART is returned when the DART database is not configured
+	ErrNoDART = errors.New("DART database is not configured")
+	// ErrNoJudal is returned for theme filters when Judal is not configured
+	ErrNoJudal = errors.New("theme filter needs Judal, which is not configured")
+	// ErrUnknownTheme is returned for a theme filter matching no Judal theme
+	ErrUnknownTheme = errors.New("unknown theme")
+)
+
+// Dividend record dates can precede the announcement (year-end dividends
+// are decided in February) and payment follows up to months later, so
+// filings this far around the requested range are considered
+const dividendLookaround = 200 * 24 * time.Hour
+
+// Service builds the dividend and earnings calendars from the events the
+// DART extractor parsed out of 현금ㆍ현물배당결정 and 영업(잠정)실적 filings
+type Service struct {
+	dart  *gorm.DB            // nil when DART is disabled
+	judal *judalDB.Repository // nil when Judal is disabled; needed for theme filters
+}
+
+// NewService creates a calendar service
+func NewService(dart *gorm.DB, judal *judalDB.Repository) *Service {
+	return &Service{dart: dart, judal: judal}
+}
+
+// Filter selects calendar entries. From and To are inclusive KST dates.
+type Filter struct {
+	From    time.Time
+	To      time.Time
+	Markets []string // corp_cls codes (Y, K, N, E); empty means all
+	Theme   string   // Judal theme idx or name
+}
+
+// Entry fields shared by both calendars
+type Entry struct {
+	RceptNo   string `json:"rcept_no"`
+	CorpCode  string `json:"corp_code"`
+	CorpName  string `json:"corp_name"`
+	StockCode string `json:"stock_code"`
+	Market    string `json:"market"`    // KOSPI, KOSDAQ, KONEX or ETC
+	Announced string `json:"announced"` // filing date, YYYY-MM-DD
+	URL       string `json:"url"`
+}
+
+// Dividend is a cash dividend in the calendar
+type Dividend struct {
+	Entry
+	extract.Dividend
+	Close     float64 `json:"close,omitempty"`      // latest close from the candle data
+	CloseDate string  `json:"close_date,omitempty"` // YYYY-MM-DD of that close
+	Yield     float64 `json:"yield,omitempty"`      // dps / close in %
+}
+
+// Earnings is a preliminary earnings announcement in the calendar
+type Earnings struct {
+	Entry
+	extract.Earnings
+	RevenueQoQ         *float64 `json:"revenue_qoq"` // % change vs. the previous quarter
+	OperatingProfitQoQ *float64 `json:"operating_profit_qoq"`
+	RevenueYoY         *float64 `json:"revenue_yoy"` // % change vs. the same quarter a year ago
+	OperatingProfitYoY *float64 `json:"operating_profit_yoy"`
+	// Operating profit vs. the previous quarter: up, down, turnaround
+	// (loss to profit), to_loss or flat
+	Surprise string `json:"surprise,omitempty"`
+}
+
+type eventRow struct {
+	RceptNo     string
+	RceptDt     string
+	CorpCode    string
+	CorpName    string
+	CorpCls     string
+	StockCode   string
+	PayloadJSON string
+}
+
+// events loads the current (not superseded) events of a type filed
+// between two YYYYMMDD dates
+func (s *Service) events(eventType, from, to string, f Filter) ([]eventRow, error) {
+	if s.dart == nil {
+		return nil, ErrNoDART
+	}
+	query := s.dart.Table("extracted_events").
+		Select(`filings.rcept_no, filings.rcept_dt, filings.corp_code, filings.corp_name, filings.corp_cls,
+			COALESCE(NULLIF(filings.stock_code, ''), corps.stock_code, '') AS stock_code,
+			extracted_events.payload_json`).
+		Joins("JOIN filings ON filings.rcept_no = extracted_events.rcept_no").
+		Joins("LEFT JOIN corps ON corps.corp_code = filings.corp_code").
+		Where("extracted_events.event_type = ?", eventType).
+		Where("(extracted_events.superseded = ? OR extracted_events.superseded IS NULL)", false).
+		Where("filings.rcept_dt BETWEEN ? AND ?", from, to)
+	if len(f.Markets) > 0 {
+		query = query.Where("filings.corp_cls IN ?", f.Markets)
+	}
+	if f.Theme != "" {
+		codes, err := s.themeStocks(f.Theme)
+		if err != nil {
+			return nil, err
+		}
+		query = query.Where("COALESCE(NULLIF(filings.stock_code, ''), corps.stock_code) IN ?", codes)
+	}
+
+	var rows []eventRow
+	err := query.Order("filings.rcept_dt, filings.rcept_no").Scan(&rows).Error
+	return rows, err
+}
+
+// themeStocks returns the stock codes of a Judal theme given by idx or name
+func (s *Service) themeStocks(theme string) ([]string, error) {
+	if s.judal == nil {
+		return nil, ErrNoJudal
+	}
+	idx, err := strconv.Atoi(theme)
+	if err != nil {
+		themes, err := s.judal.GetAllThemes()
+		if err != nil {
+			return nil, err
+		}
+		idx = -1
+		for _, t := range themes {
+			if t.Name == theme {
+				idx = t.ThemeIdx
+				break
+			}
+		}
+		if idx < 0 {
+			return nil, fmt.Errorf("%w %q", ErrUnknownTheme, theme)
+		}
+	}
+	stocks, err := s.judal.GetStocksByTheme(idx)
+	if err != nil {
+		return nil, err
+	}
+	codes := make([]string, 0, len(stocks))
+	for _, st := range stocks {
+		codes = append(codes, st.Code)
+	}
+	return codes, nil
+}
+
+func (r eventRow) entry() Entry {
+	return Entry{
+		RceptNo:   r.RceptNo,
+		CorpCode:  r.CorpCode,
+		CorpName:  r.CorpName,
+		StockCode: r.StockCode,
+		Market:    taxonomy.CorpClasses[r.CorpCls],
+		Announced: timeutil.DashDate(r.RceptDt),
+		URL:       "https://dart.fss.or.kr/dsaf001/main.do?rcpNo=" + r.RceptNo,
+	}
+}
+
+// Dividends returns the cash dividends whose record date (by "record") or
+// payment date (by "payment") falls in the filter range, ordered by that
+// date. The yield is computed from the latest close in the candle data.
+func (s *Service) Dividends(f Filter, by string) ([]Dividend, error) {
+	from := f.From.Add(-dividendLookaround).Format("20060102")
+	to := f.To.Add(dividendLookaround).Format("20060102")
+	if today := timeutil.Today().Format("20060102"); to > today {
+		to = today
+	}
+	rows, err := s.events(extract.EventCashDividend, from, to, f)
+	if err != nil {
+		return nil, err
+	}
+
+	rangeFrom, rangeTo := f.From.Format("2006-01-02"), f.To.Format("2006-01-02")
+	dividends := []Dividend{}
+	for _, r := range rows {
+		d := Dividend{Entry: r.entry()}
+		if err := json.Unmarshal([]byte(r.PayloadJSON), &d.Dividend); err != nil {
+			continue
+		}
+		date := d.RecordDate
+		if by == "payment" {
+			date = d.PaymentDate
+		}
+		if date == "" || date < rangeFrom || date > rangeTo {
+			continue
+		}
+		dividends = append(dividends, d)
+	}
+	sort.SliceStable(dividends, func(i, j int) bool {
+		if by == "payment" {
+			return dividends[i].PaymentDate < dividends[j].PaymentDate
+		}
+		return dividends[i].RecordDate < dividends[j].RecordDate
+	})
+
+	s.addYields(dividends)
+	return dividends, nil
+}
+
+// addYields sets the latest close and the yield it implies; it is skipped
+// when the candle data is unavailable
+func (s *Service) addYields(dividends []Dividend) {
+	if candleDB.DB == nil || len(dividends) == 0 {
+		return
+	}
+	var symbols []string
+	seen := make(map[string]bool)
+	for _, d := range dividends {
+		if d.StockCode != "" && !seen[d.StockCode] {
+			seen[d.StockCode] = true
+			symbols = append(symbols, d.StockCode)
+		}
+	}
+	closes, err := candleDB.QueryLatestCloses("KR", symbols, time.Now().Unix())
+	if err != nil {
+		return
+	}
+	for i := range dividends {
+		c, ok := closes[dividends[i].StockCode]
+		if !ok || c.Close <= 0 {
+			continue
+		}
+		dividends[i].Close = c.Close
+		dividends[i].CloseDate = time.Unix(c.TS, 0).In(timeutil.KST).Format("2006-01-02")
+		dividends[i].Yield = round2(dividends[i].DPS / c.Close * 100)
+	}
+}
+
+// Earnings returns the preliminary earnings announced in the filter range
+// with their changes against the previous quarter and a year ago
+func (s *Service) Earnings(f Filter) ([]Earnings, error) {
+	rows, err := s.events(extract.EventEarnings, f.From.Format("20060102"), f.To.Format("20060102"), f)
+	if err != nil {
+		return nil, err
+	}
+
+	earnings := []Earnings{}
+	for _, r := range rows {
+		e := Earnings{Entry: r.entry()}
+		if err := json.Unmarshal([]byte(r.PayloadJSON), &e.Earnings); err != nil {
+			continue
+		}
+		e.RevenueQoQ = change(e.Revenue.Current, e.Revenue.Prior)
+		e.RevenueYoY = change(e.Revenue.Current, e.Revenue.YearAgo)
+		e.OperatingProfitQoQ = change(e.OperatingProfit.Current, e.OperatingProfit.Prior)
+		e.OperatingProfitYoY = change(e.OperatingProfit.Current, e.OperatingProfit.YearAgo)
+		e.Surprise = surprise(e.OperatingProfit.Current, e.OperatingProfit.Prior)
+		earnings = append(earnings, e)
+	}
+	return earnings, nil
+}
+
+// change returns the % change from prior to current, nil when unknown
+func change(current, prior *float64) *float64 {
+	if current == nil || prior == nil || *prior == 0 {
+		return nil
+	}
+	pct := round2((*current - *prior) / abs(*prior) * 100)
+	return &pct
+}
+
+func surprise(current, prior *float64) string {
+	if current == nil || prior == nil {
+		return ""
+	}
+	switch {
+	case *prior <= 0 && *current > 0:
+		return "turnaround"
+	case *prior > 0 && *current <= 0:
+		return "to_loss"
+	case *current > *prior:
+		return "up"
+	case *current < *prior:
+		return "down"
+	}
+	return "flat"
+}
+
+func abs(f float64) float64 {
+	if f < 0 {
+		return -f
+	}
+	return f
+}
+
+func round2(f float64) float64 {
+	if f < 0 {
+		return -float64(int64(-f*100+0.5)) / 100
+	}
+	return float64(int64(f*100+0.5)) / 100
+}
+
+// MarketCodes maps market names (KOSPI, KOSDAQ, KONEX, ETC) or corp_cls
+// codes, comma separated, to corp_cls codes
+func MarketCodes(s string) ([]string, error) {
+	if s == "" {
+		return nil, nil
+	}
+	var codes []string
+	for _, m := range strings.Split(strings.ToUpper(s), ",") {
+		m = strings.TrimSpace(m)
+		if _, ok := taxonomy.CorpClasses[m]; ok {
+			codes = append(codes, m)
+			continue
+		}
+		found := false
+		for code, market := range taxonomy.CorpClasses {
+			if market == m {
+				codes = append(codes, code)
+				found = true
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("unknown market %q, expected KOSPI, KOSDAQ, KONEX or ETC", m)
+		}
+	}
+	return codes, nil
+}
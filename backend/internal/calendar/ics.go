@@ -0,0 +1,176 @@
+package calendar
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// icsEvent is an all-day VEVENT
+type icsEvent struct {
+	UID         string
+	Date        string // YYYY-MM-DD
+	Summary     string
+	Description string
+	URL         string
+}
+
+// DividendsICS renders dividends as an iCalendar feed with an event on the
+// record date and one on the payment date
+func DividendsICS(w io.Writer, dividends []Dividend) error {
+	var events []icsEvent
+	for _, d := range dividends {
+		desc := fmt.Sprintf("%s %s\n1주당 배당금: %s원", d.CorpName, d.Kind, formatAmount(d.DPS))
+		if d.YieldPct > 0 {
+			desc += fmt.Sprintf("\n시가배당율(공시): %.2f%%", d.YieldPct)
+		}
+		if d.Yield > 0 {
+			desc += fmt.Sprintf("\n배당수익률(%s 종가 %s원 기준): %.2f%%", d.CloseDate, formatAmount(d.Close), d.Yield)
+		}
+		desc += "\n배당기준일: " + d.RecordDate
+		if d.PaymentDate != "" {
+			desc += "\n지급예정일: " + d.PaymentDate
+		}
+
+		events = append(events, icsEvent{
+			UID:         d.RceptNo + "-record",
+			Date:        d.RecordDate,
+			Summary:     fmt.Sprintf("[배당기준일] %s %s원", d.CorpName, formatAmount(d.DPS)),
+			Description: desc,
+			URL:         d.URL,
+		})
+		if d.PaymentDate != "" {
+			events = append(events, icsEvent{
+				UID:         d.RceptNo + "-payment",
+				Date:        d.PaymentDate,
+				Summary:     fmt.Sprintf("[배당지급] %s %s원", d.CorpName, formatAmount(d.DPS)),
+				Description: desc,
+				URL:         d.URL,
+			})
+		}
+	}
+	return writeICS(w, "배당 캘린더", events)
+}
+
+// EarningsICS renders earnings announcements as an iCalendar feed
+func EarningsICS(w io.Writer, earnings []Earnings) error {
+	events := make([]icsEvent, 0, len(earnings))
+	for _, e := range earnings {
+		desc := e.CorpName
+		if e.Period != "" {
+			desc += " " + e.Period
+		}
+		desc += "\n매출액: " + formatMetric(e.Revenue.Current, e.RevenueQoQ)
+		desc += "\n영업이익: " + formatMetric(e.OperatingProfit.Current, e.OperatingProfitQoQ)
+
+		summary := "[실적] " + e.CorpName
+		if e.OperatingProfitQoQ != nil {
+			summary += fmt.Sprintf(" 영업이익 %+.1f%% QoQ", *e.OperatingProfitQoQ)
+		}
+		events = append(events, icsEvent{
+			UID:         e.RceptNo + "-earnings",
+			Date:        e.Announced,
+			Summary:     summary,
+			Description: desc,
+			URL:         e.URL,
+		})
+	}
+	return writeICS(w, "실적 캘린더", events)
+}
+
+func writeICS(w io.Writer, name string, events []icsEvent) error {
+	stamp := time.Now().UTC().Format("20060102T150405Z")
+
+	var b strings.Builder
+	line := func(s string) {
+		b.WriteString(foldLine(s))
+		b.WriteString("\r\n")
+	}
+	line("BEGIN:VCALENDAR")
+	line("VERSION:2.0")
+	line("PRODID:-//dx-unified//calendar//KO")
+	line("CALSCALE:GREGORIAN")
+	line("X-WR-CALNAME:" + escapeText(name))
+	line("X-WR-TIMEZONE:Asia/Seoul")
+	for _, e := range events {
+		day, err := time.Parse("2006-01-02", e.Date)
+		if err != nil {
+			continue
+		}
+		line("BEGIN:VEVENT")
+		line("UID:" + e.UID + "@dx-unified")
+		line("DTSTAMP:" + stamp)
+		line("DTSTART;VALUE=DATE:" + day.Format("20060102"))
+		line("DTEND;VALUE=DATE:" + day.AddDate(0, 0, 1).Format("20060102"))
+		line("SUMMARY:" + escapeText(e.Summary))
+		line("DESCRIPTION:" + escapeText(e.Description))
+		if e.URL != "" {
+			line("URL:" + e.URL)
+		}
+		line("END:VEVENT")
+	}
+	line("END:VCALENDAR")
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// escapeText escapes an iCalendar TEXT value (RFC 5545 3.3.11)
+func escapeText(s string) string {
+	return strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\n", `\n`).Replace(s)
+}
+
+// foldLine splits content lines longer than 75 octets without breaking
+// UTF-8 sequences (RFC 5545 3.1)
+func foldLine(s string) string {
+	const limit = 75
+	if len(s) <= limit {
+		return s
+	}
+	var b strings.Builder
+	width := limit
+	for len(s) > width {
+		cut := width
+		for cut > 0 && !utf8.RuneStart(s[cut]) {
+			cut--
+		}
+		b.WriteString(s[:cut])
+		b.WriteString("\r\n ")
+		s = s[cut:]
+		width = limit - 1 // the leading space counts
+	}
+	b.WriteString(s)
+	return b.String()
+}
+
+// formatAmount formats won with thousands separators
+func formatAmount(f float64) string {
+	n := int64(f)
+	neg := n < 0
+	if neg {
+		n = -n
+	}
+	s := fmt.Sprintf("%d", n)
+	for i := len(s) - 3; i > 0; i -= 3 {
+		s = s[:i] + "," + s[i:]
+	}
+	if neg {
+		s = "-" + s
+	}
+	return s
+}
+
+// formatMetric formats an amount in 억원 with its change vs. the previous
+// quarter
+func formatMetric(current, qoq *float64) string {
+	if current == nil {
+		return "-"
+	}
+	s := formatAmount(*current/1e8) + "억원"
+	if qoq != nil {
+		s += fmt.Sprintf(" (전분기 대비 %+.1f%%)", *qoq)
+	}
+	return s
+}
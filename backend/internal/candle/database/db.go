@@ -6,6 +6,7 @@ import (
 	"log"
 	"os"
 	"path/filepath"
+	"strings"
 	"time"
 
 	_ "github.com/marcboeker/go-duckdb"
@@ -157,6 +158,43 @@ func GetParquetGlob(market, year, month string) string {
 	return filepath.Join(pattern, "*.parquet")
 }
 
+// ParquetFilesBetween returns the Parquet files of a market holding bars
+// between from and to. Files are named after the day they were written for
+// (data_YYYYMMDD.parquet), which may differ from the UTC date of their bars
+// by the time zone, so a day of margin is kept on both sides; files with
+// other names are always included.
+func ParquetFilesBetween(market string, from, to time.Time) ([]string, error) {
+	from, to = from.AddDate(0, 0, -1), to.AddDate(0, 0, 1)
+	first, last := from.Format("20060102"), to.Format("20060102")
+
+	var files []string
+	for month := time.Date(from.Year(), from.Month(), 1, 0, 0, 0, 0, time.UTC); month.Format("200601") <= last[:6]; month = month.AddDate(0, 1, 0) {
+		matches, err := filepath.Glob(GetParquetGlob(market, month.Format("2006"), month.Format("01")))
+		if err != nil {
+			return nil, err
+		}
+		for _, path := range matches {
+			var day string
+			if n, _ := fmt.Sscanf(filepath.Base(path), "data_%8s.parquet", &day); n == 1 && len(day) == 8 {
+				if day < first || day > last {
+					continue
+				}
+			}
+			files = append(files, path)
+		}
+	}
+	return files, nil
+}
+
+// sqlList formats paths as a DuckDB list literal for read_parquet
+func sqlList(paths []string) string {
+	quoted := make([]string, len(paths))
+	for i, p := range paths {
+		quoted[i] = "'" + strings.ReplaceAll(p, "'", "''") + "'"
+	}
+	return "[" + strings.Join(quoted, ", ") + "]"
+}
+
 // GetAllParquetGlob returns glob pattern for all markets
 func GetAllParquetGlob() string {
 	return filepath.Join(DataDir, "market=*", "year=*", "month=*", "*.parquet")
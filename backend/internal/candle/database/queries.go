@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"time"
 
 	"dx-unified/internal/candle/model"
@@ -207,3 +208,183 @@ func UpdateIngestRun(run *model.IngestRun) error {
 	`, run.FinishedAt, run.SymbolsCount, run.InsertedRows, run.Status, run.ErrorMessage, run.ID)
 	return err
 }
+
+// GetInstrumentExchange returns the listing exchange (KOSPI, KOSDAQ, NASDAQ...)
+// of an instrument, or "" when it is not in the universe
+func GetInstrumentExchange(market, symbol string) (string, error) {
+	var exchange sql.NullString
+	err := DB.QueryRow(`
+		SELECT exchange FROM instruments WHERE market = ? AND symbol = ?
+	`, market, symbol).Scan(&exchange)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	return exchange.String, err
+}
+
+// timeframeExpr classifies the bars of a market as "1d" or "1m", since the
+// Parquet files do not record the timeframe. Daily bars are stamped at local
+// midnight (KST, or US Eastern with or without DST), which no bar of the
+// regular session is.
+func timeframeExpr(market string) string {
+	midnight := "epoch_ms(timestamp) // 1000 % 86400 = 54000" // 15:00 UTC
+	if market == model.MarketUS {
+		midnight = "epoch_ms(timestamp) // 1000 % 86400 IN (14400, 18000)"
+	}
+	return fmt.Sprintf("CASE WHEN %s THEN '1d' ELSE '1m' END", midnight)
+}
+
+// QueryCandleSeries returns the bars of one symbol and timeframe ("1m" or
+// "1d") between tsFrom and tsTo (UTC epoch sec, inclusive) in ascending time
+// order. Like QueryDailyMoves it uses epoch_ms, which works for TIMESTAMPTZ
+// columns without the ICU extension.
+func QueryCandleSeries(market, symbol, timeframe string, tsFrom, tsTo int64) ([]model.Candle, error) {
+	pattern := GetParquetGlob(market, "", "")
+
+	query := fmt.Sprintf(`
+		SELECT * FROM (
+			SELECT epoch_ms(timestamp) // 1000 AS ts, open, high, low, close, volume
+			FROM read_parquet('%s', hive_partitioning=true)
+			WHERE symbol = ? AND %s = ?
+		) WHERE ts >= ? AND ts <= ?
+		ORDER BY ts ASC
+	`, pattern, timeframeExpr(market))
+
+	rows, err := DB.Query(query, symbol, timeframe, tsFrom, tsTo)
+	if err != nil {
+		return nil, fmt.Errorf("query failed: %w", err)
+	}
+	defer rows.Close()
+
+	var candles []model.Candle
+	for rows.Next() {
+		c := model.Candle{Market: market, Symbol: symbol, Timeframe: timeframe}
+		var open, high, low, closePrice, volume sql.NullFloat64
+		if err := rows.Scan(&c.TS, &open, &high, &low, &closePrice, &volume); err != nil {
+			return nil, err
+		}
+		if !closePrice.Valid {
+			continue
+		}
+		c.Open, c.High, c.Low, c.Close, c.Volume = open.Float64, high.Float64, low.Float64, closePrice.Float64, volume.Float64
+		candles = append(candles, c)
+	}
+	return candles, rows.Err()
+}
+
+// DailyMove is the close-to-close change of one symbol over a session
+type DailyMove struct {
+	Symbol    string  `json:"symbol"`
+	Name      string  `json:"name"`
+	PrevClose float64 `json:"prev_close"`
+	Close     float64 `json:"close"`
+	ChangePct float64 `json:"change_pct"`
+	Volume    float64 `json:"volume"` // summed over the session's bars
+}
+
+// QueryDailyMoves compares each symbol's last close in [dayStart, dayEnd)
+// with its last close in the ten days before dayStart (UTC epoch sec), using
+// the bars of one timeframe ("1d" or "1m") so volumes are not counted twice.
+// Only the files of those days are read. Results are sorted by change,
+// largest gain first. epoch_ms binds for TIMESTAMPTZ columns even without
+// the ICU extension.
+func QueryDailyMoves(market, timeframe string, dayStart, dayEnd int64) ([]DailyMove, error) {
+	from := dayStart - 10*24*3600
+	files, err := ParquetFilesBetween(market, time.Unix(from, 0), time.Unix(dayEnd, 0))
+	if err != nil {
+		return nil, err
+	}
+	if len(files) == 0 {
+		return nil, nil
+	}
+
+	query := fmt.Sprintf(`
+		WITH bars AS (
+			SELECT symbol, epoch_ms(timestamp) // 1000 AS ts, close, volume
+			FROM read_parquet(%s, hive_partitioning=true)
+			WHERE close > 0 AND %s = ?
+		),
+		window_bars AS (
+			SELECT * FROM bars WHERE ts >= ? AND ts < ?
+		),
+		day AS (
+			SELECT symbol, arg_max(close, ts) AS close, SUM(volume) AS volume
+			FROM window_bars WHERE ts >= ? GROUP BY symbol
+		),
+		prev AS (
+			SELECT symbol, arg_max(close, ts) AS close
+			FROM window_bars WHERE ts < ? GROUP BY symbol
+		)
+		SELECT d.symbol, COALESCE(i.name, ''), p.close, d.close, (d.close / p.close - 1) * 100 AS change_pct, COALESCE(d.volume, 0)
+		FROM day d
+		JOIN prev p ON p.symbol = d.symbol
+		LEFT JOIN instruments i ON i.market = ? AND i.symbol = d.symbol
+		ORDER BY change_pct DESC
+	`, sqlList(files), timeframeExpr(market))
+
+	rows, err := DB.Query(query, timeframe, from, dayEnd, dayStart, dayStart, market)
+	if err != nil {
+		return nil, fmt.Errorf("query failed: %w", err)
+	}
+	defer rows.Close()
+
+	var moves []DailyMove
+	for rows.Next() {
+		var m DailyMove
+		if err := rows.Scan(&m.Symbol, &m.Name, &m.PrevClose, &m.Close, &m.ChangePct, &m.Volume); err != nil {
+			return nil, err
+		}
+		moves = append(moves, m)
+	}
+	return moves, rows.Err()
+}
+
+// LatestClose is the last close of a symbol
+type LatestClose struct {
+	Symbol string  `json:"symbol"`
+	Close  float64 `json:"close"`
+	TS     int64   `json:"ts"` // UTC epoch sec of the bar
+}
+
+// QueryLatestCloses returns the last close at or before ts (UTC epoch sec)
+// of each symbol that has bars, looking back at most 30 days
+func QueryLatestCloses(market string, symbols []string, ts int64) (map[string]LatestClose, error) {
+	closes := make(map[string]LatestClose)
+	if len(symbols) == 0 {
+		return closes, nil
+	}
+	pattern := GetParquetGlob(market, "", "")
+
+	placeholders := strings.TrimSuffix(strings.Repeat("?,", len(symbols)), ",")
+	query := fmt.Sprintf(`
+		WITH bars AS (
+			SELECT symbol, epoch_ms(timestamp) // 1000 AS ts, close
+			FROM read_parquet('%s', hive_partitioning=true)
+			WHERE close > 0 AND symbol IN (%s)
+		)
+		SELECT symbol, arg_max(close, ts), MAX(ts)
+		FROM bars WHERE ts > ? AND ts <= ?
+		GROUP BY symbol
+	`, pattern, placeholders)
+
+	args := make([]interface{}, 0, len(symbols)+2)
+	for _, s := range symbols {
+		args = append(args, s)
+	}
+	args = append(args, ts-30*24*3600, ts)
+
+	rows, err := DB.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query failed: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var c LatestClose
+		if err := rows.Scan(&c.Symbol, &c.Close, &c.TS); err != nil {
+			return nil, err
+		}
+		closes[c.Symbol] = c
+	}
+	return closes, rows.Err()
+}
@@ -0,0 +1,288 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	"dx-unified/internal/dart/models"
+	"dx-unified/internal/shared/timeutil"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+const maxBackfillDays = 3660
+
+// pblntf_ty codes of list.json (A periodic, B major events, ... J fair trade)
+var filingTypePattern = regexp.MustCompile(`^[A-J]$`)
+
+var corpCodePattern = regexp.MustCompile(`^\d{8}$`)
+
+// SetBackfillRunner sets the function that starts processing queued
+// backfill jobs; without it (no DART API key) backfills are refused
+func (h *Handler) SetBackfillRunner(run func()) {
+	h.runBackfills = run
+}
+
+// backfillView adds the completion percentage to a job
+type backfillView struct {
+	models.BackfillJob
+	Progress float64 `json:"progress"`
+}
+
+func viewBackfill(job models.BackfillJob) backfillView {
+	v := backfillView{BackfillJob: job}
+	if job.DaysTotal > 0 {
+		v.Progress = float64(int(float64(job.DaysDone)/float64(job.DaysTotal)*1000)) / 10
+	}
+	return v
+}
+
+// CreateBackfill queues a backfill of past filing lists:
+//
+//	{"date_from": "20240101", "date_to": "20240331",
+//	 "corp_codes": ["00126380"], "filing_types": ["A", "B"]}
+//
+// corp_codes and filing_types are optional; each combination is fetched
+// per day, so they multiply the number of requests.
+func (h *Handler) CreateBackfill(c *gin.Context) {
+	if h.runBackfills == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "DART API key is not configured"})
+		return
+	}
+
+	var req struct {
+		DateFrom    string   `json:"date_from" binding:"required"`
+		DateTo      string   `json:"date_to" binding:"required"`
+		CorpCodes   []string `json:"corp_codes"`
+		FilingTypes []string `json:"filing_types"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	from, err := timeutil.ParseDate(req.DateFrom)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	to, err := timeutil.ParseDate(req.DateTo)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if to.Before(from) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "date_to is before date_from"})
+		return
+	}
+	if to.After(time.Now()) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "date_to is in the future"})
+		return
+	}
+	days := int(to.Sub(from).Hours()/24) + 1
+	if days > maxBackfillDays {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("range is limited to %d days", maxBackfillDays)})
+		return
+	}
+	for _, code := range req.CorpCodes {
+		if !corpCodePattern.MatchString(code) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("invalid corp code %q", code)})
+			return
+		}
+	}
+	for i, typ := range req.FilingTypes {
+		req.FilingTypes[i] = strings.ToUpper(typ)
+		if !filingTypePattern.MatchString(req.FilingTypes[i]) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("invalid filing type %q, expected A-J", typ)})
+			return
+		}
+	}
+
+	job := models.BackfillJob{
+		DateFrom:    from.Format("20060102"),
+		DateTo:      to.Format("20060102"),
+		CorpCodes:   strings.Join(req.CorpCodes, ","),
+		FilingTypes: strings.Join(req.FilingTypes, ","),
+		State:       models.BackfillQueued,
+		NextDate:    from.Format("20060102"),
+		DaysTotal:   days,
+	}
+	if err := h.DB.Create(&job).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	go h.runBackfills()
+	c.JSON(http.StatusAccepted, viewBackfill(job))
+}
+
+// GetBackfills returns the latest backfill jobs, optionally by state
+func (h *Handler) GetBackfills(c *gin.Context) {
+	query := h.DB.Order("id DESC").Limit(50)
+	if state := c.Query("state"); state != "" {
+		query = query.Where("state = ?", state)
+	}
+
+	var jobs []models.BackfillJob
+	if err := query.Find(&jobs).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	views := make([]backfillView, len(jobs))
+	for i, job := range jobs {
+		views[i] = viewBackfill(job)
+	}
+	c.JSON(http.StatusOK, gin.H{"data": views, "total": len(views)})
+}
+
+// GetBackfill returns a backfill job with its progress
+func (h *Handler) GetBackfill(c *gin.Context) {
+	job, ok := h.findBackfill(c)
+	if !ok {
+		return
+	}
+	c.JSON(http.StatusOK, viewBackfill(job))
+}
+
+// CancelBackfill stops a job after the day it is fetching
+func (h *Handler) CancelBackfill(c *gin.Context) {
+	job, ok := h.findBackfill(c)
+	if !ok {
+		return
+	}
+	if job.State == models.BackfillDone || job.State == models.BackfillCancelled {
+		c.JSON(http.StatusConflict, gin.H{"error": "Backfill is already " + job.State})
+		return
+	}
+
+	if err := h.DB.Model(&job).Update("state", models.BackfillCancelled).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	job.State = models.BackfillCancelled
+	c.JSON(http.StatusOK, viewBackfill(job))
+}
+
+// ResumeBackfill queues a failed, cancelled or paused job again; it
+// continues from its checkpoint
+func (h *Handler) ResumeBackfill(c *gin.Context) {
+	if h.runBackfills == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "DART API key is not configured"})
+		return
+	}
+	job, ok := h.findBackfill(c)
+	if !ok {
+		return
+	}
+	if job.State == models.BackfillDone || job.State == models.BackfillRunning || job.State == models.BackfillQueued {
+		c.JSON(http.StatusConflict, gin.H{"error": "Backfill is " + job.State})
+		return
+	}
+
+	if err := h.DB.Model(&job).Update("state", models.BackfillQueued).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	job.State = models.BackfillQueued
+	go h.runBackfills()
+	c.JSON(http.StatusOK, viewBackfill(job))
+}
+
+func (h *Handler) findBackfill(c *gin.Context) (models.BackfillJob, bool) {
+	var job models.BackfillJob
+	if err := h.DB.First(&job, c.Param("id")).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Backfill not found"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return job, false
+	}
+	return job, true
+}
+
+// GetGaps compares the weekdays of a range (default the last 90 days)
+// with the stored filings. Weekdays without filings are "missing" when
+// their filing list was never fetched completely (backfill candidates) and
+// "empty" when it was fetched and had none, usually market holidays.
+func (h *Handler) GetGaps(c *gin.Context) {
+	to := time.Now()
+	from := to.AddDate(0, 0, -90)
+	var err error
+	if s := c.Query("date_from"); s != "" {
+		if from, err = timeutil.ParseDate(s); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+	if s := c.Query("date_to"); s != "" {
+		if to, err = timeutil.ParseDate(s); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+	if to.Sub(from).Hours()/24 > maxBackfillDays {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("range is limited to %d days", maxBackfillDays)})
+		return
+	}
+	fromStr, toStr := from.Format("20060102"), to.Format("20060102")
+
+	var counts []struct {
+		RceptDt string
+		Count   int
+	}
+	if err := h.DB.Model(&models.Filing{}).
+		Select("rcept_dt, COUNT(*) AS count").
+		Where("rcept_dt BETWEEN ? AND ?", fromStr, toStr).
+		Group("rcept_dt").
+		Scan(&counts).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	filingsByDay := make(map[string]int, len(counts))
+	for _, fc := range counts {
+		filingsByDay[fc.RceptDt] = fc.Count
+	}
+
+	var fetched []models.FilingDay
+	h.DB.Where("date BETWEEN ? AND ?", fromStr, toStr).Find(&fetched)
+	fetchedDays := make(map[string]bool, len(fetched))
+	for _, d := range fetched {
+		fetchedDays[d.Date] = true
+	}
+
+	missing, empty := []string{}, []string{}
+	businessDays, withFilings := 0, 0
+	for day := from; !day.After(to); day = day.AddDate(0, 0, 1) {
+		if day.Weekday() == time.Saturday || day.Weekday() == time.Sunday {
+			continue
+		}
+		businessDays++
+		date := day.Format("20060102")
+		switch {
+		case filingsByDay[date] > 0:
+			withFilings++
+		case fetchedDays[date]:
+			empty = append(empty, date)
+		default:
+			missing = append(missing, date)
+		}
+	}
+
+	resp := gin.H{
+		"date_from":         fromStr,
+		"date_to":           toStr,
+		"business_days":     businessDays,
+		"days_with_filings": withFilings,
+		"missing":           missing,
+		"empty":             empty,
+	}
+	if len(missing) > 0 {
+		resp["backfill"] = gin.H{"date_from": missing[0], "date_to": missing[len(missing)-1]}
+	}
+	c.JSON(http.StatusOK, resp)
+}
@@ -0,0 +1,66 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"dx-unified/internal/dart/models"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func TestGetGaps(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "dart.db")), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.AutoMigrate(&models.Filing{}, &models.FilingDay{}); err != nil {
+		t.Fatal(err)
+	}
+	// Filings on Monday and Tuesday, Wednesday fetched without filings
+	db.Create(&[]models.Filing{
+		{RceptNo: "20260302000001", RceptDt: "20260302"},
+		{RceptNo: "20260302000002", RceptDt: "20260302"},
+		{RceptNo: "20260303000001", RceptDt: "20260303"},
+	})
+	db.Create(&[]models.FilingDay{{Date: "20260302", Count: 2}, {Date: "20260304"}})
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/gaps", (&Handler{DB: db}).GetGaps)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/gaps?date_from=2026-03-02&date_to=20260313", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status %d: %s", rec.Code, rec.Body)
+	}
+
+	var got struct {
+		BusinessDays    int               `json:"business_days"`
+		DaysWithFilings int               `json:"days_with_filings"`
+		Missing         []string          `json:"missing"`
+		Empty           []string          `json:"empty"`
+		Backfill        map[string]string `json:"backfill"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+	if got.BusinessDays != 10 || got.DaysWithFilings != 2 {
+		t.Errorf("business days %d, with filings %d; want 10, 2", got.BusinessDays, got.DaysWithFilings)
+	}
+	if want := []string{"20260304"}; !reflect.DeepEqual(got.Empty, want) {
+		t.Errorf("empty = %v, want %v", got.Empty, want)
+	}
+	if want := []string{"20260305", "20260306", "20260309", "20260310", "20260311", "20260312", "20260313"}; !reflect.DeepEqual(got.Missing, want) {
+		t.Errorf("missing = %v, want %v", got.Missing, want)
+	}
+	if got.Backfill["date_from"] != "20260305" || got.Backfill["date_to"] != "20260313" {
+		t.Errorf("backfill = %v", got.Backfill)
+	}
+}
@@ -0,0 +1,109 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"dx-unified/internal/dart/models"
+	"dx-unified/internal/shared/timeutil"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// corpIdentity is the name, stock code and listing status a corp had
+// during a period. From is inclusive and Until exclusive (YYYYMMDD); an
+// empty From means before the recorded history, an empty Until means now.
+type corpIdentity struct {
+	From      string `json:"from"`
+	Until     string `json:"until"`
+	CorpName  string `json:"corp_name"`
+	StockCode string `json:"stock_code"`
+	Status    string `json:"status"`
+}
+
+// identities walks the changes (newest first) back from the current corp
+// and returns its identities, newest first
+func identities(corp models.Corp, changes []models.CorpHistory) []corpIdentity {
+	cur := corpIdentity{CorpName: corp.CorpName, StockCode: corp.StockCode, Status: corp.Status}
+	if cur.Status == "" { // not refreshed since statuses were added
+		cur.Status = models.CorpUnlisted
+		if cur.StockCode != "" {
+			cur.Status = models.CorpListed
+		}
+	}
+	var out []corpIdentity
+	for i := 0; i < len(changes); {
+		// Changes detected on the same day form one transition
+		day := changes[i].ChangedOn
+		cur.From = day
+		out = append(out, cur)
+		cur = corpIdentity{Until: day, CorpName: cur.CorpName, StockCode: cur.StockCode, Status: cur.Status}
+		for ; i < len(changes) && changes[i].ChangedOn == day; i++ {
+			switch changes[i].Field {
+			case models.CorpFieldName:
+				cur.CorpName = changes[i].OldValue
+			case models.CorpFieldStockCode:
+				cur.StockCode = changes[i].OldValue
+			case models.CorpFieldStatus:
+				cur.Status = changes[i].OldValue
+			}
+		}
+	}
+	// A corp first seen as listed did not exist before its listing
+	if cur.Status != "" {
+		out = append(out, cur)
+	}
+	return out
+}
+
+// GetCorpHistory returns a corp with its recorded changes and the
+// identities they imply, newest first. With date (YYYYMMDD or YYYY-MM-DD)
+// it also returns the identity in effect on that day, for mapping
+// historical filings and prices.
+func (h *Handler) GetCorpHistory(c *gin.Context) {
+	corpCode := c.Param("corp_code")
+
+	var corp models.Corp
+	if err := h.DB.Where("corp_code = ?", corpCode).First(&corp).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Corp not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	var changes []models.CorpHistory
+	err := h.DB.Where("corp_code = ?", corpCode).
+		Order("changed_on DESC, id DESC").
+		Find(&changes).Error
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	ids := identities(corp, changes)
+	resp := gin.H{
+		"corp":       corp,
+		"changes":    changes,
+		"identities": ids,
+	}
+	if s := c.Query("date"); s != "" {
+		t, err := timeutil.ParseDate(s)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		date := t.Format("20060102")
+		var asOf *corpIdentity
+		for i := range ids {
+			if (ids[i].From == "" || ids[i].From <= date) && (ids[i].Until == "" || date < ids[i].Until) {
+				asOf = &ids[i]
+				break
+			}
+		}
+		resp["as_of"] = asOf
+	}
+	c.JSON(http.StatusOK, resp)
+}
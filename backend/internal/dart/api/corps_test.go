@@ -0,0 +1,63 @@
+package api
+
+import (
+	"reflect"
+	"testing"
+
+	"dx-unified/internal/dart/models"
+)
+
+func TestIdentities(t *testing.T) {
+	change := func(on, field, old, new string) models.CorpHistory {
+		return models.CorpHistory{ChangedOn: on, Field: field, OldValue: old, NewValue: new}
+	}
+	tests := []struct {
+		name    string
+		corp    models.Corp
+		changes []models.CorpHistory
+		want    []corpIdentity
+	}{
+		{
+			name: "no history",
+			corp: models.Corp{CorpName: "삼성전자", StockCode: "005930", Status: models.CorpListed},
+			want: []corpIdentity{{CorpName: "삼성전자", StockCode: "005930", Status: models.CorpListed}},
+		},
+		{
+			name: "status predating the column",
+			corp: models.Corp{CorpName: "삼성전자", StockCode: "005930"},
+			want: []corpIdentity{{CorpName: "삼성전자", StockCode: "005930", Status: models.CorpListed}},
+		},
+		{
+			// Changes of one day form one transition
+			name: "rename, then delisting",
+			corp: models.Corp{CorpName: "신한금융", Status: models.CorpDelisted},
+			changes: []models.CorpHistory{
+				change("20260301", models.CorpFieldStockCode, "055550", ""),
+				change("20260301", models.CorpFieldStatus, models.CorpListed, models.CorpDelisted),
+				change("20250101", models.CorpFieldName, "신한지주", "신한금융"),
+			},
+			want: []corpIdentity{
+				{From: "20260301", CorpName: "신한금융", Status: models.CorpDelisted},
+				{From: "20250101", Until: "20260301", CorpName: "신한금융", StockCode: "055550", Status: models.CorpListed},
+				{Until: "20250101", CorpName: "신한지주", StockCode: "055550", Status: models.CorpListed},
+			},
+		},
+		{
+			name: "first seen as listed",
+			corp: models.Corp{CorpName: "새상장", StockCode: "123450", Status: models.CorpListed},
+			changes: []models.CorpHistory{
+				change("20260415", models.CorpFieldStatus, "", models.CorpListed),
+			},
+			want: []corpIdentity{
+				{From: "20260415", CorpName: "새상장", StockCode: "123450", Status: models.CorpListed},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := identities(tt.corp, tt.changes); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("identities =\n%+v\nwant\n%+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,88 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"dx-unified/internal/dart/models"
+	"dx-unified/internal/dart/storage"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// SetDocumentStore sets the blob store downloaded documents are read from;
+// without it raw downloads and the migration are refused
+func (h *Handler) SetDocumentStore(blobs *storage.Blobs) {
+	h.blobs = blobs
+}
+
+// GetDocumentRaw streams the zip of a downloaded document. The SHA-256 is
+// the ETag, so clients can revalidate with If-None-Match.
+func (h *Handler) GetDocumentRaw(c *gin.Context) {
+	if h.blobs == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Document store is not configured"})
+		return
+	}
+
+	var doc models.FilingDocument
+	if err := h.DB.First(&doc, c.Param("id")).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Document not found"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+	if doc.State != models.DocStateDone {
+		c.JSON(http.StatusConflict, gin.H{"error": "Document is " + doc.State + ", not downloaded yet"})
+		return
+	}
+
+	etag := `"` + doc.SHA256 + `"`
+	if doc.SHA256 != "" && c.GetHeader("If-None-Match") == etag {
+		c.Status(http.StatusNotModified)
+		return
+	}
+
+	r, size, err := h.blobs.Open(doc.StorageURI, doc.SHA256)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Document file is missing from the store"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+	defer r.Close()
+
+	headers := map[string]string{
+		"Content-Disposition": `attachment; filename="` + doc.RceptNo + `.zip"`,
+	}
+	if doc.SHA256 != "" {
+		headers["ETag"] = etag
+	}
+	c.DataFromReader(http.StatusOK, size, "application/zip", r, headers)
+}
+
+// MigrateDocuments moves documents downloaded before blob storage into the
+// store. ?limit caps the documents handled (default 1000, 0 for all).
+func (h *Handler) MigrateDocuments(c *gin.Context) {
+	if h.blobs == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Document store is not configured"})
+		return
+	}
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "1000"))
+	if err != nil || limit < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "limit must be a non-negative number"})
+		return
+	}
+
+	res, err := storage.MigrateDocuments(h.DB, h.blobs, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, res)
+}
@@ -3,8 +3,12 @@ package api
 import (
 	"net/http"
 	"strconv"
+	"strings"
 
 	"dx-unified/internal/dart/models"
+	"dx-unified/internal/dart/search"
+	"dx-unified/internal/dart/storage"
+	"dx-unified/internal/dart/taxonomy"
 
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
@@ -13,6 +17,11 @@ import (
 // Handler holds dependencies for DART API handlers
 type Handler struct {
 	DB *gorm.DB
+
+	runBackfills     func()
+	refreshOwnership func(corpCode string) (int, error)
+	blobs            *storage.Blobs
+	search           *search.Index
 }
 
 // NewHandler creates a new DART API handler
@@ -25,15 +34,46 @@ func (h *Handler) RegisterRoutes(rg *gin.RouterGroup) {
 	dart := rg.Group("/dart")
 	{
 		dart.GET("/corps", h.GetCorps)
+		dart.GET("/corps/:corp_code/history", h.GetCorpHistory)
 		dart.GET("/filings", h.GetFilings)
 		dart.GET("/filings/:rcept_no", h.GetFilingDetail)
 
+		// Full-text search
+		dart.GET("/search", h.Search)
+		dart.GET("/search/status", h.GetSearchStatus)
+		dart.POST("/search/reindex", h.ReindexSearch)
+
+		// Filing taxonomy
+		dart.GET("/taxonomy", h.GetTaxonomy)
+		dart.POST("/taxonomy/reclassify", h.Reclassify)
+
+		// Holdings of 5% holders, executives and major shareholders
+		dart.GET("/ownership/trades", h.GetInsiderTrades)
+		dart.GET("/ownership/:corp_code", h.GetOwnership)
+		dart.POST("/ownership/:corp_code/refresh", h.RefreshOwnership)
+
+		// Document downloads
+		dart.GET("/documents", h.GetDocuments)
+		dart.POST("/documents/retry", h.RetryDocuments)
+		dart.POST("/documents/:id/retry", h.RetryDocument)
+		dart.GET("/documents/:id/raw", h.GetDocumentRaw)
+
+		// Historical backfill
+		dart.POST("/backfill", h.CreateBackfill)
+		dart.GET("/backfill", h.GetBackfills)
+		dart.GET("/backfill/:id", h.GetBackfill)
+		dart.POST("/backfill/:id/cancel", h.CancelBackfill)
+		dart.POST("/backfill/:id/resume", h.ResumeBackfill)
+		dart.GET("/gaps", h.GetGaps)
+
 		// Migration
 		dart.POST("/migration/filings", h.IngestFilings)
+		dart.POST("/migration/documents", h.MigrateDocuments)
 	}
 }
 
-// GetCorps returns a paginated list of corporations
+// GetCorps returns a paginated list of corporations, optionally filtered
+// by status (listed, delisted or unlisted)
 func (h *Handler) GetCorps(c *gin.Context) {
 	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
 	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
@@ -42,8 +82,12 @@ func (h *Handler) GetCorps(c *gin.Context) {
 	var corps []models.Corp
 	var total int64
 
-	h.DB.Model(&models.Corp{}).Count(&total)
-	result := h.DB.Limit(limit).Offset(offset).Order("corp_name ASC").Find(&corps)
+	query := h.DB.Model(&models.Corp{})
+	if status := c.Query("status"); status != "" {
+		query = query.Where("status = ?", status)
+	}
+	query.Count(&total)
+	result := query.Limit(limit).Offset(offset).Order("corp_name ASC").Find(&corps)
 
 	if result.Error != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": result.Error.Error()})
@@ -58,7 +102,10 @@ func (h *Handler) GetCorps(c *gin.Context) {
 	})
 }
 
-// GetFilings returns a paginated list of filings
+// GetFilings returns a paginated list of filings. Besides corp, stock code
+// and date it filters by type (pblntf_ty A-J or a detail code like B001),
+// category (comma separated taxonomy keys), corp_cls (Y/K/N/E or the market
+// name), correction=true|false and q, a substring of the report or corp name.
 func (h *Handler) GetFilings(c *gin.Context) {
 	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
 	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
@@ -76,21 +123,59 @@ func (h *Handler) GetFilings(c *gin.Context) {
 	query := h.DB.Model(&models.Filing{})
 
 	if corpCode != "" {
-		query = query.Where("corp_code = ?", corpCode)
+		query = query.Where("filings.corp_code = ?", corpCode)
 	}
 	if stockCode != "" {
 		query = query.Joins("JOIN corps ON filings.corp_code = corps.corp_code").
 			Where("corps.stock_code = ?", stockCode)
 	}
 	if dateFrom != "" {
-		query = query.Where("rcept_dt >= ?", dateFrom)
+		query = query.Where("filings.rcept_dt >= ?", dateFrom)
 	}
 	if dateTo != "" {
-		query = query.Where("rcept_dt <= ?", dateTo)
+		query = query.Where("filings.rcept_dt <= ?", dateTo)
+	}
+	if typ := strings.ToUpper(c.Query("type")); typ != "" {
+		switch {
+		case filingTypePattern.MatchString(typ):
+			query = query.Where("filings.pblntf_ty = ?", typ)
+		case detailTypePattern.MatchString(typ):
+			query = query.Where("filings.pblntf_detail_ty = ?", typ)
+		default:
+			c.JSON(http.StatusBadRequest, gin.H{"error": "type must be A-J or a detail type like B001"})
+			return
+		}
+	}
+	if category := c.Query("category"); category != "" {
+		query = query.Where("filings.category IN ?", strings.Split(category, ","))
+	}
+	if corpCls := strings.ToUpper(c.Query("corp_cls")); corpCls != "" {
+		cls, ok := corpClass(corpCls)
+		if !ok {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "corp_cls must be Y, K, N, E or KOSPI, KOSDAQ, KONEX, ETC"})
+			return
+		}
+		query = query.Where("filings.corp_cls = ?", cls)
+	}
+	if correction := c.Query("correction"); correction != "" {
+		isCorrection, err := strconv.ParseBool(correction)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "correction must be true or false"})
+			return
+		}
+		query = query.Where("filings.is_correction = ?", isCorrection)
+	}
+	if latest, _ := strconv.ParseBool(c.Query("latest")); latest {
+		// Only the latest version of corrected filings
+		query = query.Where("(filings.superseded_by = '' OR filings.superseded_by IS NULL)")
+	}
+	if q := strings.TrimSpace(c.Query("q")); q != "" {
+		like := "%" + q + "%"
+		query = query.Where("(filings.report_nm LIKE ? OR filings.corp_name LIKE ?)", like, like)
 	}
 
 	query.Count(&total)
-	result := query.Limit(limit).Offset(offset).Order("rcept_dt DESC, rcept_no DESC").Find(&filings)
+	result := query.Limit(limit).Offset(offset).Order("filings.rcept_dt DESC, filings.rcept_no DESC").Find(&filings)
 
 	if result.Error != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": result.Error.Error()})
@@ -125,13 +210,145 @@ func (h *Handler) GetFilingDetail(c *gin.Context) {
 	var events []models.ExtractedEvent
 	h.DB.Where("rcept_no = ?", rceptNo).Find(&events)
 
-	c.JSON(http.StatusOK, gin.H{
+	resp := gin.H{
 		"filing":    filing,
 		"documents": documents,
 		"events":    events,
+	}
+
+	// Versions of the correction chain, original first, and the events
+	// that are current across all of them
+	chain, err := taxonomy.Chain(h.DB, filing)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if len(chain) > 1 {
+		versions := make([]chainVersion, len(chain))
+		rceptNos := make([]string, len(chain))
+		for i, f := range chain {
+			versions[i] = chainVersion{
+				RceptNo:      f.RceptNo,
+				ReportNm:     f.ReportNm,
+				RceptDt:      f.RceptDt,
+				IsCorrection: f.IsCorrection,
+				Current:      f.RceptNo == filing.RceptNo,
+			}
+			rceptNos[i] = f.RceptNo
+		}
+		var current []models.ExtractedEvent
+		h.DB.Where("rcept_no IN ? AND (superseded = ? OR superseded IS NULL)", rceptNos, false).
+			Order("rcept_no, id").
+			Find(&current)
+
+		resp["chain"] = versions
+		resp["latest_rcept_no"] = chain[len(chain)-1].RceptNo
+		resp["current_events"] = current
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// chainVersion is one version of a correction chain in GetFilingDetail
+type chainVersion struct {
+	RceptNo      string `json:"rcept_no"`
+	ReportNm     string `json:"report_nm"`
+	RceptDt      string `json:"rcept_dt"`
+	IsCorrection bool   `json:"is_correction"`
+	Current      bool   `json:"current"` // the requested filing
+}
+
+// GetDocuments returns a paginated list of filing documents, optionally by
+// download state (pending, downloading, done, failed, dead) or rcept_no,
+// with the number of documents in each state
+func (h *Handler) GetDocuments(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	offset := (page - 1) * limit
+
+	query := h.DB.Model(&models.FilingDocument{})
+	if state := c.Query("state"); state != "" {
+		query = query.Where("state = ?", state)
+	}
+	if rceptNo := c.Query("rcept_no"); rceptNo != "" {
+		query = query.Where("rcept_no = ?", rceptNo)
+	}
+
+	var documents []models.FilingDocument
+	var total int64
+	query.Count(&total)
+	result := query.Limit(limit).Offset(offset).Order("updated_at DESC, id DESC").Find(&documents)
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": result.Error.Error()})
+		return
+	}
+
+	var counts []struct {
+		State string
+		Count int64
+	}
+	h.DB.Model(&models.FilingDocument{}).Select("state, COUNT(*) AS count").Group("state").Scan(&counts)
+	states := make(map[string]int64, len(counts))
+	for _, sc := range counts {
+		states[sc.State] = sc.Count
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":   documents,
+		"total":  total,
+		"page":   page,
+		"limit":  limit,
+		"states": states,
 	})
 }
 
+// retryUpdates queues a document for download on the next run
+var retryUpdates = map[string]interface{}{
+	"state":           models.DocStatePending,
+	"retry_count":     0,
+	"next_attempt_at": nil,
+}
+
+// RetryDocument queues a failed or dead document for download on the next run
+func (h *Handler) RetryDocument(c *gin.Context) {
+	var doc models.FilingDocument
+	if err := h.DB.First(&doc, c.Param("id")).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Document not found"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+	if doc.State != models.DocStateFailed && doc.State != models.DocStateDead {
+		c.JSON(http.StatusConflict, gin.H{"error": "Document is " + doc.State + ", only failed or dead documents can be retried"})
+		return
+	}
+
+	if err := h.DB.Model(&doc).Updates(retryUpdates).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	h.DB.First(&doc, doc.ID)
+	c.JSON(http.StatusOK, doc)
+}
+
+// RetryDocuments queues all documents in a state (?state=dead by default,
+// or failed) for download on the next run
+func (h *Handler) RetryDocuments(c *gin.Context) {
+	state := c.DefaultQuery("state", models.DocStateDead)
+	if state != models.DocStateFailed && state != models.DocStateDead {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "state must be failed or dead"})
+		return
+	}
+
+	result := h.DB.Model(&models.FilingDocument{}).Where("state = ?", state).Updates(retryUpdates)
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": result.Error.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Documents queued for download", "count": result.RowsAffected})
+}
+
 // IngestFilings handles batch ingestion of DART filings
 func (h *Handler) IngestFilings(c *gin.Context) {
 	var filings []models.Filing
@@ -145,19 +362,16 @@ func (h *Handler) IngestFilings(c *gin.Context) {
 		return
 	}
 
-	// Batch Upsert using GORM Clause
-	// Note: We need to import "gorm.io/gorm/clause"
-	// However, standard GORM Create with multiple items works for Insert.
-	// For Upsert, we need clause.OnConflict.
-	// Since I can't easily add import via this tool without reading top, I'll rely on generic Save if applicable?
-	// Or try to use h.DB.Save() in loop? Loop is safer without import knowledge.
-	// Or just Loop and FirstOrCreate/Updates. Use Transaction.
+	// Classify like the scheduler does, so ingested filings can be filtered
+	for i := range filings {
+		taxonomy.Apply(&filings[i])
+	}
 
 	tx := h.DB.Begin()
 	count := 0
 	for _, f := range filings {
-		// Use Save (Upsert based on PK: rcept_no)
-		if err := tx.Save(&f).Error; err != nil {
+		// Use Save (Upsert based on PK: rcept_no), keeping correction links
+		if err := tx.Omit("corrects_rcept_no", "original_rcept_no", "superseded_by").Save(&f).Error; err != nil {
 			tx.Rollback()
 			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 			return
@@ -166,6 +380,15 @@ func (h *Handler) IngestFilings(c *gin.Context) {
 	}
 	tx.Commit()
 
+	corpCodes := make([]string, 0, len(filings))
+	for _, f := range filings {
+		corpCodes = append(corpCodes, f.CorpCode)
+	}
+	if _, err := taxonomy.LinkCorrections(h.DB, corpCodes); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
 	c.JSON(http.StatusOK, gin.H{
 		"message": "Filings ingested successfully",
 		"count":   count,
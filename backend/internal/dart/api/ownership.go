@@ -0,0 +1,247 @@
+package api
+
+import (
+	"net/http"
+	"sort"
+	"strconv"
+
+	"dx-unified/internal/dart/models"
+	"dx-unified/internal/shared/timeutil"
+	"dx-unified/pkg/dart"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// SetOwnershipRefresher sets the function fetching the holdings of a corp
+// from OpenDART; without it (no DART API key) refreshes are refused
+func (h *Handler) SetOwnershipRefresher(refresh func(corpCode string) (int, error)) {
+	h.refreshOwnership = refresh
+}
+
+// holdingPoint is one report in a holder's time series
+type holdingPoint struct {
+	Date         string  `json:"date"` // YYYY-MM-DD
+	RceptNo      string  `json:"rcept_no"`
+	Shares       int64   `json:"shares"`
+	SharesChange int64   `json:"shares_change"`
+	Ratio        float64 `json:"ratio"`
+}
+
+// holderSeries is the holdings of one holder over time, oldest first
+type holderSeries struct {
+	Kind     string         `json:"kind"`
+	Holder   string         `json:"holder"`
+	Position string         `json:"position,omitempty"`
+	Relation string         `json:"relation,omitempty"`
+	Shares   int64          `json:"shares"` // latest
+	Ratio    float64        `json:"ratio"`
+	LastDate string         `json:"last_date"`
+	Points   []holdingPoint `json:"points"`
+}
+
+// insiderTrade is a holding change with a direction; date and symbol match
+// the candle API (market KR) so prices can be looked up for the trade day
+type insiderTrade struct {
+	Date         string  `json:"date"` // YYYY-MM-DD
+	RceptNo      string  `json:"rcept_no"`
+	CorpCode     string  `json:"corp_code"`
+	CorpName     string  `json:"corp_name"`
+	Symbol       string  `json:"symbol"`
+	Kind         string  `json:"kind"`
+	Holder       string  `json:"holder"`
+	Position     string  `json:"position,omitempty"`
+	Relation     string  `json:"relation,omitempty"`
+	Side         string  `json:"side"` // buy or sell
+	SharesChange int64   `json:"shares_change"`
+	Shares       int64   `json:"shares"`
+	Ratio        float64 `json:"ratio"`
+	RatioChange  float64 `json:"ratio_change"`
+}
+
+func toTrade(hc models.HoldingChange, symbol string) insiderTrade {
+	side := "buy"
+	if hc.SharesChange < 0 {
+		side = "sell"
+	}
+	return insiderTrade{
+		Date:         timeutil.DashDate(hc.RceptDt),
+		RceptNo:      hc.RceptNo,
+		CorpCode:     hc.CorpCode,
+		CorpName:     hc.CorpName,
+		Symbol:       symbol,
+		Kind:         hc.Kind,
+		Holder:       hc.Holder,
+		Position:     hc.Position,
+		Relation:     hc.Relation,
+		Side:         side,
+		SharesChange: hc.SharesChange,
+		Shares:       hc.Shares,
+		Ratio:        hc.Ratio,
+		RatioChange:  hc.RatioChange,
+	}
+}
+
+// holdingFilters applies the kind and date range query parameters
+func holdingFilters(c *gin.Context, query *gorm.DB) (*gorm.DB, bool) {
+	if kind := c.Query("kind"); kind != "" {
+		if kind != models.HoldingMajor && kind != models.HoldingInsider {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "kind must be major or insider"})
+			return nil, false
+		}
+		query = query.Where("holding_changes.kind = ?", kind)
+	}
+	for param, cond := range map[string]string{
+		"date_from": "holding_changes.rcept_dt >= ?",
+		"date_to":   "holding_changes.rcept_dt <= ?",
+	} {
+		if s := c.Query(param); s != "" {
+			t, err := timeutil.ParseDate(s)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return nil, false
+			}
+			query = query.Where(cond, t.Format("20060102"))
+		}
+	}
+	return query, true
+}
+
+// GetOwnership returns the holdings of a corp: per holder the latest
+// position and its time series, plus the recent buys and sells
+func (h *Handler) GetOwnership(c *gin.Context) {
+	corpCode := c.Param("corp_code")
+	if !corpCodePattern.MatchString(corpCode) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "corp_code must be 8 digits"})
+		return
+	}
+
+	query, ok := holdingFilters(c, h.DB.Where("corp_code = ?", corpCode))
+	if !ok {
+		return
+	}
+	var changes []models.HoldingChange
+	if err := query.Order("rcept_dt, rcept_no").Find(&changes).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	var corp models.Corp
+	h.DB.Where("corp_code = ?", corpCode).Limit(1).Find(&corp)
+
+	var sync models.OwnershipSync
+	h.DB.Where("corp_code = ?", corpCode).Limit(1).Find(&sync)
+
+	byHolder := make(map[string]*holderSeries)
+	holders := []*holderSeries{}
+	trades := []insiderTrade{}
+	for _, hc := range changes {
+		key := hc.Kind + "|" + hc.Holder
+		s := byHolder[key]
+		if s == nil {
+			s = &holderSeries{Kind: hc.Kind, Holder: hc.Holder}
+			byHolder[key] = s
+			holders = append(holders, s)
+		}
+		s.Position, s.Relation = hc.Position, hc.Relation
+		s.Shares, s.Ratio, s.LastDate = hc.Shares, hc.Ratio, timeutil.DashDate(hc.RceptDt)
+		s.Points = append(s.Points, holdingPoint{
+			Date:         timeutil.DashDate(hc.RceptDt),
+			RceptNo:      hc.RceptNo,
+			Shares:       hc.Shares,
+			SharesChange: hc.SharesChange,
+			Ratio:        hc.Ratio,
+		})
+		if hc.SharesChange != 0 {
+			trades = append(trades, toTrade(hc, corp.StockCode))
+		}
+	}
+	sort.SliceStable(holders, func(i, j int) bool { return holders[i].Shares > holders[j].Shares })
+
+	// Newest trades first
+	for i, j := 0, len(trades)-1; i < j; i, j = i+1, j-1 {
+		trades[i], trades[j] = trades[j], trades[i]
+	}
+	if len(trades) > 50 {
+		trades = trades[:50]
+	}
+
+	resp := gin.H{
+		"corp_code":  corpCode,
+		"corp_name":  corp.CorpName,
+		"stock_code": corp.StockCode,
+		"holders":    holders,
+		"trades":     trades,
+	}
+	if sync.CorpCode != "" {
+		resp["fetched_at"] = sync.FetchedAt
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// GetInsiderTrades returns the latest holding changes of all corps as a
+// feed of buys and sells (side=buy|sell, kind, date_from, date_to, limit)
+func (h *Handler) GetInsiderTrades(c *gin.Context) {
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	if limit <= 0 || limit > 500 {
+		limit = 50
+	}
+
+	query := h.DB.Model(&models.HoldingChange{}).
+		Select("holding_changes.*, corps.stock_code AS stock_code").
+		Joins("LEFT JOIN corps ON corps.corp_code = holding_changes.corp_code")
+	switch c.Query("side") {
+	case "":
+		query = query.Where("holding_changes.shares_change <> 0")
+	case "buy":
+		query = query.Where("holding_changes.shares_change > 0")
+	case "sell":
+		query = query.Where("holding_changes.shares_change < 0")
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "side must be buy or sell"})
+		return
+	}
+	query, ok := holdingFilters(c, query)
+	if !ok {
+		return
+	}
+
+	var rows []struct {
+		models.HoldingChange
+		StockCode string
+	}
+	if err := query.Order("holding_changes.rcept_dt DESC, holding_changes.rcept_no DESC").Limit(limit).Scan(&rows).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	trades := make([]insiderTrade, len(rows))
+	for i, r := range rows {
+		trades[i] = toTrade(r.HoldingChange, r.StockCode)
+	}
+	c.JSON(http.StatusOK, gin.H{"data": trades, "total": len(trades)})
+}
+
+// RefreshOwnership fetches the holdings of a corp from OpenDART now
+func (h *Handler) RefreshOwnership(c *gin.Context) {
+	if h.refreshOwnership == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "DART API key is not configured"})
+		return
+	}
+	corpCode := c.Param("corp_code")
+	if !corpCodePattern.MatchString(corpCode) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "corp_code must be 8 digits"})
+		return
+	}
+
+	n, err := h.refreshOwnership(corpCode)
+	if dart.IsBudgetError(err) {
+		c.JSON(http.StatusTooManyRequests, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusBadGateway, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"corp_code": corpCode, "changes": n})
+}
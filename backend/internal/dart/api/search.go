@@ -0,0 +1,117 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"dx-unified/internal/dart/search"
+	"dx-unified/internal/shared/timeutil"
+
+	"github.com/gin-gonic/gin"
+)
+
+const maxSearchLimit = 100
+
+// SetSearchIndex sets the full-text index; without it (SQLite built
+// without FTS5) search is refused
+func (h *Handler) SetSearchIndex(idx *search.Index) {
+	h.search = idx
+}
+
+// Search runs a full-text search over report names, corp names (including
+// former names) and the text of downloaded documents. All terms of q must
+// match. Filters: corp_code, type (comma separated pblntf_ty A-J or detail
+// codes like B001), date_from, date_to; sort=date (default) or relevance.
+// Hits carry highlighted snippets and link to the filing and its events.
+func (h *Handler) Search(c *gin.Context) {
+	if h.search == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": search.ErrUnavailable.Error()})
+		return
+	}
+	q := strings.TrimSpace(c.Query("q"))
+	if q == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "q is required"})
+		return
+	}
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > maxSearchLimit {
+		limit = 20
+	}
+
+	query := search.Query{
+		Text:     q,
+		CorpCode: c.Query("corp_code"),
+		Sort:     c.DefaultQuery("sort", "date"),
+		Limit:    limit,
+		Offset:   (page - 1) * limit,
+	}
+	if query.Sort != "date" && query.Sort != "relevance" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "sort must be date or relevance"})
+		return
+	}
+	if s := c.Query("type"); s != "" {
+		for _, t := range strings.Split(strings.ToUpper(s), ",") {
+			t = strings.TrimSpace(t)
+			if !filingTypePattern.MatchString(t) && !detailTypePattern.MatchString(t) {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "invalid type " + t + ", expected A-J or a detail code like B001"})
+				return
+			}
+			query.Types = append(query.Types, t)
+		}
+	}
+	for param, dst := range map[string]*string{"date_from": &query.DateFrom, "date_to": &query.DateTo} {
+		if s := c.Query(param); s != "" {
+			d, err := timeutil.ParseDate(s)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": param + ": " + err.Error()})
+				return
+			}
+			*dst = d.Format("20060102")
+		}
+	}
+
+	hits, total, err := h.search.Search(query)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"query": q,
+		"data":  hits,
+		"total": total,
+		"page":  page,
+		"limit": limit,
+	})
+}
+
+// GetSearchStatus returns how many filings and documents are indexed
+func (h *Handler) GetSearchStatus(c *gin.Context) {
+	if h.search == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": search.ErrUnavailable.Error()})
+		return
+	}
+	filings, documents, err := h.search.Stats()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"filings": filings, "documents": documents})
+}
+
+// ReindexSearch drops the index; the DART-SearchIndex job rebuilds it
+func (h *Handler) ReindexSearch(c *gin.Context) {
+	if h.search == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": search.ErrUnavailable.Error()})
+		return
+	}
+	if err := h.search.Reset(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusAccepted, gin.H{"message": "Search index cleared, rebuilding on the next runs"})
+}
@@ -0,0 +1,102 @@
+package api
+
+import (
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+
+	"dx-unified/internal/dart/models"
+	"dx-unified/internal/dart/taxonomy"
+
+	"github.com/gin-gonic/gin"
+)
+
+// pblntf_detail_ty codes, e.g. B001
+var detailTypePattern = regexp.MustCompile(`^[A-J]\d{3}$`)
+
+// corpClass accepts a corp_cls code or the market it stands for
+func corpClass(s string) (string, bool) {
+	if _, ok := taxonomy.CorpClasses[s]; ok {
+		return s, true
+	}
+	for code, market := range taxonomy.CorpClasses {
+		if market == s {
+			return code, true
+		}
+	}
+	return "", false
+}
+
+type codeLabel struct {
+	Code  string `json:"code"`
+	Label string `json:"label"`
+	Count int64  `json:"count"`
+}
+
+// GetTaxonomy returns the filing types, corp classes and categories usable
+// as /dart/filings filters, with the number of stored filings of each
+func (h *Handler) GetTaxonomy(c *gin.Context) {
+	counts := func(column string) map[string]int64 {
+		var rows []struct {
+			Value string
+			Count int64
+		}
+		h.DB.Model(&models.Filing{}).
+			Select(column + " AS value, COUNT(*) AS count").
+			Group(column).
+			Scan(&rows)
+		m := make(map[string]int64, len(rows))
+		for _, r := range rows {
+			m[r.Value] = r.Count
+		}
+		return m
+	}
+
+	typeCounts := counts("pblntf_ty")
+	types := make([]codeLabel, 0, len(taxonomy.FilingTypes))
+	for code, label := range taxonomy.FilingTypes {
+		types = append(types, codeLabel{Code: code, Label: label, Count: typeCounts[code]})
+	}
+	sort.Slice(types, func(i, j int) bool { return types[i].Code < types[j].Code })
+
+	clsCounts := counts("corp_cls")
+	classes := make([]codeLabel, 0, len(taxonomy.CorpClasses))
+	for code, market := range taxonomy.CorpClasses {
+		classes = append(classes, codeLabel{Code: code, Label: market, Count: clsCounts[code]})
+	}
+	sort.Slice(classes, func(i, j int) bool { return classes[i].Code < classes[j].Code })
+
+	type categoryView struct {
+		taxonomy.Category
+		Count int64 `json:"count"`
+	}
+	categoryCounts := counts("category")
+	categories := make([]categoryView, 0, len(taxonomy.Categories)+1)
+	for _, cat := range taxonomy.Categories {
+		categories = append(categories, categoryView{Category: cat, Count: categoryCounts[cat.Key]})
+	}
+	categories = append(categories, categoryView{
+		Category: taxonomy.Category{Key: "other", Label: "기타"},
+		Count:    categoryCounts["other"],
+	})
+
+	c.JSON(http.StatusOK, gin.H{
+		"types":        types,
+		"corp_classes": classes,
+		"categories":   categories,
+		"unclassified": categoryCounts[""],
+	})
+}
+
+// Reclassify applies the current taxonomy to stored filings; only the
+// unclassified ones unless all=true
+func (h *Handler) Reclassify(c *gin.Context) {
+	all, _ := strconv.ParseBool(c.Query("all"))
+	updated, err := taxonomy.Reclassify(h.DB, all)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error(), "updated": updated})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"updated": updated})
+}
@@ -40,9 +40,14 @@ func InitDB(dbPath string) error {
 	// Auto Migrate
 	err = DB.AutoMigrate(
 		&models.Corp{},
+		&models.CorpHistory{},
 		&models.Filing{},
 		&models.FilingDocument{},
 		&models.ExtractedEvent{},
+		&models.BackfillJob{},
+		&models.FilingDay{},
+		&models.HoldingChange{},
+		&models.OwnershipSync{},
 	)
 	if err != nil {
 		return err
@@ -0,0 +1,93 @@
+package extract
+
+import (
+	"errors"
+	"strings"
+)
+
+// Dividend is the payload of a cash_dividend event, from a
+// 현금ㆍ현물배당결정 filing. Amounts are in won, dates YYYY-MM-DD.
+type Dividend struct {
+	Kind         string  `json:"kind,omitempty"` // 결산배당, 분기배당, 중간배당
+	DPS          float64 `json:"dps"`            // per common share
+	DPSPreferred float64 `json:"dps_preferred,omitempty"`
+	YieldPct     float64 `json:"yield_pct,omitempty"` // 시가배당율 as reported, common shares
+	Total        float64 `json:"total,omitempty"`
+	RecordDate   string  `json:"record_date"`
+	PaymentDate  string  `json:"payment_date,omitempty"`
+	BoardDate    string  `json:"board_date,omitempty"`
+}
+
+var errNoDividend = errors.New("no dividend table found")
+
+// isShareClass reports whether a cell names the share class of the row
+// label above, e.g. "보통주식" below "1주당 배당금(원)"
+func isShareClass(label string) bool {
+	return strings.HasPrefix(label, "보통주") || strings.HasPrefix(label, "종류주") || strings.HasPrefix(label, "우선주")
+}
+
+// ParseDividend reads the 현금ㆍ현물배당결정 table
+func ParseDividend(rows [][]string) (Dividend, []Evidence, error) {
+	var d Dividend
+	var ev []Evidence
+
+	label := ""
+	for _, row := range rows {
+		cells := compact(row)
+		first := normLabel(cells[0])
+		class := ""
+		switch {
+		case isShareClass(first):
+			class = first // continues the label of the previous row
+		case len(cells) >= 3 && isShareClass(normLabel(cells[1])):
+			label, class = first, normLabel(cells[1])
+		default:
+			label = first
+		}
+		if len(cells) < 2 {
+			continue
+		}
+		value := cells[len(cells)-1]
+		common := class == "" || strings.HasPrefix(class, "보통주")
+
+		switch {
+		case strings.Contains(label, "배당구분"):
+			d.Kind = value
+			ev = append(ev, evidence("kind", cells))
+		case strings.Contains(label, "1주당배당금"):
+			if n, ok := parseNumber(value); ok {
+				if common {
+					d.DPS = n
+					ev = append(ev, evidence("dps", cells))
+				} else if d.DPSPreferred == 0 {
+					d.DPSPreferred = n
+					ev = append(ev, evidence("dps_preferred", cells))
+				}
+			}
+		case strings.Contains(label, "시가배당율") || strings.Contains(label, "시가배당률"):
+			if n, ok := parseNumber(value); ok && common {
+				d.YieldPct = n
+				ev = append(ev, evidence("yield_pct", cells))
+			}
+		case strings.Contains(label, "배당금총액"):
+			if n, ok := parseNumber(value); ok {
+				d.Total = n
+				ev = append(ev, evidence("total", cells))
+			}
+		case strings.Contains(label, "배당기준일"):
+			d.RecordDate = parseDate(value)
+			ev = append(ev, evidence("record_date", cells))
+		case strings.Contains(label, "배당금지급") || strings.Contains(label, "지급예정일"):
+			d.PaymentDate = parseDate(value)
+			ev = append(ev, evidence("payment_date", cells))
+		case strings.Contains(label, "이사회결의일"):
+			d.BoardDate = parseDate(value)
+			ev = append(ev, evidence("board_date", cells))
+		}
+	}
+
+	if d.DPS == 0 && d.RecordDate == "" {
+		return d, nil, errNoDividend
+	}
+	return d, ev, nil
+}
@@ -0,0 +1,67 @@
+package extract
+
+import "testing"
+
+func TestParseDividend(t *testing.T) {
+	tests := []struct {
+		name    string
+		rows    [][]string
+		want    Dividend
+		wantErr bool
+	}{
+		{
+			name: "common and preferred on label rows",
+			rows: [][]string{
+				{"1. 배당구분", "", "결산배당"},
+				{"2. 배당종류", "", "현금배당"},
+				{"3. 1주당 배당금(원)", "보통주식", "1,444"},
+				{"", "종류주식", "1,445"},
+				{"4. 시가배당율(%)", "보통주식", "2.6"},
+				{"", "종류주식", "3.1"},
+				{"5. 배당금총액(원)", "", "9,809,438,586,000"},
+				{"6. 배당기준일", "", "2025-12-31"},
+				{"7. 배당금지급 예정일자", "", "2026년 04월 17일"},
+				{"9. 이사회결의일(결정일)", "", "2026.01.30"},
+			},
+			want: Dividend{
+				Kind: "결산배당", DPS: 1444, DPSPreferred: 1445, YieldPct: 2.6, Total: 9809438586000,
+				RecordDate: "2025-12-31", PaymentDate: "2026-04-17", BoardDate: "2026-01-30",
+			},
+		},
+		{
+			name: "share class on its own row",
+			rows: [][]string{
+				{"1주당 배당금(원)"},
+				{"보통주식", "361"},
+				{"종류주식", "-"},
+				{"배당기준일", "2026-03-31"},
+			},
+			want: Dividend{DPS: 361, RecordDate: "2026-03-31"},
+		},
+		{
+			name:    "no dividend table",
+			rows:    [][]string{{"회사명", "삼성전자"}, {"대표이사", "홍길동"}},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ev, err := ParseDividend(tt.rows)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("ParseDividend = %+v, want error", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("ParseDividend =\n%+v\nwant\n%+v", got, tt.want)
+			}
+			if len(ev) == 0 {
+				t.Error("no evidence")
+			}
+		})
+	}
+}
@@ -0,0 +1,133 @@
+package extract
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+)
+
+// Metric is one line of a 영업(잠정)실적 table in won: the reported
+// quarter, the previous quarter and the same quarter a year ago. Missing
+// values are nil.
+type Metric struct {
+	Current *float64 `json:"current"`
+	Prior   *float64 `json:"prior"`
+	YearAgo *float64 `json:"year_ago"`
+}
+
+// Earnings is the payload of an earnings event, from a 영업(잠정)실적
+// filing
+type Earnings struct {
+	Basis           string `json:"basis"` // consolidated or separate
+	Unit            string `json:"unit"`  // unit of the table; amounts are converted to won
+	Period          string `json:"period,omitempty"`
+	PriorPeriod     string `json:"prior_period,omitempty"`
+	YearAgoPeriod   string `json:"year_ago_period,omitempty"`
+	Revenue         Metric `json:"revenue"`
+	OperatingProfit Metric `json:"operating_profit"`
+	NetIncome       Metric `json:"net_income"`
+}
+
+var errNoEarnings = errors.New("no earnings table found")
+
+var (
+	unitPattern   = regexp.MustCompile(`단위\s*:\s*(천원|백만원|십억원|억원|조원|원)`)
+	periodPattern = regexp.MustCompile(`^\(?\s*(\d{4}\s*\.\s*\d?\s*[QH]|\d{4}\s*\.\s*\d{1,2}|\d{4}년.*)\s*\)?$`)
+)
+
+var unitScale = map[string]float64{
+	"원":   1,
+	"천원":  1e3,
+	"백만원": 1e6,
+	"억원":  1e8,
+	"십억원": 1e9,
+	"조원":  1e12,
+}
+
+// ParseEarnings reads the first (consolidated when present) results table
+// of a 영업(잠정)실적(공정공시) filing. Only the 당해실적 (quarter) rows are
+// used, not the 누계실적 (year to date) ones.
+func ParseEarnings(rows [][]string) (Earnings, []Evidence, error) {
+	e := Earnings{Unit: "원"}
+	var ev []Evidence
+	scale := 1.0
+
+	for _, cells := range rows {
+		joined := strings.Join(cells, " ")
+		if e.Basis == "" {
+			switch {
+			case strings.Contains(joined, "연결실적"):
+				e.Basis = "consolidated"
+			case strings.Contains(joined, "개별실적") || strings.Contains(joined, "별도실적"):
+				e.Basis = "separate"
+			}
+		} else if e.Revenue.Current != nil && (strings.Contains(joined, "개별실적") || strings.Contains(joined, "별도실적")) {
+			break // the separate table following the consolidated one
+		}
+		if m := unitPattern.FindStringSubmatch(joined); m != nil && e.Revenue.Current == nil {
+			e.Unit, scale = m[1], unitScale[m[1]]
+		}
+
+		// Period labels such as (2024.1Q) under the column headers
+		if filled := compact(cells); e.Period == "" && len(filled) >= 2 && periodPattern.MatchString(filled[0]) {
+			var periods []string
+			for _, c := range filled {
+				if periodPattern.MatchString(c) {
+					periods = append(periods, strings.Trim(strings.ReplaceAll(c, " ", ""), "()"))
+				}
+			}
+			if len(periods) >= 2 {
+				e.Period, e.PriorPeriod = periods[0], periods[1]
+				if len(periods) >= 3 {
+					e.YearAgoPeriod = periods[2]
+				}
+			}
+			continue
+		}
+
+		if len(cells) < 3 || normLabel(cells[1]) != "당해실적" {
+			continue
+		}
+		var metric *Metric
+		field := ""
+		switch name := normLabel(cells[0]); {
+		case name == "매출액" || name == "영업수익":
+			metric, field = &e.Revenue, "revenue"
+		case name == "영업이익":
+			metric, field = &e.OperatingProfit, "operating_profit"
+		case name == "당기순이익":
+			metric, field = &e.NetIncome, "net_income"
+		default:
+			continue
+		}
+		if metric.Current != nil {
+			continue
+		}
+		// 당기실적, 전기실적, 전기대비증감율, 전년동기실적, 전년동기대비증감율
+		values := cells[2:]
+		metric.Current = amount(values, 0, scale)
+		metric.Prior = amount(values, 1, scale)
+		metric.YearAgo = amount(values, 3, scale)
+		ev = append(ev, evidence(field, cells))
+	}
+
+	if e.Revenue.Current == nil && e.OperatingProfit.Current == nil {
+		return e, nil, errNoEarnings
+	}
+	if e.Basis == "" {
+		e.Basis = "consolidated"
+	}
+	return e, ev, nil
+}
+
+func amount(values []string, i int, scale float64) *float64 {
+	if i >= len(values) {
+		return nil
+	}
+	n, ok := parseNumber(values[i])
+	if !ok {
+		return nil
+	}
+	n *= scale
+	return &n
+}
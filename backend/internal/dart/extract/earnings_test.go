@@ -0,0 +1,111 @@
+package extract
+
+import (
+	"strconv"
+	"testing"
+)
+
+func ptr(f float64) *float64 { return &f }
+
+func equalAmount(a, b *float64) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+func equalMetric(a, b Metric) bool {
+	return equalAmount(a.Current, b.Current) && equalAmount(a.Prior, b.Prior) && equalAmount(a.YearAgo, b.YearAgo)
+}
+
+func TestParseEarnings(t *testing.T) {
+	tests := []struct {
+		name    string
+		rows    [][]string
+		want    Earnings
+		wantErr bool
+	}{
+		{
+			name: "consolidated before separate",
+			rows: [][]string{
+				{"※ 동 정보는 확정치가 아닌 잠정치로서 향후 확정치와는 다를 수 있음."},
+				{"1. 연결실적내용", "", "", "", "", "", "(단위 : 억원, %)"},
+				{"구분(단위 : 억원, %)", "", "당기실적", "전기실적", "전기대비증감율(%)", "전년동기실적", "전년동기대비증감율(%)"},
+				{"", "", "(2026.3Q)", "(2026.2Q)", "", "(2025.3Q)", ""},
+				{"매출액", "당해실적", "860,000", "745,663", "15.3", "790,987", "8.7"},
+				{"", "누계실적", "2,290,000", "1,430,000", "-", "2,250,000", "1.8"},
+				{"영업이익", "당해실적", "121,000", "47,000", "157.4", "91,834", "31.8"},
+				{"당기순이익", "당해실적", "△1,000", "-", "-", "", ""},
+				{"2. 개별실적내용"},
+				{"매출액", "당해실적", "1", "2", "", "3", ""},
+			},
+			want: Earnings{
+				Basis: "consolidated", Unit: "억원",
+				Period: "2026.3Q", PriorPeriod: "2026.2Q", YearAgoPeriod: "2025.3Q",
+				Revenue:         Metric{ptr(860000e8), ptr(745663e8), ptr(790987e8)},
+				OperatingProfit: Metric{ptr(121000e8), ptr(47000e8), ptr(91834e8)},
+				NetIncome:       Metric{Current: ptr(-1000e8)},
+			},
+		},
+		{
+			name: "separate only",
+			rows: [][]string{
+				{"1. 별도실적내용", "(단위 : 백만원)"},
+				{"영업수익", "당해실적", "5,000", "4,000", "25.0", "3,000", "66.7"},
+			},
+			want: Earnings{
+				Basis: "separate", Unit: "백만원",
+				Revenue: Metric{ptr(5000e6), ptr(4000e6), ptr(3000e6)},
+			},
+		},
+		{
+			name:    "no results table",
+			rows:    [][]string{{"회사명", "삼성전자"}},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, _, err := ParseEarnings(tt.rows)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("ParseEarnings = %+v, want error", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got.Basis != tt.want.Basis || got.Unit != tt.want.Unit || got.Period != tt.want.Period ||
+				got.PriorPeriod != tt.want.PriorPeriod || got.YearAgoPeriod != tt.want.YearAgoPeriod {
+				t.Errorf("ParseEarnings = %s %s %s %s %s, want %s %s %s %s %s",
+					got.Basis, got.Unit, got.Period, got.PriorPeriod, got.YearAgoPeriod,
+					tt.want.Basis, tt.want.Unit, tt.want.Period, tt.want.PriorPeriod, tt.want.YearAgoPeriod)
+			}
+			for _, m := range []struct {
+				name      string
+				got, want Metric
+			}{
+				{"revenue", got.Revenue, tt.want.Revenue},
+				{"operating_profit", got.OperatingProfit, tt.want.OperatingProfit},
+				{"net_income", got.NetIncome, tt.want.NetIncome},
+			} {
+				if !equalMetric(m.got, m.want) {
+					t.Errorf("%s = %s, want %s", m.name, formatMetric(m.got), formatMetric(m.want))
+				}
+			}
+		})
+	}
+}
+
+func formatMetric(m Metric) string {
+	s := ""
+	for _, v := range []*float64{m.Current, m.Prior, m.YearAgo} {
+		if v == nil {
+			s += " nil"
+		} else {
+			s += " " + strconv.FormatFloat(*v, 'f', -1, 64)
+		}
+	}
+	return "[" + s[1:] + "]"
+}
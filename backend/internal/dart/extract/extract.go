@@ -0,0 +1,259 @@
+package extract
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"path"
+	"regexp"
+	"time"
+
+	"dx-unified/internal/dart/models"
+	"dx-unified/internal/dart/storage"
+	"dx-unified/internal/dart/taxonomy"
+
+	"golang.org/x/net/html/charset"
+	"gorm.io/gorm"
+)
+
+// Event types written by the extractor
+const (
+	EventCashDividend = "cash_dividend"
+	EventEarnings     = "earnings"
+)
+
+// parsers by taxonomy category
+var parsers = map[string]struct {
+	eventType string
+	parse     func(rows [][]string) (interface{}, []Evidence, error)
+}{
+	"dividend": {EventCashDividend, func(rows [][]string) (interface{}, []Evidence, error) {
+		return ParseDividend(rows)
+	}},
+	"earnings": {EventEarnings, func(rows [][]string) (interface{}, []Evidence, error) {
+		return ParseEarnings(rows)
+	}},
+}
+
+// Categories returns the taxonomy categories with a parser
+func Categories() []string {
+	categories := make([]string, 0, len(parsers))
+	for c := range parsers {
+		categories = append(categories, c)
+	}
+	return categories
+}
+
+const (
+	maxDocumentBytes = 20 << 20
+	maxZipBytes      = 100 << 20
+)
+
+var xmlEncoding = regexp.MustCompile(`^\s*<\?xml[^>]*encoding=["']([^"']+)["']`)
+
+// ErrBadDocument marks a stored document that was read but cannot be used,
+// e.g. a corrupt zip or tables no parser understands. Retrying does not help,
+// unlike errors of the document store.
+var ErrBadDocument = errors.New("bad document")
+
+// Run extracts events from up to limit downloaded documents not processed
+// yet and returns the number of events saved. Only filings of a category
+// with a parser are processed. Documents that could not be parsed are marked
+// as extracted so they are not retried on every run; documents whose blob is
+// gone are queued for download again, and a document store error stops the
+// run so the rest of the batch is retried next time.
+func Run(db *gorm.DB, blobs *storage.Blobs, limit int) (int, error) {
+	var docs []struct {
+		models.FilingDocument
+		Category        string
+		OriginalRceptNo string
+	}
+	err := db.Table("filing_documents").
+		Select("filing_documents.*, filings.category, filings.original_rcept_no").
+		Joins("JOIN filings ON filings.rcept_no = filing_documents.rcept_no").
+		Where("filing_documents.state = ? AND filing_documents.extracted_at IS NULL", models.DocStateDone).
+		Where("filings.category IN ?", Categories()).
+		Order("filing_documents.id").
+		Limit(limit).
+		Scan(&docs).Error
+	if err != nil {
+		return 0, err
+	}
+
+	saved := 0
+	for _, doc := range docs {
+		p := parsers[doc.Category]
+		payload, ev, err := extractDocument(blobs, &doc.FilingDocument, p.parse)
+		switch {
+		case errors.Is(err, storage.ErrNotFound):
+			log.Printf("[DART] Document of %s is missing from the store, downloading it again\n", doc.RceptNo)
+			if err := Requeue(db, doc.ID); err != nil {
+				return saved, err
+			}
+			continue
+		case errors.Is(err, ErrBadDocument):
+			log.Printf("[DART] No %s event in %s: %v\n", p.eventType, doc.RceptNo, err)
+		case err != nil:
+			return saved, fmt.Errorf("load document of %s: %w", doc.RceptNo, err)
+		}
+
+		err = db.Transaction(func(tx *gorm.DB) error {
+			if payload != nil {
+				if err := saveEvent(tx, doc.RceptNo, p.eventType, payload, ev); err != nil {
+					return err
+				}
+			}
+			return tx.Model(&models.FilingDocument{}).Where("id = ?", doc.ID).Update("extracted_at", time.Now()).Error
+		})
+		if err != nil {
+			return saved, err
+		}
+		if payload == nil {
+			continue
+		}
+		saved++
+
+		// A correction replaces the events of earlier versions
+		original := doc.OriginalRceptNo
+		if original == "" {
+			original = doc.RceptNo
+		}
+		if err := taxonomy.MarkSuperseded(db, original); err != nil {
+			log.Printf("[DART] Error marking superseded events of %s: %v\n", original, err)
+		}
+	}
+	return saved, nil
+}
+
+// Requeue queues a downloaded document whose blob is gone for download
+func Requeue(db *gorm.DB, id uint) error {
+	return db.Model(&models.FilingDocument{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"state":           models.DocStatePending,
+		"storage_uri":     "",
+		"sha256":          "",
+		"retry_count":     0,
+		"next_attempt_at": nil,
+	}).Error
+}
+
+// saveEvent replaces the event of a filing with a newly extracted one
+func saveEvent(tx *gorm.DB, rceptNo, eventType string, payload interface{}, ev []Evidence) error {
+	payloadJSON, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	evidenceJSON, err := json.Marshal(ev)
+	if err != nil {
+		return err
+	}
+	if err := tx.Where("rcept_no = ? AND event_type = ?", rceptNo, eventType).Delete(&models.ExtractedEvent{}).Error; err != nil {
+		return err
+	}
+	return tx.Create(&models.ExtractedEvent{
+		RceptNo:           rceptNo,
+		EventType:         eventType,
+		PayloadJSON:       string(payloadJSON),
+		EvidenceSpansJSON: string(evidenceJSON),
+	}).Error
+}
+
+// extractDocument parses the main document of a filing zip
+func extractDocument(blobs *storage.Blobs, fd *models.FilingDocument, parse func([][]string) (interface{}, []Evidence, error)) (interface{}, []Evidence, error) {
+	doc, err := LoadMainDocument(blobs, fd)
+	if err != nil {
+		return nil, nil, err
+	}
+	payload, ev, err := parse(Rows(doc))
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: %v", ErrBadDocument, err)
+	}
+	return payload, ev, nil
+}
+
+// LoadMainDocument opens the stored zip of a downloaded document and
+// returns its main document as UTF-8. Blobs not on local disk are read
+// into memory, up to maxZipBytes. Documents that were read but are not
+// usable fail with ErrBadDocument, missing blobs with storage.ErrNotFound.
+func LoadMainDocument(blobs *storage.Blobs, fd *models.FilingDocument) (string, error) {
+	rc, size, err := blobs.Open(fd.StorageURI, fd.SHA256)
+	if err != nil {
+		return "", err
+	}
+	defer rc.Close()
+
+	r, ok := rc.(io.ReaderAt)
+	if !ok {
+		if size > maxZipBytes {
+			return "", fmt.Errorf("%w: zip of %s is too large (%d bytes)", ErrBadDocument, fd.RceptNo, size)
+		}
+		data, err := io.ReadAll(io.LimitReader(rc, maxZipBytes+1))
+		if err != nil {
+			return "", err
+		}
+		if len(data) > maxZipBytes {
+			return "", fmt.Errorf("%w: zip of %s is too large", ErrBadDocument, fd.RceptNo)
+		}
+		r, size = bytes.NewReader(data), int64(len(data))
+	}
+	text, err := ReadMainDocument(r, size, fd.RceptNo)
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrBadDocument, err)
+	}
+	return text, nil
+}
+
+// ReadMainDocument returns the main document of a filing zip as UTF-8.
+// The zip holds {rcept_no}.xml and attachments named {rcept_no}_NNNNN.xml.
+func ReadMainDocument(r io.ReaderAt, size int64, rceptNo string) (string, error) {
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return "", err
+	}
+
+	var main *zip.File
+	for _, f := range zr.File {
+		name := path.Base(f.Name)
+		if name == rceptNo+".xml" {
+			main = f
+			break
+		}
+		if main == nil && path.Ext(name) == ".xml" {
+			main = f
+		}
+	}
+	if main == nil {
+		return "", fmt.Errorf("no xml document in the zip of %s", rceptNo)
+	}
+
+	f, err := main.Open()
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	data, err := io.ReadAll(io.LimitReader(f, maxDocumentBytes))
+	if err != nil {
+		return "", err
+	}
+	return decode(data), nil
+}
+
+// decode converts documents declared as EUC-KR (older filings) to UTF-8
+func decode(data []byte) string {
+	m := xmlEncoding.FindSubmatch(data)
+	if m == nil {
+		return string(data)
+	}
+	r, err := charset.NewReaderLabel(string(m[1]), bytes.NewReader(data))
+	if err != nil {
+		return string(data)
+	}
+	out, err := io.ReadAll(r)
+	if err != nil {
+		return string(data)
+	}
+	return string(out)
+}
@@ -0,0 +1,107 @@
+package extract
+
+import (
+	"html"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// DART documents are XML with HTML-like tables; cells are TD, TH, TE
+// (text) or TU (unit) elements
+var (
+	rowPattern   = regexp.MustCompile(`(?is)<TR\b[^>]*>(.*?)</TR>`)
+	cellPattern  = regexp.MustCompile(`(?is)<(?:TD|TH|TE|TU)\b[^>]*>(.*?)</(?:TD|TH|TE|TU)>`)
+	tagPattern   = regexp.MustCompile(`(?s)<[^>]+>`)
+	spacePattern = regexp.MustCompile(`\s+`)
+	labelPrefix  = regexp.MustCompile(`^(\d+\.|[-ㆍ·]|\(\d+\))`)
+	datePattern  = regexp.MustCompile(`(\d{4})\D{1,3}(\d{1,2})\D{1,3}(\d{1,2})`)
+)
+
+// Rows returns the text of the table cells of a document, row by row.
+// Empty cells are kept so columns line up; rows without text are dropped.
+func Rows(doc string) [][]string {
+	var rows [][]string
+	for _, rm := range rowPattern.FindAllStringSubmatch(doc, -1) {
+		var cells []string
+		for _, cm := range cellPattern.FindAllStringSubmatch(rm[1], -1) {
+			text := html.UnescapeString(tagPattern.ReplaceAllString(cm[1], " "))
+			text = strings.TrimSpace(spacePattern.ReplaceAllString(strings.ReplaceAll(text, "\u00a0", " "), " "))
+			cells = append(cells, text)
+		}
+		if len(compact(cells)) > 0 {
+			rows = append(rows, cells)
+		}
+	}
+	return rows
+}
+
+// compact drops the empty cells of a row
+func compact(cells []string) []string {
+	out := make([]string, 0, len(cells))
+	for _, c := range cells {
+		if c != "" {
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+// normLabel makes a row label comparable: no spaces or numbering
+func normLabel(s string) string {
+	s = strings.ReplaceAll(s, " ", "")
+	return labelPrefix.ReplaceAllString(s, "")
+}
+
+// parseNumber parses amounts such as "1,234", "-1,234", "(1,234)" or
+// "△1,234"; ok is false for "-" and text
+func parseNumber(s string) (float64, bool) {
+	s = strings.ReplaceAll(strings.TrimSpace(s), ",", "")
+	neg := false
+	switch {
+	case strings.HasPrefix(s, "△"):
+		neg, s = true, strings.TrimPrefix(s, "△")
+	case strings.HasPrefix(s, "(") && strings.HasSuffix(s, ")"):
+		neg, s = true, s[1:len(s)-1]
+	}
+	f, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+	if err != nil {
+		return 0, false
+	}
+	if neg {
+		f = -f
+	}
+	return f, true
+}
+
+// parseDate finds a date such as 2024-03-31, 2024.03.31 or 2024년 3월 31일
+// and returns it as YYYY-MM-DD
+func parseDate(s string) string {
+	m := datePattern.FindStringSubmatch(s)
+	if m == nil {
+		return ""
+	}
+	month, _ := strconv.Atoi(m[2])
+	day, _ := strconv.Atoi(m[3])
+	if month < 1 || month > 12 || day < 1 || day > 31 {
+		return ""
+	}
+	return m[1] + "-" + pad2(month) + "-" + pad2(day)
+}
+
+func pad2(n int) string {
+	if n < 10 {
+		return "0" + strconv.Itoa(n)
+	}
+	return strconv.Itoa(n)
+}
+
+// Evidence is a table row a value was taken from
+type Evidence struct {
+	Field string `json:"field"`
+	Text  string `json:"text"`
+}
+
+func evidence(field string, cells []string) Evidence {
+	return Evidence{Field: field, Text: strings.Join(compact(cells), " | ")}
+}
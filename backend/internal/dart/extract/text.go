@@ -0,0 +1,36 @@
+package extract
+
+import (
+	"html"
+	"regexp"
+	"strings"
+)
+
+var (
+	// Elements ending a line of text: paragraphs, table rows, titles and
+	// line breaks
+	breakPattern = regexp.MustCompile(`(?i)</(?:P|TR|TITLE|TABLE|SECTION-\d|LIBRARY|PGBRK)>|<(?:BR|PGBRK)\s*/?>`)
+	// Cells are separated by a space
+	cellEndPattern = regexp.MustCompile(`(?i)</(?:TD|TH|TE|TU)>`)
+	// Tags whose content is not text
+	skipPattern = regexp.MustCompile(`(?is)<(?:COVER-TITLE-IMG|IMAGE|IMG)\b[^>]*>.*?</(?:COVER-TITLE-IMG|IMAGE|IMG)>|<\?xml[^>]*\?>|<!--.*?-->`)
+)
+
+// Text returns the plain text of a document, one paragraph or table row
+// per line
+func Text(doc string) string {
+	doc = skipPattern.ReplaceAllString(doc, "")
+	doc = breakPattern.ReplaceAllString(doc, "\n")
+	doc = cellEndPattern.ReplaceAllString(doc, " ")
+	doc = html.UnescapeString(tagPattern.ReplaceAllString(doc, " "))
+	doc = strings.ReplaceAll(doc, "\u00a0", " ")
+
+	var lines []string
+	for _, line := range strings.Split(doc, "\n") {
+		line = strings.TrimSpace(spacePattern.ReplaceAllString(line, " "))
+		if line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
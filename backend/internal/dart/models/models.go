@@ -10,6 +10,43 @@ type Corp struct {
 	CorpName   string    `gorm:"column:corp_name;type:varchar(200);index" json:"corp_name"`
 	StockCode  string    `gorm:"column:stock_code;type:varchar(20);index" json:"stock_code"`
 	ModifiedAt time.Time `gorm:"column:modified_at" json:"modified_at"`
+
+	// Listing status, maintained by the corp code refresh (see CorpHistory).
+	// Dates are YYYYMMDD and empty when the change predates the history.
+	Status     string `gorm:"column:status;type:varchar(10);index" json:"status"`
+	ListedAt   string `gorm:"column:listed_at;type:varchar(8)" json:"listed_at,omitempty"`
+	DelistedAt string `gorm:"column:delisted_at;type:varchar(8)" json:"delisted_at,omitempty"`
+}
+
+// Listing statuses of a Corp
+const (
+	CorpListed   = "listed"
+	CorpDelisted = "delisted" // had a stock code that was dropped
+	CorpUnlisted = "unlisted" // never had a stock code
+)
+
+// Fields of a CorpHistory change
+const (
+	CorpFieldName      = "corp_name"
+	CorpFieldStockCode = "stock_code"
+	CorpFieldStatus    = "status"
+)
+
+// CorpHistory is one change of a corp detected between two corp code
+// refreshes: a rename, a new stock code or a listing status change
+type CorpHistory struct {
+	ID         uint      `gorm:"primaryKey;autoIncrement" json:"id"`
+	CorpCode   string    `gorm:"column:corp_code;type:varchar(20);index" json:"corp_code"`
+	Field      string    `gorm:"column:field;type:varchar(20)" json:"field"`
+	OldValue   string    `gorm:"column:old_value;type:varchar(200)" json:"old_value"`
+	NewValue   string    `gorm:"column:new_value;type:varchar(200)" json:"new_value"`
+	ChangedOn  string    `gorm:"column:changed_on;type:varchar(8);index" json:"changed_on"` // YYYYMMDD, DART's modify_date when it moved
+	DetectedAt time.Time `gorm:"column:detected_at" json:"detected_at"`
+}
+
+// TableName overrides GORM's plural corp_histories
+func (CorpHistory) TableName() string {
+	return "corp_history"
 }
 
 // Filing represents the disclosure metadata
@@ -23,8 +60,35 @@ type Filing struct {
 	Rm        string    `gorm:"column:rm;type:varchar(50)" json:"rm"`
 	DcmNo     string    `gorm:"column:dcm_no;type:varchar(20)" json:"dcm_no"`
 	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
+
+	// Returned by list.json
+	StockCode string `gorm:"column:stock_code;type:varchar(20)" json:"stock_code"`
+	CorpCls   string `gorm:"column:corp_cls;type:varchar(1);index" json:"corp_cls"` // Y KOSPI, K KOSDAQ, N KONEX, E other
+
+	// Taxonomy, see internal/dart/taxonomy. list.json returns neither
+	// pblntf_ty nor pblntf_detail_ty; both are derived from the report name.
+	PblntfTy       string `gorm:"column:pblntf_ty;type:varchar(1);index" json:"pblntf_ty"`
+	PblntfDetailTy string `gorm:"column:pblntf_detail_ty;type:varchar(4)" json:"pblntf_detail_ty"`
+	Category       string `gorm:"column:category;type:varchar(50);index" json:"category"`
+	IsCorrection   bool   `gorm:"column:is_correction;index" json:"is_correction"` // [기재정정], [첨부정정]
+	HasAttached    bool   `gorm:"column:has_attached" json:"has_attached"`         // [첨부정정], [첨부추가]
+
+	// Correction chain: a correction points to the version it corrects and
+	// to the first version; a corrected filing points to its successor
+	CorrectsRceptNo string `gorm:"column:corrects_rcept_no;type:varchar(20)" json:"corrects_rcept_no,omitempty"`
+	OriginalRceptNo string `gorm:"column:original_rcept_no;type:varchar(20);index" json:"original_rcept_no,omitempty"`
+	SupersededBy    string `gorm:"column:superseded_by;type:varchar(20);index" json:"superseded_by,omitempty"`
 }
 
+// Download states of a FilingDocument
+const (
+	DocStatePending     = "pending"
+	DocStateDownloading = "downloading"
+	DocStateDone        = "done"
+	DocStateFailed      = "failed" // retried after NextAttemptAt
+	DocStateDead        = "dead"   // gave up after the max retries, retried only manually
+)
+
 // FilingDocument represents the physical files downloaded
 type FilingDocument struct {
 	ID          uint       `gorm:"primaryKey;autoIncrement" json:"id"`
@@ -35,6 +99,12 @@ type FilingDocument struct {
 	FetchedAt   time.Time  `gorm:"autoCreateTime" json:"fetched_at"`
 	ExtractedAt *time.Time `gorm:"column:extracted_at" json:"extracted_at"`
 	RetryCount  int        `gorm:"column:retry_count;default:0" json:"retry_count"`
+
+	// Rows created before download states existed were all downloaded
+	State         string     `gorm:"column:state;type:varchar(20);default:done;index" json:"state"`
+	NextAttemptAt *time.Time `gorm:"column:next_attempt_at" json:"next_attempt_at,omitempty"`
+	LastError     string     `gorm:"column:last_error;type:text" json:"last_error,omitempty"`
+	UpdatedAt     time.Time  `gorm:"autoUpdateTime" json:"updated_at"`
 }
 
 // ExtractedEvent represents structured data parsed from filings
@@ -45,4 +115,80 @@ type ExtractedEvent struct {
 	PayloadJSON       string    `gorm:"column:payload_json;type:text" json:"payload_json"`
 	EvidenceSpansJSON string    `gorm:"column:evidence_spans_json;type:text" json:"evidence_spans_json"`
 	CreatedAt         time.Time `gorm:"autoCreateTime" json:"created_at"`
+
+	// Set when a later correction of the filing has an event of the same type
+	Superseded   bool   `gorm:"column:superseded;index" json:"superseded"`
+	SupersededBy string `gorm:"column:superseded_by;type:varchar(20)" json:"superseded_by,omitempty"`
+}
+
+// States of a BackfillJob
+const (
+	BackfillQueued    = "queued"
+	BackfillRunning   = "running"
+	BackfillPaused    = "paused" // waiting for the daily request budget
+	BackfillDone      = "done"
+	BackfillFailed    = "failed"
+	BackfillCancelled = "cancelled"
+)
+
+// BackfillJob fetches the filing lists of a past date range day by day.
+// NextDate is the checkpoint a paused or interrupted job resumes from.
+type BackfillJob struct {
+	ID           uint       `gorm:"primaryKey;autoIncrement" json:"id"`
+	DateFrom     string     `gorm:"column:date_from;type:varchar(8)" json:"date_from"` // YYYYMMDD
+	DateTo       string     `gorm:"column:date_to;type:varchar(8)" json:"date_to"`
+	CorpCodes    string     `gorm:"column:corp_codes;type:text" json:"corp_codes"`            // comma separated, empty means all
+	FilingTypes  string     `gorm:"column:filing_types;type:varchar(50)" json:"filing_types"` // pblntf_ty codes, comma separated
+	State        string     `gorm:"column:state;type:varchar(20);index" json:"state"`
+	NextDate     string     `gorm:"column:next_date;type:varchar(8)" json:"next_date"`
+	DaysTotal    int        `gorm:"column:days_total" json:"days_total"`
+	DaysDone     int        `gorm:"column:days_done" json:"days_done"`
+	FilingsSaved int        `gorm:"column:filings_saved" json:"filings_saved"`
+	LastError    string     `gorm:"column:last_error;type:text" json:"last_error,omitempty"`
+	CreatedAt    time.Time  `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt    time.Time  `gorm:"autoUpdateTime" json:"updated_at"`
+	FinishedAt   *time.Time `gorm:"column:finished_at" json:"finished_at,omitempty"`
+}
+
+// FilingDay records that the complete filing list of a day (all companies
+// and types) was fetched, so days without filings can be told apart from
+// days never fetched
+type FilingDay struct {
+	Date      string    `gorm:"primaryKey;column:date;type:varchar(8)" json:"date"` // YYYYMMDD
+	Count     int       `gorm:"column:count" json:"count"`
+	FetchedAt time.Time `gorm:"column:fetched_at" json:"fetched_at"`
+}
+
+// Kinds of a HoldingChange
+const (
+	HoldingMajor   = "major"   // 5% holder report (majorstock.json)
+	HoldingInsider = "insider" // executive or major shareholder report (elestock.json)
+)
+
+// HoldingChange is one ownership report of a holder: the shares held
+// after the reported change and the change itself
+type HoldingChange struct {
+	ID           uint      `gorm:"primaryKey;autoIncrement" json:"id"`
+	RceptNo      string    `gorm:"column:rcept_no;type:varchar(20);uniqueIndex:idx_holding_report" json:"rcept_no"`
+	RceptDt      string    `gorm:"column:rcept_dt;type:varchar(8);index" json:"rcept_dt"` // YYYYMMDD
+	CorpCode     string    `gorm:"column:corp_code;type:varchar(20);index" json:"corp_code"`
+	CorpName     string    `gorm:"column:corp_name;type:varchar(200)" json:"corp_name"`
+	Kind         string    `gorm:"column:kind;type:varchar(10);uniqueIndex:idx_holding_report" json:"kind"`
+	Holder       string    `gorm:"column:holder;type:varchar(200);uniqueIndex:idx_holding_report" json:"holder"`
+	Position     string    `gorm:"column:position;type:varchar(100)" json:"position,omitempty"` // executive position
+	Relation     string    `gorm:"column:relation;type:varchar(100)" json:"relation,omitempty"` // report type or relation to the company
+	Shares       int64     `gorm:"column:shares" json:"shares"`
+	SharesChange int64     `gorm:"column:shares_change" json:"shares_change"`
+	Ratio        float64   `gorm:"column:ratio" json:"ratio"` // % of shares outstanding
+	RatioChange  float64   `gorm:"column:ratio_change" json:"ratio_change"`
+	Reason       string    `gorm:"column:reason;type:text" json:"reason,omitempty"`
+	CreatedAt    time.Time `gorm:"autoCreateTime" json:"created_at"`
+}
+
+// OwnershipSync records when the ownership reports of a corp were last
+// fetched, so only corps with newer ownership filings are fetched again
+type OwnershipSync struct {
+	CorpCode  string    `gorm:"primaryKey;column:corp_code;type:varchar(20)" json:"corp_code"`
+	Changes   int       `gorm:"column:changes" json:"changes"`
+	FetchedAt time.Time `gorm:"column:fetched_at" json:"fetched_at"`
 }
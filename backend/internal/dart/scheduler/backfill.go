@@ -0,0 +1,143 @@
+package scheduler
+
+import (
+	"errors"
+	"log"
+	"strings"
+	"time"
+
+	"dx-unified/internal/dart/database"
+	"dx-unified/internal/dart/models"
+	"dx-unified/pkg/dart"
+
+	"gorm.io/gorm"
+)
+
+// backfillDelay spaces the days of a backfill
+var backfillDelay = 200 * time.Millisecond
+
+// RunBackfills works through queued, paused and interrupted backfill jobs
+// in creation order. Only one run is active at a time; a job paused by the
+// request budget is resumed by a later run.
+func (j *DartJobs) RunBackfills() {
+	if !j.backfillRunning.CompareAndSwap(false, true) {
+		return
+	}
+	defer j.backfillRunning.Store(false)
+
+	for {
+		var job models.BackfillJob
+		err := database.DB.
+			Where("state IN ?", []string{models.BackfillQueued, models.BackfillRunning, models.BackfillPaused}).
+			Order("id").
+			First(&job).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return
+		}
+		if err != nil {
+			log.Printf("[DART] Error finding backfill jobs: %v\n", err)
+			return
+		}
+
+		if !j.runBackfill(&job) {
+			return
+		}
+	}
+}
+
+// runBackfill fetches the remaining days of a job, saving the checkpoint
+// after each day. It returns false when the job was paused by the budget.
+func (j *DartJobs) runBackfill(job *models.BackfillJob) bool {
+	if j.budget != nil && !j.budget.Available(dart.PriorityBackfill) {
+		if job.State != models.BackfillPaused {
+			updateBackfill(job, map[string]interface{}{"state": models.BackfillPaused, "last_error": "waiting for the daily request budget"})
+		}
+		return false
+	}
+
+	if !updateBackfill(job, map[string]interface{}{"state": models.BackfillRunning, "last_error": ""}) {
+		log.Printf("[DART] Backfill #%d cancelled\n", job.ID)
+		return true
+	}
+	log.Printf("[DART] Backfill #%d: %s..%s from %s\n", job.ID, job.DateFrom, job.DateTo, job.NextDate)
+
+	client := j.client.WithPriority(dart.PriorityBackfill)
+	corps := splitList(job.CorpCodes)
+	types := splitList(job.FilingTypes)
+	complete := job.CorpCodes == "" && job.FilingTypes == ""
+
+	end, _ := time.Parse("20060102", job.DateTo)
+	for day, err := time.Parse("20060102", job.NextDate); err == nil && !day.After(end); day = day.AddDate(0, 0, 1) {
+		date := day.Format("20060102")
+		count := 0
+		for _, corp := range corps {
+			for _, typ := range types {
+				filings, err := client.GetFilings(dart.ListParams{BeginDate: date, EndDate: date, CorpCode: corp, FilingType: typ})
+				if dart.IsBudgetError(err) {
+					log.Printf("[DART] Backfill #%d paused at %s: %v\n", job.ID, date, err)
+					updateBackfill(job, map[string]interface{}{"state": models.BackfillPaused, "last_error": err.Error()})
+					return false
+				}
+				if err == nil {
+					err = saveFilings(filings)
+				}
+				if err != nil {
+					log.Printf("[DART] Backfill #%d failed at %s: %v\n", job.ID, date, err)
+					updateBackfill(job, map[string]interface{}{"state": models.BackfillFailed, "last_error": err.Error()})
+					return true
+				}
+				count += len(filings)
+			}
+		}
+		if complete {
+			recordFilingDay(date, count)
+		}
+
+		job.NextDate = day.AddDate(0, 0, 1).Format("20060102")
+		job.DaysDone++
+		job.FilingsSaved += count
+		// Cancellation is checked between days
+		if !updateBackfill(job, map[string]interface{}{
+			"next_date":     job.NextDate,
+			"days_done":     job.DaysDone,
+			"filings_saved": job.FilingsSaved,
+		}) {
+			log.Printf("[DART] Backfill #%d cancelled\n", job.ID)
+			return true
+		}
+		time.Sleep(backfillDelay)
+	}
+
+	now := time.Now()
+	if !updateBackfill(job, map[string]interface{}{"state": models.BackfillDone, "finished_at": &now}) {
+		log.Printf("[DART] Backfill #%d cancelled\n", job.ID)
+		return true
+	}
+	log.Printf("[DART] Backfill #%d done: %d filings over %d days\n", job.ID, job.FilingsSaved, job.DaysDone)
+	return true
+}
+
+// updateBackfill updates a job unless it was cancelled meanwhile, so a run
+// never overwrites a cancel. It reports false when the job was cancelled.
+func updateBackfill(job *models.BackfillJob, fields map[string]interface{}) bool {
+	res := database.DB.Model(job).Where("state <> ?", models.BackfillCancelled).Updates(fields)
+	if res.Error != nil {
+		log.Printf("[DART] Error updating backfill #%d: %v\n", job.ID, res.Error)
+	}
+	return res.RowsAffected == 1
+}
+
+// splitList splits a comma separated list; an empty list yields one empty
+// entry meaning "no filter"
+func splitList(s string) []string {
+	var out []string
+	for _, v := range strings.Split(s, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			out = append(out, v)
+		}
+	}
+	if len(out) == 0 {
+		return []string{""}
+	}
+	return out
+}
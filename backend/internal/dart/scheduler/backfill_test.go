@@ -0,0 +1,146 @@
+package scheduler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"dx-unified/internal/dart/database"
+	"dx-unified/internal/dart/models"
+	"dx-unified/pkg/dart"
+)
+
+// listStub answers list.json with one filing per weekday and "013" (no
+// data) on weekends, calling onRequest with the date first
+type listStub struct {
+	requests  []string
+	onRequest func(date string)
+}
+
+func (s *listStub) RoundTrip(req *http.Request) (*http.Response, error) {
+	date := req.URL.Query().Get("bgn_de")
+	s.requests = append(s.requests, date)
+	if s.onRequest != nil {
+		s.onRequest(date)
+	}
+
+	body := map[string]interface{}{"status": "013", "message": "no data"}
+	if day, _ := time.Parse("20060102", date); day.Weekday() != time.Saturday && day.Weekday() != time.Sunday {
+		body = map[string]interface{}{
+			"status":     "000",
+			"page_no":    1,
+			"total_page": 1,
+			"list": []models.Filing{{
+				RceptNo:  date + "000001",
+				CorpCode: "00126380",
+				CorpName: "삼성전자",
+				ReportNm: "주요사항보고서",
+				RceptDt:  date,
+			}},
+		}
+	}
+	rec := httptest.NewRecorder()
+	json.NewEncoder(rec).Encode(body)
+	return rec.Result(), nil
+}
+
+func newBackfillJobs(t *testing.T, stub *listStub, budget *dart.Budget) *DartJobs {
+	prev := backfillDelay
+	backfillDelay = 0
+	t.Cleanup(func() { backfillDelay = prev })
+
+	client := dart.NewClient("test")
+	client.HTTPClient = &http.Client{Transport: stub}
+	client.Budget = budget
+	return &DartJobs{client: client, budget: budget}
+}
+
+func createJob(t *testing.T, from, to string) models.BackfillJob {
+	job := models.BackfillJob{DateFrom: from, DateTo: to, NextDate: from, State: models.BackfillQueued}
+	if err := database.DB.Create(&job).Error; err != nil {
+		t.Fatal(err)
+	}
+	return job
+}
+
+func getJob(t *testing.T, id uint) models.BackfillJob {
+	var job models.BackfillJob
+	if err := database.DB.First(&job, id).Error; err != nil {
+		t.Fatal(err)
+	}
+	return job
+}
+
+// TestBackfillResume checks that a job paused by the budget keeps its
+// checkpoint and continues from it on the next run
+func TestBackfillResume(t *testing.T) {
+	openTestDB(t)
+	job := createJob(t, "20260302", "20260311")
+
+	// Backfills pause at 70% of a budget of 10 requests, one request a day
+	stub := &listStub{}
+	budget := dart.NewBudget(filepath.Join(t.TempDir(), "budget.json"), 10)
+	newBackfillJobs(t, stub, budget).RunBackfills()
+
+	got := getJob(t, job.ID)
+	if got.State != models.BackfillPaused || got.NextDate != "20260309" || got.DaysDone != 7 || got.FilingsSaved != 5 {
+		t.Fatalf("after pause: state %s, next %s, days %d, filings %d", got.State, got.NextDate, got.DaysDone, got.FilingsSaved)
+	}
+
+	stub.requests = nil
+	newBackfillJobs(t, stub, nil).RunBackfills()
+
+	got = getJob(t, job.ID)
+	if got.State != models.BackfillDone || got.NextDate != "20260312" || got.DaysDone != 10 || got.FilingsSaved != 8 || got.FinishedAt == nil {
+		t.Errorf("after resume: state %s, next %s, days %d, filings %d", got.State, got.NextDate, got.DaysDone, got.FilingsSaved)
+	}
+	if len(stub.requests) != 3 || stub.requests[0] != "20260309" {
+		t.Errorf("resumed requests = %v, want 20260309..20260311", stub.requests)
+	}
+
+	var filings int64
+	database.DB.Model(&models.Filing{}).Count(&filings)
+	var days []models.FilingDay
+	database.DB.Order("date").Find(&days)
+	if filings != 8 || len(days) != 10 || days[5].Date != "20260307" || days[5].Count != 0 {
+		t.Errorf("stored %d filings and filing days %+v", filings, days)
+	}
+}
+
+// TestBackfillCancel checks that a cancel is kept when it arrives while
+// the run is starting or fetching the last day
+func TestBackfillCancel(t *testing.T) {
+	openTestDB(t)
+	cancel := func(id uint) {
+		database.DB.Model(&models.BackfillJob{}).Where("id = ?", id).Update("state", models.BackfillCancelled)
+	}
+
+	t.Run("before start", func(t *testing.T) {
+		job := createJob(t, "20260302", "20260303")
+		stub := &listStub{}
+		cancel(job.ID)
+		newBackfillJobs(t, stub, nil).runBackfill(&job)
+
+		if got := getJob(t, job.ID); got.State != models.BackfillCancelled || len(stub.requests) != 0 {
+			t.Errorf("state %s after %d requests", got.State, len(stub.requests))
+		}
+	})
+
+	t.Run("last day", func(t *testing.T) {
+		job := createJob(t, "20260302", "20260303")
+		stub := &listStub{onRequest: func(date string) {
+			if date == "20260303" {
+				cancel(job.ID)
+			}
+		}}
+		newBackfillJobs(t, stub, nil).runBackfill(&job)
+
+		got := getJob(t, job.ID)
+		if got.State != models.BackfillCancelled || got.FinishedAt != nil || got.DaysDone != 1 {
+			t.Errorf("state %s, days %d, finished %v", got.State, got.DaysDone, got.FinishedAt)
+		}
+	})
+}
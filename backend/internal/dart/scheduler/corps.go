@@ -0,0 +1,116 @@
+package scheduler
+
+import (
+	"strings"
+	"time"
+
+	"dx-unified/internal/dart/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const corpBatchSize = 100
+
+// syncCorps upserts the fetched corp codes and records in corp_history what
+// changed since the previous refresh. A change is dated with DART's
+// modify_date when it moved, otherwise with the refresh day. Corps missing
+// from the fetched list are left as they are. On the first load nothing is
+// recorded, and listing dates stay unknown.
+func syncCorps(db *gorm.DB, corps []models.Corp, now time.Time) (int, error) {
+	var existing []models.Corp
+	if err := db.Find(&existing).Error; err != nil {
+		return 0, err
+	}
+	stored := make(map[string]models.Corp, len(existing))
+	for _, c := range existing {
+		stored[c.CorpCode] = c
+	}
+	firstLoad := len(existing) == 0
+	today := now.Format("20060102")
+
+	var history []models.CorpHistory
+	record := func(corpCode, field, oldValue, newValue, changedOn string) {
+		history = append(history, models.CorpHistory{
+			CorpCode:   corpCode,
+			Field:      field,
+			OldValue:   oldValue,
+			NewValue:   newValue,
+			ChangedOn:  changedOn,
+			DetectedAt: now,
+		})
+	}
+
+	for i := range corps {
+		c := &corps[i]
+		c.StockCode = strings.TrimSpace(c.StockCode)
+		old, ok := stored[c.CorpCode]
+
+		changedOn := today
+		if !c.ModifiedAt.IsZero() && (!ok || c.ModifiedAt.After(old.ModifiedAt)) {
+			changedOn = c.ModifiedAt.Format("20060102")
+		}
+
+		if !ok {
+			c.Status = models.CorpUnlisted
+			if c.StockCode != "" {
+				c.Status = models.CorpListed
+				if !firstLoad {
+					c.ListedAt = changedOn
+					record(c.CorpCode, models.CorpFieldStatus, "", models.CorpListed, changedOn)
+				}
+			}
+			continue
+		}
+
+		c.ListedAt, c.DelistedAt = old.ListedAt, old.DelistedAt
+		oldStock := strings.TrimSpace(old.StockCode)
+		if c.CorpName != old.CorpName {
+			record(c.CorpCode, models.CorpFieldName, old.CorpName, c.CorpName, changedOn)
+		}
+		if c.StockCode != oldStock {
+			record(c.CorpCode, models.CorpFieldStockCode, oldStock, c.StockCode, changedOn)
+		}
+
+		// Rows stored before statuses existed get theirs from the stock code
+		oldStatus := old.Status
+		if oldStatus == "" {
+			oldStatus = models.CorpUnlisted
+			if oldStock != "" {
+				oldStatus = models.CorpListed
+			}
+		}
+		switch {
+		case c.StockCode != "":
+			c.Status = models.CorpListed
+		case oldStatus == models.CorpUnlisted:
+			c.Status = models.CorpUnlisted
+		default:
+			c.Status = models.CorpDelisted
+		}
+		if c.Status != oldStatus {
+			record(c.CorpCode, models.CorpFieldStatus, oldStatus, c.Status, changedOn)
+			if c.Status == models.CorpListed {
+				c.ListedAt, c.DelistedAt = changedOn, ""
+			} else {
+				c.DelistedAt = changedOn
+			}
+		}
+	}
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if len(history) > 0 {
+			if err := tx.CreateInBatches(history, corpBatchSize).Error; err != nil {
+				return err
+			}
+		}
+		return tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "corp_code"}},
+			DoUpdates: clause.AssignmentColumns([]string{"corp_name", "stock_code", "modified_at", "status", "listed_at", "delisted_at"}),
+		}).CreateInBatches(corps, corpBatchSize).Error
+	})
+	if err != nil {
+		return 0, err
+	}
+	return len(history), nil
+}
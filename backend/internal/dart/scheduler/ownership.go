@@ -0,0 +1,91 @@
+package scheduler
+
+import (
+	"log"
+	"time"
+
+	"dx-unified/internal/dart/database"
+	"dx-unified/internal/dart/models"
+	"dx-unified/pkg/dart"
+
+	"gorm.io/gorm/clause"
+)
+
+const (
+	ownershipCorpsPerRun = 50
+	ownershipLookback    = 30 // days of ownership filings that trigger a fetch
+)
+
+// FetchOwnership refreshes the holdings of corps that filed ownership
+// reports (대량보유상황보고서, 임원ㆍ주요주주 소유상황보고서) since their
+// holdings were last fetched. Each corp costs two requests.
+func (j *DartJobs) FetchOwnership() {
+	since := time.Now().AddDate(0, 0, -ownershipLookback).Format("20060102")
+
+	var corpCodes []string
+	err := database.DB.Model(&models.Filing{}).
+		Distinct("filings.corp_code").
+		Joins("LEFT JOIN ownership_syncs ON ownership_syncs.corp_code = filings.corp_code").
+		Where("filings.category IN ?", []string{"large_holding", "insider_ownership"}).
+		Where("filings.rcept_dt >= ?", since).
+		Where("(ownership_syncs.fetched_at IS NULL OR filings.created_at > ownership_syncs.fetched_at)").
+		Limit(ownershipCorpsPerRun).
+		Pluck("filings.corp_code", &corpCodes).Error
+	if err != nil {
+		log.Printf("[DART] Error selecting corps for ownership: %v\n", err)
+		return
+	}
+	if len(corpCodes) == 0 {
+		return
+	}
+
+	log.Printf("[DART] Fetching ownership of %d corps...\n", len(corpCodes))
+	total := 0
+	for _, corpCode := range corpCodes {
+		n, err := j.RefreshOwnership(corpCode)
+		if dart.IsBudgetError(err) {
+			log.Printf("[DART] Stopping ownership fetch: %v\n", err)
+			break
+		}
+		if err != nil {
+			log.Printf("[DART] Error fetching ownership of %s: %v\n", corpCode, err)
+			continue
+		}
+		total += n
+	}
+	log.Printf("[DART] Saved %d holding changes\n", total)
+}
+
+// RefreshOwnership fetches the 5% holder and insider reports of a corp
+// and returns the number of holding changes saved
+func (j *DartJobs) RefreshOwnership(corpCode string) (int, error) {
+	major, err := j.ownership.GetMajorHoldings(corpCode)
+	if err != nil {
+		return 0, err
+	}
+	insider, err := j.ownership.GetInsiderHoldings(corpCode)
+	if err != nil {
+		return 0, err
+	}
+	changes := append(major, insider...)
+
+	if len(changes) > 0 {
+		err = database.DB.Clauses(clause.OnConflict{
+			Columns: []clause.Column{{Name: "rcept_no"}, {Name: "kind"}, {Name: "holder"}},
+			DoUpdates: clause.AssignmentColumns([]string{
+				"rcept_dt", "corp_name", "position", "relation",
+				"shares", "shares_change", "ratio", "ratio_change", "reason",
+			}),
+		}).CreateInBatches(&changes, 500).Error
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	sync := models.OwnershipSync{CorpCode: corpCode, Changes: len(changes), FetchedAt: time.Now()}
+	err = database.DB.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "corp_code"}},
+		DoUpdates: clause.AssignmentColumns([]string{"changes", "fetched_at"}),
+	}).Create(&sync).Error
+	return len(changes), err
+}
@@ -1,37 +1,53 @@
 package scheduler
 
 import (
-	"crypto/sha256"
-	"encoding/hex"
 	"fmt"
-	"io"
 	"log"
 	"os"
 	"path/filepath"
+	"sync/atomic"
 	"time"
 
 	"dx-unified/internal/dart/database"
+	"dx-unified/internal/dart/extract"
 	"dx-unified/internal/dart/models"
+	"dx-unified/internal/dart/storage"
+	"dx-unified/internal/dart/taxonomy"
 	"dx-unified/pkg/dart"
 
+	"gorm.io/gorm"
 	"gorm.io/gorm/clause"
 )
 
 // DartJobs contains all scheduled DART jobs
 type DartJobs struct {
-	client     *dart.Client
-	storageDir string
+	client    *dart.Client // filing lists and corp codes
+	documents *dart.Client // document downloads, paused before filings when the budget runs low
+	ownership *dart.Client // holdings reports, sharing the document downloads' share
+	budget    *dart.Budget
+	blobs     *storage.Blobs
+	tmpDir    string // downloads in progress, moved into blobs once complete
+
+	backfillRunning atomic.Bool
 }
 
-// NewDartJobs creates a new DartJobs instance
-func NewDartJobs(apiKey, storageDir string) *DartJobs {
+// NewDartJobs creates a new DartJobs instance sharing the daily request
+// budget (nil for unlimited) and storing documents in blobs
+func NewDartJobs(apiKey, storageDir string, budget *dart.Budget, blobs *storage.Blobs) *DartJobs {
+	client := dart.NewClient(apiKey)
+	client.Budget = budget
 	return &DartJobs{
-		client:     dart.NewClient(apiKey),
-		storageDir: storageDir,
+		client:    client,
+		documents: client.WithPriority(dart.PriorityDocuments),
+		ownership: client.WithPriority(dart.PriorityDocuments),
+		budget:    budget,
+		blobs:     blobs,
+		tmpDir:    filepath.Join(storageDir, "dart", "tmp"),
 	}
 }
 
-// UpdateCorpCodes fetches and updates corp codes
+// UpdateCorpCodes fetches the corp codes and updates the corps, recording
+// renames, stock code and listing status changes in corp_history
 func (j *DartJobs) UpdateCorpCodes() {
 	log.Println("[DART] Fetching corp codes...")
 	corps, err := j.client.GetCorpCode()
@@ -40,24 +56,12 @@ func (j *DartJobs) UpdateCorpCodes() {
 		return
 	}
 
-	batchSize := 100
-	for i := 0; i < len(corps); i += batchSize {
-		end := i + batchSize
-		if end > len(corps) {
-			end = len(corps)
-		}
-
-		batch := corps[i:end]
-		result := database.DB.Clauses(clause.OnConflict{
-			Columns:   []clause.Column{{Name: "corp_code"}},
-			DoUpdates: clause.AssignmentColumns([]string{"corp_name", "stock_code", "modified_at"}),
-		}).Create(&batch)
-
-		if result.Error != nil {
-			log.Printf("[DART] Batch error at index %d: %v\n", i, result.Error)
-		}
+	changes, err := syncCorps(database.DB, corps, time.Now())
+	if err != nil {
+		log.Printf("[DART] Failed to save corp codes: %v\n", err)
+		return
 	}
-	log.Printf("[DART] Successfully updated %d corp codes\n", len(corps))
+	log.Printf("[DART] Successfully updated %d corp codes (%d changes)\n", len(corps), changes)
 }
 
 // FetchFilings fetches recent filings (3-day lookback)
@@ -68,82 +72,295 @@ func (j *DartJobs) FetchFilings() {
 		log.Printf("[DART] Fetching filings for %s...", targetDate)
 
 		filings, err := j.client.GetDailyFilings(targetDate)
+		if dart.IsBudgetError(err) {
+			log.Printf("[DART] Stopping filing fetch: %v\n", err)
+			return
+		}
 		if err != nil {
 			log.Printf("[DART] Error fetching filings for %s: %v\n", targetDate, err)
 			continue
 		}
 
+		if err := saveFilings(filings); err != nil {
+			log.Printf("[DART] Error saving filings: %v\n", err)
+			continue
+		}
 		if len(filings) > 0 {
-			result := database.DB.Clauses(clause.OnConflict{
-				Columns:   []clause.Column{{Name: "rcept_no"}},
-				DoUpdates: clause.AssignmentColumns([]string{"corp_code", "corp_name", "report_nm", "rcept_dt", "flr_nm", "rm", "dcm_no"}),
-			}).Create(&filings)
-
-			if result.Error != nil {
-				log.Printf("[DART] Error saving filings: %v\n", result.Error)
-			} else {
-				log.Printf("[DART] Processed %d filings for %s\n", len(filings), targetDate)
-			}
+			log.Printf("[DART] Processed %d filings for %s\n", len(filings), targetDate)
+		}
+		recordFilingDay(targetDate, len(filings))
+	}
+}
+
+// saveFilings classifies filings, upserts them by rcept_no and links the
+// corrections among them
+func saveFilings(filings []models.Filing) error {
+	if len(filings) == 0 {
+		return nil
+	}
+	for i := range filings {
+		taxonomy.Apply(&filings[i])
+	}
+	err := database.DB.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "rcept_no"}},
+		DoUpdates: clause.AssignmentColumns([]string{
+			"corp_code", "corp_name", "report_nm", "rcept_dt", "flr_nm", "rm", "dcm_no",
+			"stock_code", "corp_cls", "pblntf_ty", "pblntf_detail_ty", "category", "is_correction", "has_attached",
+		}),
+	}).CreateInBatches(&filings, 500).Error
+	if err != nil {
+		return err
+	}
+
+	// Link corrections of these corps, both new ones and older ones whose
+	// original arrived only now, e.g. from a backfill of an earlier range
+	linkCorrections(filings)
+	return nil
+}
+
+func linkCorrections(filings []models.Filing) {
+	seen := make(map[string]bool)
+	corpCodes := []string{}
+	for _, f := range filings {
+		if !seen[f.CorpCode] {
+			seen[f.CorpCode] = true
+			corpCodes = append(corpCodes, f.CorpCode)
 		}
 	}
+	n, err := taxonomy.LinkCorrections(database.DB, corpCodes)
+	if err != nil {
+		log.Printf("[DART] Error linking corrections: %v\n", err)
+	} else if n > 0 {
+		log.Printf("[DART] Linked %d corrections to their originals\n", n)
+	}
+}
+
+// recordFilingDay marks the complete filing list of date as fetched
+func recordFilingDay(date string, count int) {
+	day := models.FilingDay{Date: date, Count: count, FetchedAt: time.Now()}
+	err := database.DB.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "date"}},
+		DoUpdates: clause.AssignmentColumns([]string{"count", "fetched_at"}),
+	}).Create(&day).Error
+	if err != nil {
+		log.Printf("[DART] Error recording filing day %s: %v\n", date, err)
+	}
 }
 
-// DownloadDocuments downloads pending documents
+const (
+	docType           = "MAIN_XML_ZIP"
+	downloadBatchSize = 10
+	enqueueBatchSize  = 500
+	extractBatchSize  = 200
+
+	// Failed downloads are retried after 5m, 10m, 20m, ... up to maxBackoff
+	// and dead-lettered after maxDownloadRetries attempts
+	baseBackoff        = 5 * time.Minute
+	maxBackoff         = 12 * time.Hour
+	maxDownloadRetries = 8
+
+	// A download still marked downloading after this was interrupted
+	staleDownload = 30 * time.Minute
+)
+
+// DownloadDocuments downloads pending documents. Each filing gets one
+// document row that moves pending -> downloading -> done, or to failed with
+// exponential backoff and finally dead after maxDownloadRetries.
 func (j *DartJobs) DownloadDocuments() {
 	log.Println("[DART] Starting Document Downloader...")
 
-	var pendingFilings []models.Filing
+	if err := enqueueDocuments(); err != nil {
+		log.Printf("[DART] Error queueing documents: %v\n", err)
+		return
+	}
+	j.recoverStaleDownloads()
 
-	err := database.DB.Raw(`
-		SELECT * FROM filings 
-		WHERE rcept_no NOT IN (SELECT rcept_no FROM filing_documents)
-		ORDER BY rcept_dt DESC
-		LIMIT 10
-	`).Scan(&pendingFilings).Error
+	if j.budget != nil && !j.budget.Available(dart.PriorityDocuments) {
+		log.Println("[DART] Document downloads paused: daily request budget reserved for filings")
+		return
+	}
 
+	var docs []models.FilingDocument
+	err := database.DB.
+		Where("state IN ?", []string{models.DocStatePending, models.DocStateFailed}).
+		Where("next_attempt_at IS NULL OR next_attempt_at <= ?", time.Now()).
+		Order("rcept_no DESC").
+		Limit(downloadBatchSize).
+		Find(&docs).Error
 	if err != nil {
 		log.Printf("[DART] Error finding pending downloads: %v\n", err)
 		return
 	}
 
-	if len(pendingFilings) == 0 {
+	if len(docs) == 0 {
 		return
 	}
 
-	if err := os.MkdirAll(j.storageDir, 0755); err != nil {
-		log.Printf("[DART] Error creating storage dir: %v\n", err)
+	if err := os.MkdirAll(j.tmpDir, 0755); err != nil {
+		log.Printf("[DART] Error creating download dir: %v\n", err)
 		return
 	}
 
-	for _, f := range pendingFilings {
-		log.Printf("[DART] Downloading document for %s (%s)\n", f.CorpName, f.RceptNo)
+	for i := range docs {
+		if err := j.download(&docs[i]); err != nil {
+			log.Printf("[DART] Stopping document downloads: %v\n", err)
+			return
+		}
+		time.Sleep(500 * time.Millisecond)
+	}
+}
 
-		filename := fmt.Sprintf("%s.zip", f.RceptNo)
-		filePath := filepath.Join(j.storageDir, filename)
+// ExtractEvents parses the downloaded dividend and earnings documents
+// into extracted events
+func (j *DartJobs) ExtractEvents() {
+	n, err := extract.Run(database.DB, j.blobs, extractBatchSize)
+	if err != nil {
+		log.Printf("[DART] Error extracting events: %v\n", err)
+		return
+	}
+	if n > 0 {
+		log.Printf("[DART] Extracted %d events\n", n)
+	}
+}
 
-		if err := j.client.DownloadDocument(f.RceptNo, filePath); err != nil {
-			log.Printf("[DART] Failed to download %s: %v\n", f.RceptNo, err)
-			continue
+// enqueueDocuments creates a pending document row for filings without one
+func enqueueDocuments() error {
+	for {
+		var rceptNos []string
+		err := database.DB.Raw(`
+			SELECT rcept_no FROM filings
+			WHERE rcept_no NOT IN (SELECT rcept_no FROM filing_documents)
+			LIMIT ?
+		`, enqueueBatchSize).Scan(&rceptNos).Error
+		if err != nil || len(rceptNos) == 0 {
+			return err
 		}
 
-		hash, err := calculateSHA256(filePath)
-		if err != nil {
-			log.Printf("[DART] Failed to calculate hash for %s: %v\n", filePath, err)
+		docs := make([]models.FilingDocument, len(rceptNos))
+		for i, rceptNo := range rceptNos {
+			docs[i] = models.FilingDocument{RceptNo: rceptNo, DocType: docType, State: models.DocStatePending}
 		}
-
-		doc := models.FilingDocument{
-			RceptNo:    f.RceptNo,
-			DocType:    "MAIN_XML_ZIP",
-			StorageURI: filePath,
-			SHA256:     hash,
+		if err := database.DB.Create(&docs).Error; err != nil {
+			return err
+		}
+		if len(rceptNos) < enqueueBatchSize {
+			return nil
 		}
+	}
+}
+
+// recoverStaleDownloads fails downloads interrupted by a restart so they
+// are retried, removing any partially written file
+func (j *DartJobs) recoverStaleDownloads() {
+	var stale []models.FilingDocument
+	database.DB.Where("state = ? AND updated_at < ?", models.DocStateDownloading, time.Now().Add(-staleDownload)).Find(&stale)
+	for i := range stale {
+		os.Remove(j.downloadPath(stale[i].RceptNo) + ".tmp")
+		j.fail(&stale[i], fmt.Errorf("download interrupted"))
+	}
+}
 
-		if err := database.DB.Create(&doc).Error; err != nil {
-			log.Printf("[DART] Failed to save DB record for %s: %v\n", f.RceptNo, err)
+func (j *DartJobs) downloadPath(rceptNo string) string {
+	return filepath.Join(j.tmpDir, fmt.Sprintf("%s.zip", rceptNo))
+}
+
+// download fetches one document. It returns an error only when the daily
+// budget ran out; the document then keeps its state without using a retry.
+func (j *DartJobs) download(doc *models.FilingDocument) error {
+	prevState := doc.State
+	claimed, err := claim(doc)
+	if err != nil {
+		log.Printf("[DART] Failed to update state for %s: %v\n", doc.RceptNo, err)
+		return nil
+	}
+	if !claimed {
+		log.Printf("[DART] Document %s is already being downloaded\n", doc.RceptNo)
+		return nil
+	}
+	log.Printf("[DART] Downloading document %s (attempt %d)\n", doc.RceptNo, doc.RetryCount+1)
+
+	filePath := j.downloadPath(doc.RceptNo)
+	if err := j.documents.DownloadDocument(doc.RceptNo, filePath); err != nil {
+		if dart.IsBudgetError(err) {
+			database.DB.Model(doc).Update("state", prevState)
+			return err
 		}
+		j.fail(doc, err)
+		return nil
+	}
+	defer os.Remove(filePath)
 
-		time.Sleep(500 * time.Millisecond)
+	hash, uri, stored, err := j.blobs.PutFile(filePath)
+	if err != nil {
+		j.fail(doc, fmt.Errorf("store document: %w", err))
+		return nil
+	}
+	if !stored {
+		log.Printf("[DART] Document %s is identical to stored blob %s\n", doc.RceptNo, hash)
 	}
+
+	err = database.DB.Model(doc).Updates(map[string]interface{}{
+		"state":           models.DocStateDone,
+		"storage_uri":     uri,
+		"sha256":          hash,
+		"fetched_at":      time.Now(),
+		"next_attempt_at": nil,
+		"last_error":      "",
+	}).Error
+	if err != nil {
+		log.Printf("[DART] Failed to save DB record for %s: %v\n", doc.RceptNo, err)
+	}
+	return nil
+}
+
+// claim moves a document from the state it was selected in to
+// downloading. It reports false when another run claimed it first, e.g. an
+// overlapping run of the same job.
+func claim(doc *models.FilingDocument) (bool, error) {
+	res := database.DB.Model(doc).Where("state = ?", doc.State).Update("state", models.DocStateDownloading)
+	return res.RowsAffected == 1, res.Error
+}
+
+// fail records a failed attempt and schedules the next one, or moves the
+// document to the dead-letter state once retries are exhausted or OpenDART
+// answered that the document does not exist
+func (j *DartJobs) fail(doc *models.FilingDocument, cause error) {
+	retries := doc.RetryCount + 1
+	updates := map[string]interface{}{
+		"retry_count": retries,
+		"last_error":  cause.Error(),
+	}
+	if dart.IsPermanentError(cause) {
+		updates["state"] = models.DocStateDead
+		updates["next_attempt_at"] = nil
+		log.Printf("[DART] Giving up on %s: %v\n", doc.RceptNo, cause)
+	} else if retries >= maxDownloadRetries {
+		updates["state"] = models.DocStateDead
+		updates["next_attempt_at"] = nil
+		log.Printf("[DART] Giving up on %s after %d attempts: %v\n", doc.RceptNo, retries, cause)
+	} else {
+		next := time.Now().Add(backoff(retries))
+		updates["state"] = models.DocStateFailed
+		updates["next_attempt_at"] = next
+		log.Printf("[DART] Failed to download %s, retrying after %s: %v\n", doc.RceptNo, next.Format("01-02 15:04"), cause)
+	}
+
+	if err := database.DB.Model(doc).Updates(updates).Error; err != nil {
+		log.Printf("[DART] Failed to save DB record for %s: %v\n", doc.RceptNo, err)
+	}
+}
+
+// backoff returns the wait before the attempt following the given number
+// of failed attempts
+func backoff(failures int) time.Duration {
+	d := baseBackoff
+	for i := 1; i < failures && d < maxBackoff; i++ {
+		d *= 2
+	}
+	if d > maxBackoff {
+		d = maxBackoff
+	}
+	return d
 }
 
 // InitialSetup runs initial corp code fetch if empty
@@ -156,19 +373,13 @@ func (j *DartJobs) InitialSetup() {
 	} else {
 		log.Printf("[DART] Corp table has %d records. Skipping initial fetch.\n", count)
 	}
-}
 
-func calculateSHA256(path string) (string, error) {
-	f, err := os.Open(path)
+	// Corps stored before listing statuses existed get theirs from the
+	// stock code until the next refresh
+	err := database.DB.Model(&models.Corp{}).
+		Where("status = '' OR status IS NULL").
+		Update("status", gorm.Expr("CASE WHEN TRIM(stock_code) <> '' THEN ? ELSE ? END", models.CorpListed, models.CorpUnlisted)).Error
 	if err != nil {
-		return "", err
+		log.Printf("[DART] Error setting corp statuses: %v\n", err)
 	}
-	defer f.Close()
-
-	h := sha256.New()
-	if _, err := io.Copy(h, f); err != nil {
-		return "", err
-	}
-
-	return hex.EncodeToString(h.Sum(nil)), nil
 }
@@ -0,0 +1,85 @@
+package scheduler
+
+import (
+	"path/filepath"
+	"testing"
+
+	"dx-unified/internal/dart/database"
+	"dx-unified/internal/dart/models"
+	"dx-unified/pkg/dart"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func openTestDB(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "dart.db")), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.AutoMigrate(&models.FilingDocument{}, &models.Filing{}, &models.FilingDay{}, &models.BackfillJob{}); err != nil {
+		t.Fatal(err)
+	}
+	prev := database.DB
+	database.DB = db
+	t.Cleanup(func() { database.DB = prev })
+}
+
+func getDocument(t *testing.T, id uint) models.FilingDocument {
+	var doc models.FilingDocument
+	if err := database.DB.First(&doc, id).Error; err != nil {
+		t.Fatal(err)
+	}
+	return doc
+}
+
+// TestClaim checks that of two runs that selected the same pending
+// document only the first downloads it
+func TestClaim(t *testing.T) {
+	openTestDB(t)
+	doc := models.FilingDocument{RceptNo: "20260302000100", State: models.DocStatePending}
+	database.DB.Create(&doc)
+
+	first, second := doc, doc
+	if ok, err := claim(&first); !ok || err != nil {
+		t.Fatalf("first claim = %v, %v", ok, err)
+	}
+	if ok, err := claim(&second); ok || err != nil {
+		t.Fatalf("second claim = %v, %v", ok, err)
+	}
+	if got := getDocument(t, doc.ID); got.State != models.DocStateDownloading {
+		t.Errorf("state = %s, want %s", got.State, models.DocStateDownloading)
+	}
+}
+
+func TestFail(t *testing.T) {
+	openTestDB(t)
+	tests := []struct {
+		name      string
+		cause     error
+		retries   int
+		wantState string
+	}{
+		{"transient", &dart.APIError{Status: "800", Message: "maintenance"}, 0, models.DocStateFailed},
+		{"no data", &dart.APIError{Status: dart.StatusNoData, Message: "no data"}, 0, models.DocStateDead},
+		{"file not found", &dart.APIError{Status: dart.StatusFileNotFound, Message: "no file"}, 0, models.DocStateDead},
+		{"out of retries", &dart.APIError{Status: "800", Message: "maintenance"}, maxDownloadRetries - 1, models.DocStateDead},
+	}
+	j := &DartJobs{}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc := models.FilingDocument{RceptNo: "20260302000100", State: models.DocStateDownloading, RetryCount: tt.retries}
+			database.DB.Create(&doc)
+			j.fail(&doc, tt.cause)
+
+			got := getDocument(t, doc.ID)
+			if got.State != tt.wantState || got.RetryCount != tt.retries+1 {
+				t.Errorf("state %s, retries %d; want %s, %d", got.State, got.RetryCount, tt.wantState, tt.retries+1)
+			}
+			if (got.NextAttemptAt != nil) != (tt.wantState == models.DocStateFailed) {
+				t.Errorf("next attempt at %v", got.NextAttemptAt)
+			}
+		})
+	}
+}
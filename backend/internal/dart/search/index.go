@@ -0,0 +1,246 @@
+package search
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"dx-unified/internal/dart/extract"
+	"dx-unified/internal/dart/models"
+	"dx-unified/internal/dart/storage"
+
+	"gorm.io/gorm"
+)
+
+// ErrUnavailable is returned by New when SQLite was built without FTS5
+var ErrUnavailable = errors.New("full-text search needs SQLite with FTS5 (build with -tags sqlite_fts5)")
+
+const (
+	filingBatchSize = 500
+	bodyBatchSize   = 200
+	// Longer documents (annual reports) are indexed up to this size
+	maxBodyBytes = 256 << 10
+)
+
+// Index is a full-text index of the DART filings in the DART database:
+// report names, corp names (including former names from corp_history) and
+// the text of the downloaded main documents. Like the news SQLite store it
+// uses the FTS5 trigram tokenizer, which works for Korean without a
+// morphological analyzer.
+type Index struct {
+	db    *gorm.DB
+	blobs *storage.Blobs // nil when documents cannot be read; only names are indexed
+}
+
+// New creates the index tables if needed
+func New(db *gorm.DB, blobs *storage.Blobs) (*Index, error) {
+	idx := &Index{db: db, blobs: blobs}
+	if err := idx.createSchema(); err != nil {
+		if strings.Contains(err.Error(), "no such module: fts5") {
+			return nil, ErrUnavailable
+		}
+		return nil, fmt.Errorf("failed to init search schema: %w", err)
+	}
+	return idx, nil
+}
+
+func (idx *Index) createSchema() error {
+	schema := []string{
+		// First, so nothing is created without FTS5
+		`CREATE VIRTUAL TABLE IF NOT EXISTS filing_texts_fts USING fts5(
+			report_nm, corp_name, body,
+			content='filing_texts', content_rowid='id',
+			tokenize='trigram'
+		)`,
+		`CREATE TABLE IF NOT EXISTS filing_texts (
+			id INTEGER PRIMARY KEY,
+			rcept_no TEXT NOT NULL UNIQUE,
+			report_nm TEXT NOT NULL DEFAULT '',
+			corp_name TEXT NOT NULL DEFAULT '',
+			body TEXT NOT NULL DEFAULT '',
+			body_indexed_at INTEGER
+		)`,
+		`CREATE TRIGGER IF NOT EXISTS filing_texts_ai AFTER INSERT ON filing_texts BEGIN
+			INSERT INTO filing_texts_fts(rowid, report_nm, corp_name, body)
+			VALUES (new.id, new.report_nm, new.corp_name, new.body);
+		END`,
+		`CREATE TRIGGER IF NOT EXISTS filing_texts_ad AFTER DELETE ON filing_texts BEGIN
+			INSERT INTO filing_texts_fts(filing_texts_fts, rowid, report_nm, corp_name, body)
+			VALUES ('delete', old.id, old.report_nm, old.corp_name, old.body);
+		END`,
+		`CREATE TRIGGER IF NOT EXISTS filing_texts_au AFTER UPDATE ON filing_texts BEGIN
+			INSERT INTO filing_texts_fts(filing_texts_fts, rowid, report_nm, corp_name, body)
+			VALUES ('delete', old.id, old.report_nm, old.corp_name, old.body);
+			INSERT INTO filing_texts_fts(rowid, report_nm, corp_name, body)
+			VALUES (new.id, new.report_nm, new.corp_name, new.body);
+		END`,
+	}
+	for _, stmt := range schema {
+		if err := idx.db.Exec(stmt).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Reset drops the index so the next runs rebuild it, e.g. after the text
+// extraction changed
+func (idx *Index) Reset() error {
+	err := idx.db.Transaction(func(tx *gorm.DB) error {
+		for _, stmt := range []string{
+			`DROP TRIGGER IF EXISTS filing_texts_ai`,
+			`DROP TRIGGER IF EXISTS filing_texts_ad`,
+			`DROP TRIGGER IF EXISTS filing_texts_au`,
+			`DROP TABLE IF EXISTS filing_texts_fts`,
+			`DROP TABLE IF EXISTS filing_texts`,
+		} {
+			if err := tx.Exec(stmt).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	return idx.createSchema()
+}
+
+// indexedNames is the corp_name column of a filing f joined with its corp
+// c: the name in the filing, the current name and the former names
+const indexedNames = `f.corp_name || COALESCE(' ' || NULLIF(c.corp_name, f.corp_name), '') ||
+	COALESCE(' ' || (SELECT group_concat(DISTINCT h.old_value) FROM corp_history h
+		WHERE h.corp_code = f.corp_code AND h.field = ?), '')`
+
+// Run indexes new filings and up to bodyBatchSize downloaded documents
+func (idx *Index) Run() {
+	filings, err := idx.IndexFilings()
+	if err != nil {
+		log.Printf("[DART] Error indexing filings: %v\n", err)
+		return
+	}
+	bodies, err := idx.IndexDocuments(bodyBatchSize)
+	if err != nil {
+		log.Printf("[DART] Error indexing documents: %v\n", err)
+		return
+	}
+	if filings > 0 || bodies > 0 {
+		log.Printf("[DART] Indexed %d filings and %d documents for search\n", filings, bodies)
+	}
+}
+
+// IndexFilings adds the report and corp names of filings not indexed yet
+// and updates the names of indexed filings that changed since
+func (idx *Index) IndexFilings() (int, error) {
+	total, err := idx.refreshNames()
+	if err != nil {
+		return total, err
+	}
+	for {
+		res := idx.db.Exec(`
+			INSERT INTO filing_texts (rcept_no, report_nm, corp_name)
+			SELECT f.rcept_no, f.report_nm, `+indexedNames+`
+			FROM filings f
+			LEFT JOIN corps c ON c.corp_code = f.corp_code
+			WHERE NOT EXISTS (SELECT 1 FROM filing_texts t WHERE t.rcept_no = f.rcept_no)
+			LIMIT ?
+		`, models.CorpFieldName, filingBatchSize)
+		if res.Error != nil {
+			return total, res.Error
+		}
+		total += int(res.RowsAffected)
+		if res.RowsAffected < filingBatchSize {
+			return total, nil
+		}
+	}
+}
+
+// refreshNames re-indexes the names of filings that were saved again with
+// another report or corp name, e.g. a re-fetched day, or whose corp was
+// renamed. The cheap tests pick the candidates; only rows whose names
+// really differ are rewritten, as that rewrites their text in the index.
+func (idx *Index) refreshNames() (int, error) {
+	res := idx.db.Exec(`
+		UPDATE filing_texts SET report_nm = f.report_nm, corp_name = `+indexedNames+`
+		FROM filings f
+		LEFT JOIN corps c ON c.corp_code = f.corp_code
+		WHERE f.rcept_no = filing_texts.rcept_no
+			AND (filing_texts.report_nm <> f.report_nm
+				OR substr(filing_texts.corp_name, 1, length(f.corp_name)) <> f.corp_name
+				OR f.corp_code IN (SELECT corp_code FROM corp_history WHERE field = ?))
+			AND (filing_texts.report_nm <> f.report_nm OR filing_texts.corp_name <> `+indexedNames+`)
+	`, models.CorpFieldName, models.CorpFieldName, models.CorpFieldName)
+	return int(res.RowsAffected), res.Error
+}
+
+// IndexDocuments adds the text of up to limit downloaded documents, newest
+// filings first. Documents that cannot be used are marked as indexed
+// without text, so they are not retried on every run. Documents whose blob
+// is gone are queued for download again, and a document store error stops
+// the run.
+func (idx *Index) IndexDocuments(limit int) (int, error) {
+	if idx.blobs == nil {
+		return 0, nil
+	}
+	var docs []struct {
+		TextID uint
+		models.FilingDocument
+	}
+	err := idx.db.Table("filing_texts").
+		Select("filing_texts.id AS text_id, filing_documents.*").
+		Joins("JOIN filing_documents ON filing_documents.rcept_no = filing_texts.rcept_no").
+		Where("filing_documents.state = ? AND filing_texts.body_indexed_at IS NULL", models.DocStateDone).
+		Order("filing_texts.rcept_no DESC").
+		Limit(limit).
+		Scan(&docs).Error
+	if err != nil {
+		return 0, err
+	}
+
+	indexed := 0
+	for _, doc := range docs {
+		body := ""
+		text, err := extract.LoadMainDocument(idx.blobs, &doc.FilingDocument)
+		switch {
+		case errors.Is(err, storage.ErrNotFound):
+			log.Printf("[DART] Document of %s is missing from the store, downloading it again\n", doc.RceptNo)
+			if err := extract.Requeue(idx.db, doc.ID); err != nil {
+				return indexed, err
+			}
+			continue
+		case errors.Is(err, extract.ErrBadDocument):
+			log.Printf("[DART] No text for %s: %v\n", doc.RceptNo, err)
+		case err != nil:
+			return indexed, fmt.Errorf("load document of %s: %w", doc.RceptNo, err)
+		default:
+			body = truncate(extract.Text(text), maxBodyBytes)
+			indexed++
+		}
+		err = idx.db.Exec(`UPDATE filing_texts SET body = ?, body_indexed_at = ? WHERE id = ?`,
+			body, time.Now().Unix(), doc.TextID).Error
+		if err != nil {
+			return indexed, err
+		}
+	}
+	return indexed, nil
+}
+
+// Stats returns the number of indexed filings and documents
+func (idx *Index) Stats() (filings, documents int64, err error) {
+	err = idx.db.Raw(`SELECT COUNT(*), COUNT(body_indexed_at) FROM filing_texts`).Row().Scan(&filings, &documents)
+	return filings, documents, err
+}
+
+// truncate cuts s to at most n bytes without splitting a UTF-8 sequence
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	return s[:n]
+}
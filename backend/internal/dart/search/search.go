@@ -0,0 +1,360 @@
+package search
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+
+	"dx-unified/internal/shared/fts"
+)
+
+// Highlight tags, the same as the news search
+const (
+	HighlightPreTag  = "<em>"
+	HighlightPostTag = "</em>"
+)
+
+// Body snippets are cropped to about this many words around the first match
+const (
+	cropWords  = 40
+	cropMarker = "…"
+	// Snippets of FTS matches come from FTS5 snippet(), in tokens, which are
+	// characters with the trigram tokenizer; 64 is its maximum
+	snippetTokens = 64
+	// Without an FTS match the snippet is cropped from the start of the text
+	prefixChars = 1000
+)
+
+// Query is a full-text search over the indexed filings
+type Query struct {
+	Text     string   // terms, all of which must match; terms under 3 characters only match names
+	CorpCode string   // optional
+	Types    []string // pblntf_ty (A-J) or pblntf_detail_ty (e.g. B001) codes; empty means all
+	DateFrom string   // YYYYMMDD, inclusive
+	DateTo   string
+	Sort     string // "date" (newest first, default) or "relevance"
+	Limit    int
+	Offset   int
+}
+
+// Event is an extracted event of a hit
+type Event struct {
+	ID         uint   `json:"id"`
+	EventType  string `json:"event_type"`
+	Superseded bool   `json:"superseded"`
+}
+
+// Highlight holds the matched fields with the query terms in highlight
+// tags; the body is cropped around the first match
+type Highlight struct {
+	ReportNm string `json:"report_nm"`
+	CorpName string `json:"corp_name"`
+	Body     string `json:"body,omitempty"`
+}
+
+// Hit is a matching filing
+type Hit struct {
+	RceptNo   string    `json:"rcept_no"`
+	RceptDt   string    `json:"rcept_dt"`
+	CorpCode  string    `json:"corp_code"`
+	CorpName  string    `json:"corp_name"`
+	StockCode string    `json:"stock_code"`
+	ReportNm  string    `json:"report_nm"`
+	PblntfTy  string    `json:"pblntf_ty"`
+	Category  string    `json:"category"`
+	HasText   bool      `json:"has_text"` // the document text is indexed
+	Highlight Highlight `json:"highlight"`
+	Events    []Event   `json:"events"`
+	FilingURL string    `json:"filing_url"` // filing detail with its events
+	DartURL   string    `json:"dart_url"`
+}
+
+type hitRow struct {
+	RceptNo      string
+	RceptDt      string
+	CorpCode     string
+	CorpName     string
+	StockCode    string
+	ReportNm     string
+	PblntfTy     string
+	Category     string
+	IndexedNames string
+	Snippet      string
+	HasText      bool
+}
+
+// Search returns the filings matching every term of q.Text and the total
+// number of matches
+func (idx *Index) Search(q Query) ([]Hit, int64, error) {
+	terms := strings.Fields(q.Text)
+	groups := make([][]string, len(terms)) // no synonyms, one alternative per term
+	for i, term := range terms {
+		groups[i] = []string{term}
+	}
+	match, short := fts.SplitTerms(groups)
+	var likes []string
+	for _, alts := range short {
+		likes = append(likes, alts...)
+	}
+
+	from := "filing_texts t JOIN filings f ON f.rcept_no = t.rcept_no"
+	var conds []string
+	var args []interface{}
+	if match != "" {
+		from += " JOIN filing_texts_fts ON filing_texts_fts.rowid = t.id"
+		conds = append(conds, "filing_texts_fts MATCH ?")
+		args = append(args, match)
+	}
+	// Trigram needs at least 3 characters; shorter terms such as "배당"
+	// fall back to LIKE, on the names only since scanning every document
+	// text would read the whole table
+	for _, term := range likes {
+		conds = append(conds, `(t.report_nm LIKE ? ESCAPE '\' OR t.corp_name LIKE ? ESCAPE '\')`)
+		pattern := "%" + fts.EscapeLike(term) + "%"
+		args = append(args, pattern, pattern)
+	}
+	if q.CorpCode != "" {
+		conds = append(conds, "f.corp_code = ?")
+		args = append(args, q.CorpCode)
+	}
+	if len(q.Types) > 0 {
+		var ors []string
+		for _, t := range q.Types {
+			if len(t) == 1 {
+				ors = append(ors, "f.pblntf_ty = ?")
+			} else {
+				ors = append(ors, "f.pblntf_detail_ty = ?")
+			}
+			args = append(args, t)
+		}
+		conds = append(conds, "("+strings.Join(ors, " OR ")+")")
+	}
+	if q.DateFrom != "" {
+		conds = append(conds, "f.rcept_dt >= ?")
+		args = append(args, q.DateFrom)
+	}
+	if q.DateTo != "" {
+		conds = append(conds, "f.rcept_dt <= ?")
+		args = append(args, q.DateTo)
+	}
+	if len(conds) == 0 {
+		return nil, 0, fmt.Errorf("empty query")
+	}
+	where := strings.Join(conds, " AND ")
+
+	var total int64
+	if err := idx.db.Raw(`SELECT COUNT(*) FROM `+from+` WHERE `+where, args...).Row().Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("count filings: %w", err)
+	}
+
+	order := "f.rcept_dt DESC, f.rcept_no DESC"
+	if q.Sort == "relevance" && match != "" {
+		// Report names weigh more than corp names, which weigh more than the text
+		order = "bm25(filing_texts_fts, 10.0, 5.0, 1.0), " + order
+	}
+	// Only the snippet of the text is read, cropped by SQLite
+	snippet := "substr(t.body, 1, ?)"
+	snippetArgs := []interface{}{prefixChars}
+	if match != "" {
+		snippet = "snippet(filing_texts_fts, 2, ?, ?, ?, ?)"
+		snippetArgs = []interface{}{HighlightPreTag, HighlightPostTag, cropMarker, snippetTokens}
+	}
+	var rows []hitRow
+	err := idx.db.Raw(`
+		SELECT f.rcept_no, f.rcept_dt, f.corp_code, f.corp_name, f.report_nm, f.pblntf_ty, f.category,
+			COALESCE(NULLIF(f.stock_code, ''), (SELECT c.stock_code FROM corps c WHERE c.corp_code = f.corp_code), '') AS stock_code,
+			t.corp_name AS indexed_names, `+snippet+` AS snippet, octet_length(t.body) > 0 AS has_text
+		FROM `+from+` WHERE `+where+` ORDER BY `+order+` LIMIT ? OFFSET ?`,
+		append(append(snippetArgs, args...), q.Limit, q.Offset)...).Scan(&rows).Error
+	if err != nil {
+		return nil, 0, fmt.Errorf("search filings: %w", err)
+	}
+
+	words := highlightWords(terms)
+	hits := make([]Hit, 0, len(rows))
+	rceptNos := make([]string, 0, len(rows))
+	for _, r := range rows {
+		h := Hit{
+			RceptNo:   r.RceptNo,
+			RceptDt:   r.RceptDt,
+			CorpCode:  r.CorpCode,
+			CorpName:  r.CorpName,
+			StockCode: r.StockCode,
+			ReportNm:  r.ReportNm,
+			PblntfTy:  r.PblntfTy,
+			Category:  r.Category,
+			HasText:   r.HasText,
+			Highlight: Highlight{
+				ReportNm: markTerms(r.ReportNm, words),
+				CorpName: markTerms(r.IndexedNames, words),
+			},
+			Events:    []Event{},
+			FilingURL: "/dart/filings/" + r.RceptNo,
+			DartURL:   "https://dart.fss.or.kr/dsaf001/main.do?rcpNo=" + r.RceptNo,
+		}
+		switch {
+		case r.Snippet == "":
+		case match != "":
+			// snippet() marks the FTS terms, the short ones are left
+			h.Highlight.Body = markOutside(r.Snippet, highlightWords(likes))
+		default:
+			h.Highlight.Body = markTerms(cropBody(r.Snippet, words), words)
+		}
+		hits = append(hits, h)
+		rceptNos = append(rceptNos, r.RceptNo)
+	}
+	if err := idx.addEvents(hits, rceptNos); err != nil {
+		return nil, 0, err
+	}
+	return hits, total, nil
+}
+
+// addEvents attaches the extracted events of the hits
+func (idx *Index) addEvents(hits []Hit, rceptNos []string) error {
+	if len(rceptNos) == 0 {
+		return nil
+	}
+	var events []struct {
+		Event
+		RceptNo string
+	}
+	err := idx.db.Table("extracted_events").
+		Select("id, rcept_no, event_type, COALESCE(superseded, false) AS superseded").
+		Where("rcept_no IN ?", rceptNos).
+		Order("id").
+		Scan(&events).Error
+	if err != nil {
+		return err
+	}
+	byRceptNo := make(map[string][]Event)
+	for _, e := range events {
+		byRceptNo[e.RceptNo] = append(byRceptNo[e.RceptNo], e.Event)
+	}
+	for i := range hits {
+		if evs, ok := byRceptNo[hits[i].RceptNo]; ok {
+			hits[i].Events = evs
+		}
+	}
+	return nil
+}
+
+// highlightWords lower-cases the terms, longest first so "삼성전자" wins
+// over "삼성" at the same position
+func highlightWords(terms []string) []string {
+	words := make([]string, 0, len(terms))
+	for _, t := range terms {
+		words = append(words, lowerRunes(t))
+	}
+	sort.Slice(words, func(i, j int) bool { return len([]rune(words[i])) > len([]rune(words[j])) })
+	return words
+}
+
+// lowerRunes lower-cases rune by rune so offsets match the original text
+func lowerRunes(s string) string {
+	runes := []rune(s)
+	for i, r := range runes {
+		runes[i] = unicode.ToLower(r)
+	}
+	return string(runes)
+}
+
+// matchAt returns the length in runes of the word matching at i, or 0
+func matchAt(lower []rune, i int, words []string) int {
+	for _, w := range words {
+		wr := []rune(w)
+		if len(wr) == 0 || i+len(wr) > len(lower) {
+			continue
+		}
+		if string(lower[i:i+len(wr)]) == w {
+			return len(wr)
+		}
+	}
+	return 0
+}
+
+func markTerms(text string, words []string) string {
+	if text == "" || len(words) == 0 {
+		return text
+	}
+	runes := []rune(text)
+	lower := []rune(lowerRunes(text))
+
+	var b strings.Builder
+	for i := 0; i < len(runes); {
+		if n := matchAt(lower, i, words); n > 0 {
+			b.WriteString(HighlightPreTag)
+			b.WriteString(string(runes[i : i+n]))
+			b.WriteString(HighlightPostTag)
+			i += n
+			continue
+		}
+		b.WriteRune(runes[i])
+		i++
+	}
+	return b.String()
+}
+
+// markOutside marks words in the parts of text not highlighted yet
+func markOutside(text string, words []string) string {
+	if len(words) == 0 {
+		return text
+	}
+	var b strings.Builder
+	for text != "" {
+		i := strings.Index(text, HighlightPreTag)
+		if i < 0 {
+			b.WriteString(markTerms(text, words))
+			break
+		}
+		b.WriteString(markTerms(text[:i], words))
+		j := strings.Index(text[i:], HighlightPostTag)
+		if j < 0 {
+			b.WriteString(text[i:])
+			break
+		}
+		j += i + len(HighlightPostTag)
+		b.WriteString(text[i:j])
+		text = text[j:]
+	}
+	return b.String()
+}
+
+// cropBody keeps cropWords words starting a little before the first word
+// containing a match, or the beginning of the text without a match
+func cropBody(body string, words []string) string {
+	fields := strings.Fields(body)
+	if len(fields) <= cropWords {
+		return strings.Join(fields, " ")
+	}
+
+	start := 0
+	for i, f := range fields {
+		lower := []rune(lowerRunes(f))
+		found := false
+		for j := range lower {
+			if matchAt(lower, j, words) > 0 {
+				found = true
+				break
+			}
+		}
+		if found {
+			start = max(i-cropWords/4, 0)
+			break
+		}
+	}
+	end := start + cropWords
+	if end > len(fields) {
+		end = len(fields)
+		start = end - cropWords
+	}
+
+	snippet := strings.Join(fields[start:end], " ")
+	if start > 0 {
+		snippet = cropMarker + snippet
+	}
+	if end < len(fields) {
+		snippet += cropMarker
+	}
+	return snippet
+}
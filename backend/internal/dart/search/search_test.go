@@ -0,0 +1,131 @@
+package search
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"dx-unified/internal/dart/models"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// openTestIndex needs FTS5: go test -tags sqlite_fts5
+func openTestIndex(t *testing.T) (*Index, *gorm.DB) {
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "dart.db")), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.AutoMigrate(&models.Corp{}, &models.CorpHistory{}, &models.Filing{}, &models.FilingDocument{}, &models.ExtractedEvent{}); err != nil {
+		t.Fatal(err)
+	}
+	idx, err := New(db, nil)
+	if errors.Is(err, ErrUnavailable) {
+		t.Skip(err)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	return idx, db
+}
+
+func search(t *testing.T, idx *Index, text string) []Hit {
+	hits, total, err := idx.Search(Query{Text: text, Limit: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if int(total) != len(hits) {
+		t.Errorf("%q: total %d, %d hits", text, total, len(hits))
+	}
+	return hits
+}
+
+func TestSearch(t *testing.T) {
+	idx, db := openTestIndex(t)
+	db.Create(&models.Corp{CorpCode: "00126380", CorpName: "삼성전자"})
+	db.Create(&[]models.Filing{
+		{RceptNo: "20260302000100", CorpCode: "00126380", CorpName: "삼성전자", RceptDt: "20260302", ReportNm: "현금ㆍ현물배당결정"},
+		{RceptNo: "20260303000100", CorpCode: "00126380", CorpName: "삼성전자", RceptDt: "20260303", ReportNm: "단일판매ㆍ공급계약체결"},
+	})
+	if n, err := idx.IndexFilings(); err != nil || n != 2 {
+		t.Fatalf("IndexFilings = %d, %v; want 2", n, err)
+	}
+	body := strings.Repeat("기타 사항 ", 100) + "반도체 공급 계약의 계약금액은 1조원이다 " + strings.Repeat("기타 사항 ", 100)
+	db.Exec(`UPDATE filing_texts SET body = ?, body_indexed_at = 1 WHERE rcept_no = ?`, body, "20260303000100")
+
+	hits := search(t, idx, "반도체 계약")
+	if len(hits) != 1 || hits[0].RceptNo != "20260303000100" || !hits[0].HasText {
+		t.Fatalf("hits = %+v", hits)
+	}
+	snippet := hits[0].Highlight.Body
+	if !strings.Contains(snippet, "<em>반도체</em>") || !strings.Contains(snippet, "<em>계약</em>") || len([]rune(snippet)) > 200 {
+		t.Errorf("snippet = %q", snippet)
+	}
+
+	// Short terms only match names
+	if hits := search(t, idx, "배당"); len(hits) != 1 || hits[0].RceptNo != "20260302000100" {
+		t.Errorf("배당 = %+v", hits)
+	}
+	if hits := search(t, idx, "사항"); len(hits) != 0 {
+		t.Errorf("사항 matched the text: %+v", hits)
+	}
+
+	// A rename is picked up by the next run, the former name still matches
+	db.Model(&models.Corp{}).Where("corp_code = ?", "00126380").Update("corp_name", "삼성일렉트로닉스")
+	db.Create(&models.CorpHistory{CorpCode: "00126380", Field: models.CorpFieldName, OldValue: "삼성전자", NewValue: "삼성일렉트로닉스"})
+	db.Model(&models.Filing{}).Where("rcept_no = ?", "20260303000100").Update("report_nm", "[기재정정]단일판매ㆍ공급계약체결")
+	if n, err := idx.IndexFilings(); err != nil || n != 2 {
+		t.Fatalf("IndexFilings after rename = %d, %v; want 2", n, err)
+	}
+	if hits := search(t, idx, "일렉트로닉스"); len(hits) != 2 {
+		t.Errorf("new name: %d hits", len(hits))
+	}
+	if hits := search(t, idx, "기재정정"); len(hits) != 1 || !hits[0].HasText {
+		t.Errorf("corrected name: %+v", hits)
+	}
+	if n, err := idx.IndexFilings(); err != nil || n != 0 {
+		t.Errorf("IndexFilings without changes = %d, %v; want 0", n, err)
+	}
+}
+
+func TestMarkTerms(t *testing.T) {
+	words := highlightWords([]string{"삼성", "삼성전자"})
+	got := markTerms("삼성전자와 삼성SDI", words)
+	want := "<em>삼성전자</em>와 <em>삼성</em>SDI"
+	if got != want {
+		t.Errorf("markTerms = %q, want %q", got, want)
+	}
+	if got := markTerms("Samsung Electronics", highlightWords([]string{"SAMSUNG"})); got != "<em>Samsung</em> Electronics" {
+		t.Errorf("markTerms case = %q", got)
+	}
+}
+
+func TestMarkOutside(t *testing.T) {
+	got := markOutside("현금<em>배당결정</em> 배당금", []string{"배당"})
+	want := "현금<em>배당결정</em> <em>배당</em>금"
+	if got != want {
+		t.Errorf("markOutside = %q, want %q", got, want)
+	}
+}
+
+func TestCropBody(t *testing.T) {
+	var fields []string
+	for i := 0; i < 100; i++ {
+		fields = append(fields, "단어")
+	}
+	fields[60] = "배당금"
+	got := cropBody(strings.Join(fields, " "), []string{"배당"})
+	if !strings.HasPrefix(got, cropMarker) || !strings.HasSuffix(got, cropMarker) || !strings.Contains(got, "배당금") {
+		t.Errorf("cropBody = %q", got)
+	}
+	if n := len(strings.Fields(strings.Trim(got, cropMarker))); n != cropWords {
+		t.Errorf("cropBody kept %d words, want %d", n, cropWords)
+	}
+
+	if got := cropBody("짧은  본문", []string{"배당"}); got != "짧은 본문" {
+		t.Errorf("cropBody short = %q", got)
+	}
+}
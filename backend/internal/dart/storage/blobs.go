@@ -0,0 +1,113 @@
+package storage
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// Blobs stores documents by the SHA-256 of their content, so identical
+// files (OpenDART serves the same zip for some re-filed documents) are
+// kept once and FilingDocument rows share them
+type Blobs struct {
+	store Store
+}
+
+// NewBlobs creates a content-addressed layer over a store
+func NewBlobs(store Store) *Blobs {
+	return &Blobs{store: store}
+}
+
+// Key returns the store key of a blob: sha256/{first two hex digits}/{hash}
+func Key(hash string) string {
+	if len(hash) < 2 {
+		return "sha256/" + hash
+	}
+	return "sha256/" + hash[:2] + "/" + hash
+}
+
+// URI returns the StorageURI of the blob with the given hash
+func (b *Blobs) URI(hash string) string {
+	return b.store.URI(Key(hash))
+}
+
+// uriPrefix is what the URIs of all blobs in the store start with
+func (b *Blobs) uriPrefix() string {
+	return strings.TrimSuffix(b.store.URI(Key("00")), "00/00")
+}
+
+// PutFile stores the content of a local file and returns its hash and
+// URI. stored is false when an identical blob was already in the store.
+func (b *Blobs) PutFile(path string) (hash, uri string, stored bool, err error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", "", false, err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	size, err := io.Copy(h, f)
+	if err != nil {
+		return "", "", false, err
+	}
+	hash = hex.EncodeToString(h.Sum(nil))
+	key := Key(hash)
+
+	existing, err := b.store.Stat(key)
+	if err == nil && existing == size {
+		return hash, b.store.URI(key), false, nil
+	}
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		return "", "", false, err
+	}
+
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return "", "", false, err
+	}
+	if err := b.store.Put(key, f, size); err != nil {
+		return "", "", false, err
+	}
+	return hash, b.store.URI(key), true, nil
+}
+
+// Open opens a document by its StorageURI and SHA-256. Blobs of the
+// configured store are read from it; file:// URIs and plain paths (files
+// downloaded before blob storage, or blobs of a previous local store) are
+// read from disk as *os.File.
+func (b *Blobs) Open(uri, hash string) (io.ReadCloser, int64, error) {
+	if hash != "" && uri == b.URI(hash) {
+		return b.store.Get(Key(hash))
+	}
+	path, ok := LocalPath(uri)
+	if !ok {
+		return nil, 0, fmt.Errorf("%s is not in the configured document store", uri)
+	}
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, 0, ErrNotFound
+	}
+	if err != nil {
+		return nil, 0, err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, 0, err
+	}
+	return f, info.Size(), nil
+}
+
+// LocalPath returns the file path of a file:// URI or a plain path
+func LocalPath(uri string) (string, bool) {
+	if path, ok := strings.CutPrefix(uri, "file://"); ok {
+		return path, true
+	}
+	if uri == "" || strings.Contains(uri, "://") {
+		return "", false
+	}
+	return uri, true
+}
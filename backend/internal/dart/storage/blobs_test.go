@@ -0,0 +1,83 @@
+package storage
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestBlobsPutFileDedup(t *testing.T) {
+	dir := t.TempDir()
+	store, err := NewLocalStore(filepath.Join(dir, "blobs"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	blobs := NewBlobs(store)
+
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	hash1, uri1, stored, err := blobs.PutFile(write("a.zip", "same content"))
+	if err != nil || !stored {
+		t.Fatalf("first PutFile: stored=%v err=%v", stored, err)
+	}
+	hash2, uri2, stored, err := blobs.PutFile(write("b.zip", "same content"))
+	if err != nil || stored {
+		t.Fatalf("identical PutFile: stored=%v err=%v, want deduplicated", stored, err)
+	}
+	if hash1 != hash2 || uri1 != uri2 {
+		t.Errorf("identical files got %s %s and %s %s", hash1, uri1, hash2, uri2)
+	}
+	if want := blobs.URI(hash1); uri1 != want {
+		t.Errorf("uri = %q, want %q", uri1, want)
+	}
+
+	hash3, _, stored, err := blobs.PutFile(write("c.zip", "other content"))
+	if err != nil || !stored || hash3 == hash1 {
+		t.Errorf("different PutFile: hash=%s stored=%v err=%v", hash3, stored, err)
+	}
+
+	r, size, err := blobs.Open(uri1, hash1)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	defer r.Close()
+	content, _ := io.ReadAll(r)
+	if string(content) != "same content" || size != int64(len(content)) {
+		t.Errorf("Open = %q (%d bytes)", content, size)
+	}
+}
+
+func TestBlobsPutFileToS3(t *testing.T) {
+	fake, srv := newFakeS3(t, "dart")
+	blobs := NewBlobs(newTestS3Store(t, srv.URL, testSecretKey))
+
+	path := filepath.Join(t.TempDir(), "doc.zip")
+	os.WriteFile(path, []byte("zip"), 0644)
+
+	hash, uri, stored, err := blobs.PutFile(path)
+	if err != nil || !stored {
+		t.Fatalf("PutFile: stored=%v err=%v", stored, err)
+	}
+	if _, _, stored, _ := blobs.PutFile(path); stored {
+		t.Errorf("second PutFile uploaded again")
+	}
+	if len(fake.objects) != 1 {
+		t.Errorf("objects = %d, want 1", len(fake.objects))
+	}
+	if uri != "s3://dart/docs/"+Key(hash) {
+		t.Errorf("uri = %q", uri)
+	}
+}
+
+func TestKey(t *testing.T) {
+	if got, want := Key("abcdef"), "sha256/ab/abcdef"; got != want {
+		t.Errorf("Key = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,113 @@
+package storage
+
+import (
+	"errors"
+	"log"
+	"os"
+	"strings"
+
+	"dx-unified/internal/dart/models"
+
+	"gorm.io/gorm"
+)
+
+const migrateBatchSize = 200
+
+// MigrationResult counts the documents handled by MigrateDocuments
+type MigrationResult struct {
+	Migrated     int   `json:"migrated"`     // copied into the store
+	Deduplicated int   `json:"deduplicated"` // identical to a blob already stored
+	Requeued     int   `json:"requeued"`     // file missing, queued for download again
+	Failed       int   `json:"failed"`
+	Remaining    int64 `json:"remaining"` // downloaded documents still outside the store
+}
+
+// notInStore selects downloaded documents whose StorageURI is not a blob
+// of the configured store
+func notInStore(db *gorm.DB, blobs *Blobs) *gorm.DB {
+	return db.Model(&models.FilingDocument{}).
+		Where("state = ?", models.DocStateDone).
+		Where("storage_uri <> '' AND storage_uri IS NOT NULL").
+		Where("storage_uri NOT LIKE ?", blobs.uriPrefix()+"%")
+}
+
+// MigrateDocuments moves up to limit (0 for all) downloaded documents from
+// their local files into the blob store. Files downloaded before blob
+// storage ({StorageDir}/{rcept_no}.zip) are removed once stored; blobs of
+// a previous local store are left in place as other documents may share
+// them. Documents whose file is gone are queued for download again.
+func MigrateDocuments(db *gorm.DB, blobs *Blobs, limit int) (MigrationResult, error) {
+	var res MigrationResult
+	var lastID uint
+	for limit <= 0 || res.Migrated+res.Deduplicated+res.Requeued+res.Failed < limit {
+		batch := migrateBatchSize
+		if limit > 0 {
+			batch = min(batch, limit-(res.Migrated+res.Deduplicated+res.Requeued+res.Failed))
+		}
+		var docs []models.FilingDocument
+		err := notInStore(db, blobs).Where("id > ?", lastID).Order("id").Limit(batch).Find(&docs).Error
+		if err != nil {
+			return res, err
+		}
+		if len(docs) == 0 {
+			break
+		}
+		for i := range docs {
+			lastID = docs[i].ID
+			migrateDocument(db, blobs, &docs[i], &res)
+		}
+	}
+
+	if err := notInStore(db, blobs).Count(&res.Remaining).Error; err != nil {
+		return res, err
+	}
+	return res, nil
+}
+
+func migrateDocument(db *gorm.DB, blobs *Blobs, doc *models.FilingDocument, res *MigrationResult) {
+	legacy := !strings.HasPrefix(doc.StorageURI, "file://")
+	path, ok := LocalPath(doc.StorageURI)
+	if !ok {
+		log.Printf("[DART] Cannot migrate %s: %s is not a local file\n", doc.RceptNo, doc.StorageURI)
+		res.Failed++
+		return
+	}
+
+	hash, uri, stored, err := blobs.PutFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		err = db.Model(doc).Updates(map[string]interface{}{
+			"state":           models.DocStatePending,
+			"storage_uri":     "",
+			"sha256":          "",
+			"retry_count":     0,
+			"next_attempt_at": nil,
+		}).Error
+		if err != nil {
+			log.Printf("[DART] Failed to requeue %s: %v\n", doc.RceptNo, err)
+			res.Failed++
+			return
+		}
+		res.Requeued++
+		return
+	}
+	if err != nil {
+		log.Printf("[DART] Failed to migrate %s: %v\n", doc.RceptNo, err)
+		res.Failed++
+		return
+	}
+
+	err = db.Model(doc).Updates(map[string]interface{}{"storage_uri": uri, "sha256": hash}).Error
+	if err != nil {
+		log.Printf("[DART] Failed to save DB record for %s: %v\n", doc.RceptNo, err)
+		res.Failed++
+		return
+	}
+	if legacy {
+		os.Remove(path)
+	}
+	if stored {
+		res.Migrated++
+	} else {
+		res.Deduplicated++
+	}
+}
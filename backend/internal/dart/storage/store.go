@@ -0,0 +1,46 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+
+	"dx-unified/internal/shared/config"
+)
+
+// ErrNotFound is returned for keys not in the store
+var ErrNotFound = errors.New("blob not found")
+
+// Store is a flat key/value blob backend. Keys are slash separated paths
+// such as sha256/ab/ab12....
+type Store interface {
+	// Put writes size bytes from r under key, replacing any existing blob
+	Put(key string, r io.Reader, size int64) error
+	// Get opens a blob for reading and returns its size
+	Get(key string) (io.ReadCloser, int64, error)
+	// Stat returns the size of a blob, or ErrNotFound
+	Stat(key string) (int64, error)
+	Delete(key string) error
+	// URI identifies a blob for FilingDocument.StorageURI, e.g.
+	// file:///storage/dart/blobs/sha256/ab/ab12... or s3://bucket/sha256/ab/ab12...
+	URI(key string) string
+}
+
+// NewStore creates the document store selected by cfg.DartDocStore
+func NewStore(cfg *config.Config) (Store, error) {
+	switch cfg.DartDocStore {
+	case "", "local":
+		return NewLocalStore(filepath.Join(cfg.StorageDir, "dart", "blobs"))
+	case "s3":
+		return NewS3Store(S3Config{
+			Endpoint:  cfg.S3Endpoint,
+			Region:    cfg.S3Region,
+			Bucket:    cfg.S3Bucket,
+			Prefix:    cfg.S3Prefix,
+			AccessKey: cfg.S3AccessKey,
+			SecretKey: cfg.S3SecretKey,
+		})
+	}
+	return nil, fmt.Errorf("unknown document store %q, expected local or s3", cfg.DartDocStore)
+}
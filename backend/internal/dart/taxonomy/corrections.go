@@ -0,0 +1,149 @@
+package taxonomy
+
+import (
+	"dx-unified/internal/dart/models"
+
+	"gorm.io/gorm"
+)
+
+// How many earlier filings of the corp are searched for the original
+const correctionLookback = 500
+
+// LinkCorrections links unlinked corrections of the given corps (all corps
+// when nil) to the filing they correct: the latest earlier filing of the
+// same corp with the same normalized report name. Corrections are linked
+// oldest first so a correction of a correction finds an already linked
+// predecessor. It returns the number of corrections linked.
+func LinkCorrections(db *gorm.DB, corpCodes []string) (int, error) {
+	query := db.Where("is_correction = ? AND (corrects_rcept_no = '' OR corrects_rcept_no IS NULL)", true)
+	if corpCodes != nil {
+		if len(corpCodes) == 0 {
+			return 0, nil
+		}
+		query = query.Where("corp_code IN ?", corpCodes)
+	}
+	var pending []models.Filing
+	if err := query.Order("rcept_no").Find(&pending).Error; err != nil {
+		return 0, err
+	}
+
+	linked := 0
+	roots := make(map[string]bool)
+	for _, c := range pending {
+		prev, err := findCorrected(db, c)
+		if err != nil {
+			return linked, err
+		}
+		if prev == nil {
+			continue // original not stored (yet), e.g. before the backfilled range
+		}
+
+		original := prev.OriginalRceptNo
+		if original == "" {
+			original = prev.RceptNo
+		}
+		err = db.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Model(&models.Filing{}).Where("rcept_no = ?", c.RceptNo).Updates(map[string]interface{}{
+				"corrects_rcept_no": prev.RceptNo,
+				"original_rcept_no": original,
+			}).Error; err != nil {
+				return err
+			}
+			// Corrections made later than c already linked to prev keep
+			// pointing at it; the successor of prev is the earliest one
+			return tx.Model(&models.Filing{}).
+				Where("rcept_no = ? AND (superseded_by = '' OR superseded_by IS NULL OR superseded_by > ?)", prev.RceptNo, c.RceptNo).
+				Update("superseded_by", c.RceptNo).Error
+		})
+		if err != nil {
+			return linked, err
+		}
+		linked++
+		roots[original] = true
+	}
+
+	for original := range roots {
+		if err := MarkSuperseded(db, original); err != nil {
+			return linked, err
+		}
+	}
+	return linked, nil
+}
+
+// findCorrected returns the filing a correction corrects, or nil
+func findCorrected(db *gorm.DB, c models.Filing) (*models.Filing, error) {
+	name := Normalize(c.ReportNm)
+
+	var earlier []models.Filing
+	err := db.Where("corp_code = ? AND rcept_no < ? AND rcept_dt <= ?", c.CorpCode, c.RceptNo, c.RceptDt).
+		Order("rcept_no DESC").
+		Limit(correctionLookback).
+		Find(&earlier).Error
+	if err != nil {
+		return nil, err
+	}
+	for i := range earlier {
+		if Normalize(earlier[i].ReportNm) == name {
+			return &earlier[i], nil
+		}
+	}
+	return nil, nil
+}
+
+// Chain returns every version of the filing's correction chain, original
+// first
+func Chain(db *gorm.DB, f models.Filing) ([]models.Filing, error) {
+	original := f.OriginalRceptNo
+	if original == "" {
+		original = f.RceptNo
+	}
+	var chain []models.Filing
+	err := db.Where("rcept_no = ? OR original_rcept_no = ?", original, original).
+		Order("rcept_no").
+		Find(&chain).Error
+	return chain, err
+}
+
+// MarkSuperseded flags the extracted events of a correction chain that a
+// later version replaced: per event type only the events of the latest
+// version having that type stay current
+func MarkSuperseded(db *gorm.DB, original string) error {
+	chain, err := Chain(db, models.Filing{RceptNo: original})
+	if err != nil || len(chain) < 2 {
+		return err
+	}
+	rceptNos := make([]string, len(chain))
+	for i, f := range chain {
+		rceptNos[i] = f.RceptNo
+	}
+
+	var events []models.ExtractedEvent
+	if err := db.Where("rcept_no IN ?", rceptNos).Find(&events).Error; err != nil {
+		return err
+	}
+	latest := make(map[string]string) // event type -> latest rcept_no
+	for _, e := range events {
+		if e.RceptNo > latest[e.EventType] {
+			latest[e.EventType] = e.RceptNo
+		}
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		for _, e := range events {
+			by := ""
+			if e.RceptNo != latest[e.EventType] {
+				by = latest[e.EventType]
+			}
+			if e.Superseded == (by != "") && e.SupersededBy == by {
+				continue
+			}
+			if err := tx.Model(&models.ExtractedEvent{}).Where("id = ?", e.ID).Updates(map[string]interface{}{
+				"superseded":    by != "",
+				"superseded_by": by,
+			}).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
@@ -0,0 +1,156 @@
+package taxonomy
+
+import (
+	"path/filepath"
+	"testing"
+
+	"dx-unified/internal/dart/models"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func openTestDB(t *testing.T) *gorm.DB {
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "dart.db")), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.AutoMigrate(&models.Filing{}, &models.ExtractedEvent{}); err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+func saveTestFilings(t *testing.T, db *gorm.DB, filings ...models.Filing) {
+	for i := range filings {
+		filings[i].CorpCode = "00126380"
+		Apply(&filings[i])
+	}
+	if err := db.Create(&filings).Error; err != nil {
+		t.Fatal(err)
+	}
+}
+
+func getFiling(t *testing.T, db *gorm.DB, rceptNo string) models.Filing {
+	var f models.Filing
+	if err := db.First(&f, "rcept_no = ?", rceptNo).Error; err != nil {
+		t.Fatal(err)
+	}
+	return f
+}
+
+func TestLinkCorrections(t *testing.T) {
+	db := openTestDB(t)
+	saveTestFilings(t, db,
+		models.Filing{RceptNo: "20260302000100", RceptDt: "20260302", ReportNm: "현금ㆍ현물배당결정"},
+		models.Filing{RceptNo: "20260302000200", RceptDt: "20260302", ReportNm: "단일판매ㆍ공급계약체결"},
+		models.Filing{RceptNo: "20260303000100", RceptDt: "20260303", ReportNm: "[기재정정]현금ㆍ현물배당결정"},
+		models.Filing{RceptNo: "20260305000100", RceptDt: "20260305", ReportNm: "[기재정정] 현금·현물배당결정"},
+	)
+
+	n, err := LinkCorrections(db, []string{"00126380"})
+	if err != nil || n != 2 {
+		t.Fatalf("LinkCorrections = %d, %v; want 2", n, err)
+	}
+
+	tests := []struct {
+		rceptNo      string
+		corrects     string
+		original     string
+		supersededBy string
+	}{
+		{"20260302000100", "", "", "20260303000100"},
+		{"20260302000200", "", "", ""},
+		{"20260303000100", "20260302000100", "20260302000100", "20260305000100"},
+		{"20260305000100", "20260303000100", "20260302000100", ""},
+	}
+	for _, tt := range tests {
+		f := getFiling(t, db, tt.rceptNo)
+		if f.CorrectsRceptNo != tt.corrects || f.OriginalRceptNo != tt.original || f.SupersededBy != tt.supersededBy {
+			t.Errorf("%s: corrects %q, original %q, superseded by %q; want %q, %q, %q", tt.rceptNo,
+				f.CorrectsRceptNo, f.OriginalRceptNo, f.SupersededBy, tt.corrects, tt.original, tt.supersededBy)
+		}
+	}
+
+	chain, err := Chain(db, getFiling(t, db, "20260303000100"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, f := range chain {
+		got = append(got, f.RceptNo)
+	}
+	if len(got) != 3 || got[0] != "20260302000100" || got[2] != "20260305000100" {
+		t.Errorf("Chain = %v", got)
+	}
+
+	// Linked corrections are not linked again
+	if n, err := LinkCorrections(db, nil); err != nil || n != 0 {
+		t.Errorf("second LinkCorrections = %d, %v; want 0", n, err)
+	}
+}
+
+// TestLinkCorrectionsOriginalLater links a correction whose original
+// arrives only in a later batch, e.g. from a backfill
+func TestLinkCorrectionsOriginalLater(t *testing.T) {
+	db := openTestDB(t)
+	saveTestFilings(t, db, models.Filing{RceptNo: "20260310000100", RceptDt: "20260310", ReportNm: "[기재정정]주요사항보고서(유상증자결정)"})
+	if n, err := LinkCorrections(db, nil); err != nil || n != 0 {
+		t.Fatalf("LinkCorrections without original = %d, %v; want 0", n, err)
+	}
+
+	saveTestFilings(t, db,
+		models.Filing{RceptNo: "20260309000100", RceptDt: "20260309", ReportNm: "주요사항보고서(유상증자결정)"},
+		// Same name but filed after the correction
+		models.Filing{RceptNo: "20260311000100", RceptDt: "20260311", ReportNm: "주요사항보고서(유상증자결정)"},
+	)
+	if n, err := LinkCorrections(db, nil); err != nil || n != 1 {
+		t.Fatalf("LinkCorrections = %d, %v; want 1", n, err)
+	}
+	if f := getFiling(t, db, "20260310000100"); f.CorrectsRceptNo != "20260309000100" {
+		t.Errorf("corrects %q, want 20260309000100", f.CorrectsRceptNo)
+	}
+	if f := getFiling(t, db, "20260311000100"); f.SupersededBy != "" {
+		t.Errorf("later filing superseded by %q", f.SupersededBy)
+	}
+}
+
+func TestMarkSuperseded(t *testing.T) {
+	db := openTestDB(t)
+	saveTestFilings(t, db,
+		models.Filing{RceptNo: "20260302000100", RceptDt: "20260302", ReportNm: "현금ㆍ현물배당결정"},
+		models.Filing{RceptNo: "20260303000100", RceptDt: "20260303", ReportNm: "[기재정정]현금ㆍ현물배당결정"},
+		models.Filing{RceptNo: "20260304000100", RceptDt: "20260304", ReportNm: "[첨부정정]현금ㆍ현물배당결정"},
+	)
+	events := []models.ExtractedEvent{
+		{RceptNo: "20260302000100", EventType: "dividend"},
+		{RceptNo: "20260302000100", EventType: "record_date"},
+		{RceptNo: "20260303000100", EventType: "dividend"},
+		// The attachment correction has no events of its own
+	}
+	if err := db.Create(&events).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := LinkCorrections(db, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	var stored []models.ExtractedEvent
+	db.Order("id").Find(&stored)
+	want := []struct {
+		superseded bool
+		by         string
+	}{
+		{true, "20260303000100"},
+		{false, ""}, // no later version has a record date event
+		{false, ""},
+	}
+	for i, e := range stored {
+		if e.Superseded != want[i].superseded || e.SupersededBy != want[i].by {
+			t.Errorf("event %d (%s %s): superseded %v by %q, want %v by %q", i, e.RceptNo, e.EventType,
+				e.Superseded, e.SupersededBy, want[i].superseded, want[i].by)
+		}
+	}
+}
@@ -0,0 +1,232 @@
+package taxonomy
+
+import (
+	"regexp"
+	"strings"
+
+	"dx-unified/internal/dart/models"
+
+	"gorm.io/gorm"
+)
+
+// FilingTypes are the pblntf_ty codes of OpenDART
+var FilingTypes = map[string]string{
+	"A": "정기공시",
+	"B": "주요사항보고",
+	"C": "발행공시",
+	"D": "지분공시",
+	"E": "기타공시",
+	"F": "외부감사관련",
+	"G": "펀드공시",
+	"H": "자산유동화",
+	"I": "거래소공시",
+	"J": "공정위공시",
+}
+
+// CorpClasses maps corp_cls to the market
+var CorpClasses = map[string]string{
+	"Y": "KOSPI",
+	"K": "KOSDAQ",
+	"N": "KONEX",
+	"E": "ETC",
+}
+
+// Category is one entry of the filing taxonomy. Pattern is matched
+// against the report name without bracket prefixes and spaces.
+type Category struct {
+	Key        string `json:"key"`
+	Label      string `json:"label"`
+	Type       string `json:"type"`        // pblntf_ty
+	DetailType string `json:"detail_type"` // pblntf_detail_ty
+	pattern    *regexp.Regexp
+}
+
+func category(key, label, detailType, pattern string) Category {
+	return Category{
+		Key:        key,
+		Label:      label,
+		Type:       detailType[:1],
+		DetailType: detailType,
+		pattern:    regexp.MustCompile(pattern),
+	}
+}
+
+// Categories are checked in order; the first match wins, so specific
+// entries come before general ones
+var Categories = []Category{
+	// 정기공시
+	category("annual_report", "사업보고서", "A001", `^사업보고서`),
+	category("semiannual_report", "반기보고서", "A002", `^반기보고서`),
+	category("quarterly_report", "분기보고서", "A003", `^분기보고서`),
+
+	// 실적/배당
+	category("earnings", "영업(잠정)실적", "I002", `영업\(잠정\)실적|잠정실적`),
+	category("earnings_change", "매출액또는손익구조변동", "I001", `매출액또는손익구조`),
+	category("dividend", "배당결정", "I001", `배당결정|배당기준일`),
+
+	// 주요사항보고
+	category("rights_offering", "유상증자결정", "B001", `유상증자결정|유무상증자결정`),
+	category("bonus_issue", "무상증자결정", "B001", `무상증자결정`),
+	category("capital_reduction", "감자결정", "B001", `감자결정`),
+	category("convertible_bond", "전환사채발행결정", "B001", `전환사채권발행결정`),
+	category("bond_with_warrant", "신주인수권부사채발행결정", "B001", `신주인수권부사채권발행결정`),
+	category("exchangeable_bond", "교환사채발행결정", "B001", `교환사채권발행결정`),
+	category("treasury_stock_result", "자기주식취득/처분결과", "E001", `자기주식(취득|처분)결과보고서`),
+	category("treasury_stock", "자기주식취득/처분결정", "B001", `자기주식(취득|처분)|자기주식취득신탁계약`),
+	category("split", "분할", "B001", `분할결정|분할합병|회사분할`),
+	category("merger", "합병", "B001", `합병`),
+	category("asset_transfer", "영업/자산양수도", "B001", `(영업|유형자산|자산)양(수|도)`),
+	category("equity_investment", "타법인주식취득/처분", "B001", `타법인주식및출자증권(취득|처분)`),
+	category("major_event", "주요사항보고서", "B001", `^주요사항보고서`),
+
+	// 거래소 수시공시
+	category("supply_contract", "단일판매ㆍ공급계약", "I001", `단일판매.?공급계약`),
+	category("facility_investment", "신규시설투자", "I001", `신규시설투자`),
+	category("major_shareholder_change", "최대주주변경", "I001", `최대주주(등)?(의)?변경|최대주주등소유주식변동`),
+	category("lawsuit", "소송", "I001", `소송`),
+	category("shareholder_meeting", "주주총회", "I001", `주주총회`),
+	category("inquiry", "조회공시/해명", "I001", `조회공시|해명`),
+	category("delisting", "상장폐지/관리종목", "I003", `상장폐지|관리종목|상장적격성`),
+	category("trading_halt", "매매거래정지", "I003", `매매거래정지|거래정지`),
+	category("investor_relations", "기업설명회(IR)", "I002", `기업설명회|IR개최`),
+
+	// 지분공시
+	category("large_holding", "주식등의대량보유상황보고서", "D001", `대량보유상황보고서`),
+	category("insider_ownership", "임원ㆍ주요주주 소유상황보고서", "D002", `특정증권등소유상황보고서`),
+	category("proxy_solicitation", "의결권대리행사권유", "D003", `의결권대리행사`),
+	category("tender_offer", "공개매수", "D004", `공개매수`),
+
+	// 발행공시
+	category("securities_registration", "증권신고서", "C001", `증권신고서|투자설명서|증권발행실적보고서`),
+
+	// 외부감사
+	category("audit_report", "감사보고서", "F001", `감사보고서`),
+
+	// 공정위공시
+	category("fair_trade", "공정위공시", "J001", `대규모내부거래|기업집단현황|비상장회사중요사항`),
+}
+
+// Correction kinds given as a bracket prefix of the report name
+const (
+	CorrectionContent    = "기재정정"
+	CorrectionAttachment = "첨부정정"
+	AttachmentAdded      = "첨부추가"
+)
+
+var prefixPattern = regexp.MustCompile(`^\s*\[([^\]]+)\]\s*`)
+
+// Result is the classification of one report name
+type Result struct {
+	Category     string // taxonomy key, "other" when nothing matched
+	Type         string // pblntf_ty, empty when unknown
+	DetailType   string
+	Prefixes     []string // bracket prefixes, e.g. [기재정정]
+	IsCorrection bool     // 기재정정 or 첨부정정
+	HasAttached  bool     // 첨부정정 or 첨부추가
+	BaseName     string   // report name without bracket prefixes
+}
+
+// BaseName strips the bracket prefixes of a report name
+func BaseName(reportNm string) (string, []string) {
+	var prefixes []string
+	name := reportNm
+	for {
+		m := prefixPattern.FindStringSubmatch(name)
+		if m == nil {
+			break
+		}
+		prefixes = append(prefixes, m[1])
+		name = name[len(m[0]):]
+	}
+	return strings.TrimSpace(name), prefixes
+}
+
+// Normalize makes report names comparable: no bracket prefixes, spaces
+// or middle dot variants
+func Normalize(reportNm string) string {
+	name, _ := BaseName(reportNm)
+	return strings.NewReplacer(" ", "", "\u00a0", "", "·", "ㆍ", "・", "ㆍ").Replace(name)
+}
+
+// Classify maps a report name to the taxonomy
+func Classify(reportNm string) Result {
+	base, prefixes := BaseName(reportNm)
+	r := Result{Category: "other", BaseName: base, Prefixes: prefixes}
+	for _, p := range prefixes {
+		switch p {
+		case CorrectionContent:
+			r.IsCorrection = true
+		case CorrectionAttachment:
+			r.IsCorrection = true
+			r.HasAttached = true
+		case AttachmentAdded:
+			r.HasAttached = true
+		}
+	}
+
+	name := Normalize(base)
+	for _, c := range Categories {
+		if c.pattern.MatchString(name) {
+			r.Category, r.Type, r.DetailType = c.Key, c.Type, c.DetailType
+			break
+		}
+	}
+	return r
+}
+
+// Apply sets the taxonomy fields of a filing. list.json does not return
+// pblntf_ty or pblntf_detail_ty, so both are derived from the category and
+// stay empty for filings classified as "other".
+func Apply(f *models.Filing) {
+	r := Classify(f.ReportNm)
+	f.Category = r.Category
+	f.PblntfTy = r.Type
+	f.PblntfDetailTy = r.DetailType
+	f.IsCorrection = r.IsCorrection
+	f.HasAttached = r.HasAttached
+}
+
+// Reclassify applies the current taxonomy to stored filings, all of them
+// or only those never classified, and returns the number updated
+func Reclassify(db *gorm.DB, all bool) (int, error) {
+	const batchSize = 1000
+
+	updated := 0
+	lastRceptNo := ""
+	for {
+		var filings []models.Filing
+		query := db.Select("rcept_no, report_nm").Where("rcept_no > ?", lastRceptNo)
+		if !all {
+			query = query.Where("category = '' OR category IS NULL")
+		}
+		if err := query.Order("rcept_no").Limit(batchSize).Find(&filings).Error; err != nil {
+			return updated, err
+		}
+		if len(filings) == 0 {
+			return updated, nil
+		}
+
+		err := db.Transaction(func(tx *gorm.DB) error {
+			for i := range filings {
+				f := &filings[i]
+				Apply(f)
+				err := tx.Model(&models.Filing{}).Where("rcept_no = ?", f.RceptNo).Updates(map[string]interface{}{
+					"category":         f.Category,
+					"pblntf_ty":        f.PblntfTy,
+					"pblntf_detail_ty": f.PblntfDetailTy,
+					"is_correction":    f.IsCorrection,
+					"has_attached":     f.HasAttached,
+				}).Error
+				if err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return updated, err
+		}
+		updated += len(filings)
+		lastRceptNo = filings[len(filings)-1].RceptNo
+	}
+}
@@ -0,0 +1,90 @@
+package taxonomy
+
+import (
+	"reflect"
+	"testing"
+
+	"dx-unified/internal/dart/models"
+)
+
+func TestClassify(t *testing.T) {
+	tests := []struct {
+		reportNm     string
+		category     string
+		detailType   string
+		isCorrection bool
+		hasAttached  bool
+	}{
+		{"사업보고서 (2025.12)", "annual_report", "A001", false, false},
+		{"반기보고서 (2026.06)", "semiannual_report", "A002", false, false},
+		{"연결재무제표기준영업(잠정)실적(공정공시)", "earnings", "I002", false, false},
+		{"현금ㆍ현물배당결정", "dividend", "I001", false, false},
+		{"주요사항보고서(유상증자결정)", "rights_offering", "B001", false, false},
+		{"주요사항보고서(무상증자결정)", "bonus_issue", "B001", false, false},
+		{"주요사항보고서(전환사채권발행결정)", "convertible_bond", "B001", false, false},
+		{"주요사항보고서(자기주식취득결정)", "treasury_stock", "B001", false, false},
+		{"자기주식취득결과보고서", "treasury_stock_result", "E001", false, false},
+		{"주요사항보고서(회사합병결정)", "merger", "B001", false, false},
+		{"단일판매ㆍ공급계약체결", "supply_contract", "I001", false, false},
+		{"단일판매·공급계약체결", "supply_contract", "I001", false, false},
+		{"단일 판매ㆍ공급 계약 체결", "supply_contract", "I001", false, false},
+		{"최대주주변경", "major_shareholder_change", "I001", false, false},
+		{"주식등의대량보유상황보고서(일반)", "large_holding", "D001", false, false},
+		{"임원ㆍ주요주주특정증권등소유상황보고서", "insider_ownership", "D002", false, false},
+		{"감사보고서제출", "audit_report", "F001", false, false},
+		{"[기재정정]주요사항보고서(유상증자결정)", "rights_offering", "B001", true, false},
+		{"[첨부정정]사업보고서 (2025.12)", "annual_report", "A001", true, true},
+		{"[첨부추가]증권신고서(지분증권)", "securities_registration", "C001", false, true},
+		{"[기재정정][첨부추가]반기보고서 (2026.06)", "semiannual_report", "A002", true, true},
+		{"투자판단관련주요경영사항", "other", "", false, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.reportNm, func(t *testing.T) {
+			r := Classify(tt.reportNm)
+			if r.Category != tt.category || r.DetailType != tt.detailType {
+				t.Errorf("Classify = %s %s, want %s %s", r.Category, r.DetailType, tt.category, tt.detailType)
+			}
+			wantType := ""
+			if tt.detailType != "" {
+				wantType = tt.detailType[:1]
+			}
+			if r.Type != wantType {
+				t.Errorf("Type = %q, want %q", r.Type, wantType)
+			}
+			if r.IsCorrection != tt.isCorrection || r.HasAttached != tt.hasAttached {
+				t.Errorf("IsCorrection, HasAttached = %v, %v; want %v, %v", r.IsCorrection, r.HasAttached, tt.isCorrection, tt.hasAttached)
+			}
+		})
+	}
+}
+
+func TestBaseName(t *testing.T) {
+	name, prefixes := BaseName(" [기재정정] [첨부추가] 주요사항보고서(유상증자결정)")
+	if name != "주요사항보고서(유상증자결정)" || !reflect.DeepEqual(prefixes, []string{"기재정정", "첨부추가"}) {
+		t.Errorf("BaseName = %q, %q", name, prefixes)
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	a := Normalize("[기재정정]단일판매·공급계약 체결")
+	b := Normalize("단일판매ㆍ공급계약체결")
+	if a != b {
+		t.Errorf("Normalize: %q != %q", a, b)
+	}
+}
+
+// TestApplyDerivesTypes checks that both type fields come from the report
+// name, whatever a filing carried before
+func TestApplyDerivesTypes(t *testing.T) {
+	f := models.Filing{ReportNm: "[기재정정]현금ㆍ현물배당결정", PblntfTy: "B", PblntfDetailTy: "B001"}
+	Apply(&f)
+	if f.Category != "dividend" || f.PblntfTy != "I" || f.PblntfDetailTy != "I001" || !f.IsCorrection {
+		t.Errorf("Apply = %+v", f)
+	}
+
+	f = models.Filing{ReportNm: "투자판단관련주요경영사항", PblntfTy: "I"}
+	Apply(&f)
+	if f.Category != "other" || f.PblntfTy != "" || f.PblntfDetailTy != "" {
+		t.Errorf("Apply other = %+v", f)
+	}
+}
@@ -4,6 +4,8 @@ import (
 	"database/sql"
 	"fmt"
 	"log"
+	"math"
+	"sort"
 	"strings"
 	"time"
 
@@ -496,6 +498,105 @@ func (r *Repository) GetHistoryDates(limit int) ([]string, error) {
 	return dates, nil
 }
 
+// ThemePerformance 스냅샷 기준 테마 등락 집계
+type ThemePerformance struct {
+	ThemeIdx      int     `json:"theme_idx"`
+	Name          string  `json:"name"`
+	AvgChangeRate float64 `json:"avg_change_rate"`
+	StockCount    int     `json:"stock_count"`
+	Advancers     int     `json:"advancers"`
+	Decliners     int     `json:"decliners"`
+	LeaderCode    string  `json:"leader_code"` // 최대 상승 종목 (평균이 하락이면 최대 하락 종목)
+	LeaderName    string  `json:"leader_name"`
+	LeaderRate    float64 `json:"leader_rate"`
+
+	top, bottom themeLeader
+}
+
+type themeLeader struct {
+	code, name string
+	rate       float64
+}
+
+// GetLatestHistoryDate onOrBefore 이전(포함) 가장 최근 히스토리 날짜 조회, 없으면 빈 문자열
+func (r *Repository) GetLatestHistoryDate(onOrBefore string) (string, error) {
+	var date sql.NullString
+	err := r.db.QueryRow(`SELECT MAX(crawl_date) FROM stock_history WHERE crawl_date <= ?`, onOrBefore).Scan(&date)
+	return date.String, err
+}
+
+// GetThemePerformance 히스토리 스냅샷의 종목 등락률로 테마별 평균 등락률 집계.
+// minStocks 미만 종목으로 이루어진 테마는 제외하며 평균 등락률 내림차순으로 반환
+func (r *Repository) GetThemePerformance(crawlDate string, minStocks int) ([]ThemePerformance, error) {
+	query := `
+		SELECT t.theme_idx, t.name, h.code, h.name, h.change_rate
+		FROM themes t
+		JOIN theme_stocks ts ON t.theme_idx = ts.theme_idx
+		JOIN stock_history h ON h.code = ts.stock_code AND h.crawl_date = ?
+		WHERE h.change_rate IS NOT NULL
+		ORDER BY t.theme_idx
+	`
+	rows, err := r.db.Query(query, crawlDate)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	byTheme := make(map[int]*ThemePerformance)
+	var order []int
+	for rows.Next() {
+		var themeIdx int
+		var themeName, code, name string
+		var rate float64
+		if err := rows.Scan(&themeIdx, &themeName, &code, &name, &rate); err != nil {
+			log.Printf("Error scanning theme performance: %v", err)
+			continue
+		}
+		tp := byTheme[themeIdx]
+		if tp == nil {
+			tp = &ThemePerformance{ThemeIdx: themeIdx, Name: themeName}
+			byTheme[themeIdx] = tp
+			order = append(order, themeIdx)
+		}
+		tp.StockCount++
+		tp.AvgChangeRate += rate
+		switch {
+		case rate > 0:
+			tp.Advancers++
+		case rate < 0:
+			tp.Decliners++
+		}
+		if tp.StockCount == 1 || rate > tp.top.rate {
+			tp.top = themeLeader{code, name, rate}
+		}
+		if tp.StockCount == 1 || rate < tp.bottom.rate {
+			tp.bottom = themeLeader{code, name, rate}
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	var result []ThemePerformance
+	for _, idx := range order {
+		tp := byTheme[idx]
+		if tp.StockCount < minStocks {
+			continue
+		}
+		tp.AvgChangeRate = math.Round(tp.AvgChangeRate/float64(tp.StockCount)*100) / 100
+		leader := tp.top
+		if tp.AvgChangeRate < 0 {
+			leader = tp.bottom
+		}
+		tp.LeaderCode, tp.LeaderName, tp.LeaderRate = leader.code, leader.name, leader.rate
+		result = append(result, *tp)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].AvgChangeRate > result[j].AvgChangeRate
+	})
+	return result, nil
+}
+
 // ===== Crawl Log Operations =====
 
 // SaveCrawlLog 크롤링 로그 저장
@@ -0,0 +1,378 @@
+package analysis
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"dx-unified/internal/candle/model"
+	"dx-unified/internal/news/store"
+)
+
+// Horizon is a measurement window after an article. Intraday horizons are
+// wall-clock durations clipped at the session close; day horizons count
+// trading sessions, with 1d ending at the close of the event session.
+type Horizon struct {
+	Label    string
+	Duration time.Duration // intraday horizons
+	Sessions int           // day horizons
+}
+
+// DefaultHorizons are used when the caller does not ask for specific ones
+var DefaultHorizons = []string{"5m", "30m", "1d", "5d"}
+
+// ParseHorizons parses labels like "5m", "2h" and "1d"
+func ParseHorizons(labels []string) ([]Horizon, error) {
+	var out []Horizon
+	for _, label := range labels {
+		label = strings.TrimSpace(strings.ToLower(label))
+		if len(label) < 2 {
+			return nil, fmt.Errorf("invalid horizon %q", label)
+		}
+		n, err := strconv.Atoi(label[:len(label)-1])
+		if err != nil || n <= 0 {
+			return nil, fmt.Errorf("invalid horizon %q", label)
+		}
+		switch label[len(label)-1] {
+		case 'm':
+			out = append(out, Horizon{Label: label, Duration: time.Duration(n) * time.Minute})
+		case 'h':
+			out = append(out, Horizon{Label: label, Duration: time.Duration(n) * time.Hour})
+		case 'd':
+			out = append(out, Horizon{Label: label, Sessions: n})
+		default:
+			return nil, fmt.Errorf("invalid horizon %q", label)
+		}
+	}
+	return out, nil
+}
+
+// MaxSessions returns the longest day horizon, at least 1
+func MaxSessions(horizons []Horizon) int {
+	max := 1
+	for _, h := range horizons {
+		if h.Sessions > max {
+			max = h.Sessions
+		}
+	}
+	return max
+}
+
+// MarketHours is the regular session of a market in its local time zone
+type MarketHours struct {
+	Location    *time.Location
+	OpenMinute  int // minutes after local midnight
+	CloseMinute int
+}
+
+// Hours returns the regular session for KR or US
+func Hours(market string) MarketHours {
+	if market == model.MarketUS {
+		return MarketHours{Location: loadLocation("America/New_York", -5), OpenMinute: 9*60 + 30, CloseMinute: 16 * 60}
+	}
+	return MarketHours{Location: loadLocation("Asia/Seoul", 9), OpenMinute: 9 * 60, CloseMinute: 15*60 + 30}
+}
+
+func loadLocation(name string, fallbackHours int) *time.Location {
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return time.FixedZone(name, fallbackHours*60*60)
+	}
+	return loc
+}
+
+func (m MarketHours) at(day string, minute int) time.Time {
+	d, _ := time.ParseInLocation("2006-01-02", day, m.Location)
+	return d.Add(time.Duration(minute) * time.Minute)
+}
+
+func (m MarketHours) inSession(ts int64) bool {
+	local := time.Unix(ts, 0).In(m.Location)
+	minute := local.Hour()*60 + local.Minute()
+	return minute >= m.OpenMinute && minute <= m.CloseMinute
+}
+
+// Return is the reaction over one horizon
+type Return struct {
+	Return float64 `json:"return"`
+	// Return minus the benchmark return; nil without a benchmark bar
+	// between the event and the end of the horizon
+	Abnormal *float64 `json:"abnormal,omitempty"`
+}
+
+// Event is one article with its measured reactions
+type Event struct {
+	ArticleID   string            `json:"article_id"`
+	Title       string            `json:"title"`
+	Source      string            `json:"source"`
+	Sentiment   string            `json:"sentiment"`
+	StoryID     string            `json:"story_id,omitempty"`
+	PublishedAt time.Time         `json:"published_at"`
+	EffectiveAt time.Time         `json:"effective_at"` // next session open for articles outside market hours
+	OffHours    bool              `json:"off_hours"`
+	Returns     map[string]Return `json:"returns"`
+}
+
+// Stats aggregates events over one horizon. The abnormal statistics cover
+// the NAbnormal events with benchmark prices and are nil without any.
+type Stats struct {
+	N              int      `json:"n"`
+	MeanReturn     float64  `json:"mean_return"`
+	NAbnormal      int      `json:"n_abnormal"`
+	MeanAbnormal   *float64 `json:"mean_abnormal,omitempty"`
+	MedianAbnormal *float64 `json:"median_abnormal,omitempty"`
+	PositiveRatio  *float64 `json:"positive_ratio,omitempty"` // share of NAbnormal events with a positive abnormal return
+}
+
+// Result is the output of an event study
+type Result struct {
+	Events   []Event                     `json:"events"`
+	Skipped  int                         `json:"skipped"` // articles without usable prices
+	Overall  map[string]Stats            `json:"overall"`
+	BySource map[string]map[string]Stats `json:"by_source"`
+	BySent   map[string]map[string]Stats `json:"by_sentiment"`
+	ByStory  map[string]map[string]Stats `json:"by_story"`
+}
+
+// Study measures the price reaction of symbol (market-adjusted by bench) to
+// each article. Both series must be ascending, of the same timeframe, and
+// should cover the articles plus the longest horizon. With daily bars only
+// the day horizons are measured.
+func Study(articles []store.ArticleDoc, symbol, bench []model.Candle, hours MarketHours, horizons []Horizon) *Result {
+	sessions := sessionDays(symbol, hours)
+
+	res := &Result{}
+	for _, a := range articles {
+		ev, ok := measure(a, symbol, bench, sessions, hours, horizons)
+		if !ok {
+			res.Skipped++
+			continue
+		}
+		res.Events = append(res.Events, ev)
+	}
+
+	res.Overall = aggregate(res.Events, horizons, func(Event) string { return "all" })["all"]
+	res.BySource = aggregate(res.Events, horizons, func(e Event) string { return e.Source })
+	res.BySent = aggregate(res.Events, horizons, func(e Event) string { return e.Sentiment })
+	res.ByStory = aggregate(res.Events, horizons, func(e Event) string {
+		if e.StoryID == "" {
+			return "none"
+		}
+		return e.StoryID
+	})
+	return res
+}
+
+func measure(a store.ArticleDoc, symbol, bench []model.Candle, sessions []string, hours MarketHours, horizons []Horizon) (Event, bool) {
+	effective, eventDay, offHours, ok := effectiveStart(a.PublishedAt, sessions, hours)
+	if !ok {
+		return Event{}, false
+	}
+
+	// The base is the last trade before the article was public, so off-hours
+	// news is measured from the previous close. A daily bar already contains
+	// the reaction of its session, so with daily bars the base is the close
+	// of the session before the event session, whose bar counts as after.
+	baseAt, after := a.PublishedAt.Unix(), effective
+	daily := isDaily(symbol)
+	if daily {
+		baseAt = hours.at(eventDay, 0).Unix()
+		after = baseAt
+	}
+	base, ok := priceBefore(symbol, baseAt)
+	if !ok {
+		return Event{}, false
+	}
+	benchBase, hasBench := priceBefore(bench, baseAt)
+
+	dayIdx := sort.SearchStrings(sessions, eventDay)
+	sessionClose := hours.at(eventDay, hours.CloseMinute).Unix() + 60
+
+	ev := Event{
+		ArticleID:   a.ID,
+		Title:       a.Title,
+		Source:      a.Source,
+		Sentiment:   sentimentOf(a),
+		StoryID:     a.StoryID,
+		PublishedAt: a.PublishedAt,
+		EffectiveAt: time.Unix(effective, 0).In(hours.Location),
+		OffHours:    offHours,
+		Returns:     make(map[string]Return),
+	}
+
+	for _, h := range horizons {
+		var target int64
+		if h.Sessions > 0 {
+			if dayIdx+h.Sessions-1 >= len(sessions) {
+				continue
+			}
+			target = hours.at(sessions[dayIdx+h.Sessions-1], hours.CloseMinute).Unix() + 60
+		} else if daily {
+			continue
+		} else {
+			target = effective + int64(h.Duration.Seconds())
+			if target > sessionClose {
+				target = sessionClose
+			}
+		}
+
+		// Require at least one bar after the event, otherwise the data has a gap
+		idx := lastBefore(symbol, target)
+		if idx < 0 || symbol[idx].TS < after {
+			continue
+		}
+
+		// A benchmark bar older than the event would adjust by a stale
+		// price, so the abnormal return is left out instead
+		r := Return{Return: symbol[idx].Close/base - 1}
+		if hasBench {
+			if b := lastBefore(bench, target); b >= 0 && bench[b].TS >= after && bench[b].Close > 0 {
+				abnormal := r.Return - (bench[b].Close/benchBase - 1)
+				r.Abnormal = &abnormal
+			}
+		}
+		ev.Returns[h.Label] = r
+	}
+
+	if len(ev.Returns) == 0 {
+		return Event{}, false
+	}
+	return ev, true
+}
+
+// effectiveStart returns when the market could first react to an article:
+// the publication time during a session, otherwise the next session open
+func effectiveStart(published time.Time, sessions []string, hours MarketHours) (int64, string, bool, bool) {
+	local := published.In(hours.Location)
+	day := local.Format("2006-01-02")
+	minute := local.Hour()*60 + local.Minute()
+
+	i := sort.SearchStrings(sessions, day)
+	if i < len(sessions) && sessions[i] == day {
+		if minute < hours.OpenMinute {
+			return hours.at(day, hours.OpenMinute).Unix(), day, true, true
+		}
+		if minute < hours.CloseMinute {
+			return published.Unix(), day, false, true
+		}
+		i++
+	}
+	if i >= len(sessions) {
+		return 0, "", false, false
+	}
+	return hours.at(sessions[i], hours.OpenMinute).Unix(), sessions[i], true, true
+}
+
+// sessionDays lists the local dates with regular-session bars. Using the
+// data itself as the calendar takes care of holidays. Daily bars are stamped
+// at local midnight, so each of them is a session.
+func sessionDays(series []model.Candle, hours MarketHours) []string {
+	daily := isDaily(series)
+	seen := make(map[string]bool)
+	var days []string
+	for _, c := range series {
+		if !daily && !hours.inSession(c.TS) {
+			continue
+		}
+		day := time.Unix(c.TS, 0).In(hours.Location).Format("2006-01-02")
+		if !seen[day] {
+			seen[day] = true
+			days = append(days, day)
+		}
+	}
+	sort.Strings(days)
+	return days
+}
+
+func isDaily(series []model.Candle) bool {
+	return len(series) > 0 && series[0].Timeframe == "1d"
+}
+
+// lastBefore returns the index of the last bar opening before ts, or -1
+func lastBefore(series []model.Candle, ts int64) int {
+	return sort.Search(len(series), func(i int) bool { return series[i].TS >= ts }) - 1
+}
+
+func priceBefore(series []model.Candle, ts int64) (float64, bool) {
+	idx := lastBefore(series, ts)
+	if idx < 0 || series[idx].Close <= 0 {
+		return 0, false
+	}
+	return series[idx].Close, true
+}
+
+// sentimentOf returns the article's sentiment bucket, falling back to a
+// "sentiment:<bucket>" tag
+func sentimentOf(a store.ArticleDoc) string {
+	if a.Sentiment != "" {
+		return a.Sentiment
+	}
+	for _, t := range a.Tags {
+		if bucket, ok := strings.CutPrefix(t, "sentiment:"); ok && bucket != "" {
+			return bucket
+		}
+	}
+	return "unknown"
+}
+
+func aggregate(events []Event, horizons []Horizon, key func(Event) string) map[string]map[string]Stats {
+	groups := make(map[string][]Event)
+	for _, e := range events {
+		k := key(e)
+		groups[k] = append(groups[k], e)
+	}
+
+	out := make(map[string]map[string]Stats)
+	for k, evs := range groups {
+		byHorizon := make(map[string]Stats)
+		for _, h := range horizons {
+			var rets, abns []float64
+			for _, e := range evs {
+				if r, ok := e.Returns[h.Label]; ok {
+					rets = append(rets, r.Return)
+					if r.Abnormal != nil {
+						abns = append(abns, *r.Abnormal)
+					}
+				}
+			}
+			if len(rets) == 0 {
+				continue
+			}
+			st := Stats{N: len(rets), MeanReturn: mean(rets), NAbnormal: len(abns)}
+			if len(abns) > 0 {
+				positive := 0
+				for _, v := range abns {
+					if v > 0 {
+						positive++
+					}
+				}
+				meanAbn, medianAbn := mean(abns), median(abns)
+				ratio := float64(positive) / float64(len(abns))
+				st.MeanAbnormal, st.MedianAbnormal, st.PositiveRatio = &meanAbn, &medianAbn, &ratio
+			}
+			byHorizon[h.Label] = st
+		}
+		out[k] = byHorizon
+	}
+	return out
+}
+
+func mean(vs []float64) float64 {
+	sum := 0.0
+	for _, v := range vs {
+		sum += v
+	}
+	return sum / float64(len(vs))
+}
+
+func median(vs []float64) float64 {
+	s := append([]float64(nil), vs...)
+	sort.Float64s(s)
+	n := len(s)
+	if n%2 == 1 {
+		return s[n/2]
+	}
+	return (s[n/2-1] + s[n/2]) / 2
+}
@@ -0,0 +1,105 @@
+package analysis
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"dx-unified/internal/candle/model"
+	"dx-unified/internal/news/store"
+)
+
+func TestStudyDailyBars(t *testing.T) {
+	hours := Hours(model.MarketKR)
+	day := func(d string) time.Time {
+		ts, _ := time.ParseInLocation("2006-01-02", d, hours.Location)
+		return ts
+	}
+	bar := func(d string, close float64) model.Candle {
+		return model.Candle{Timeframe: "1d", TS: day(d).Unix(), Close: close}
+	}
+	symbol := []model.Candle{
+		bar("2026-03-02", 100),
+		bar("2026-03-03", 110),
+		bar("2026-03-04", 99),
+		bar("2026-03-05", 120),
+	}
+	horizons, _ := ParseHorizons([]string{"30m", "1d", "2d"})
+
+	tests := []struct {
+		name      string
+		published time.Time
+		want1d    float64
+		want2d    float64
+	}{
+		{"in session", day("2026-03-03").Add(10 * time.Hour), 110.0/100 - 1, 99.0/100 - 1},
+		{"before open", day("2026-03-03").Add(7 * time.Hour), 110.0/100 - 1, 99.0/100 - 1},
+		// Measured from the next session, based on the close of the day
+		{"after close", day("2026-03-03").Add(18 * time.Hour), 99.0/110 - 1, 120.0/110 - 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res := Study([]store.ArticleDoc{{ID: "a", PublishedAt: tt.published}}, symbol, nil, hours, horizons)
+			if len(res.Events) != 1 {
+				t.Fatalf("got %d events, %d skipped", len(res.Events), res.Skipped)
+			}
+			ev := res.Events[0]
+			if _, ok := ev.Returns["30m"]; ok {
+				t.Errorf("intraday horizon measured on daily bars")
+			}
+			if got := ev.Returns["1d"].Return; math.Abs(got-tt.want1d) > 1e-9 {
+				t.Errorf("1d return = %v, want %v", got, tt.want1d)
+			}
+			if got := ev.Returns["2d"].Return; math.Abs(got-tt.want2d) > 1e-9 {
+				t.Errorf("2d return = %v, want %v", got, tt.want2d)
+			}
+		})
+	}
+}
+
+func TestSessionDaysDaily(t *testing.T) {
+	hours := Hours(model.MarketUS)
+	midnight, _ := time.ParseInLocation("2006-01-02", "2026-07-01", hours.Location)
+	days := sessionDays([]model.Candle{{Timeframe: "1d", TS: midnight.Unix()}}, hours)
+	if len(days) != 1 || days[0] != "2026-07-01" {
+		t.Fatalf("sessionDays = %v", days)
+	}
+}
+
+func TestStudyAbnormal(t *testing.T) {
+	hours := Hours(model.MarketKR)
+	day := func(d string) time.Time {
+		ts, _ := time.ParseInLocation("2006-01-02", d, hours.Location)
+		return ts
+	}
+	bar := func(d string, close float64) model.Candle {
+		return model.Candle{Timeframe: "1d", TS: day(d).Unix(), Close: close}
+	}
+	symbol := []model.Candle{bar("2026-03-02", 100), bar("2026-03-03", 110), bar("2026-03-04", 121)}
+	// The benchmark has no bar after 2026-03-03
+	bench := []model.Candle{bar("2026-03-02", 200), bar("2026-03-03", 210)}
+	horizons, _ := ParseHorizons([]string{"1d"})
+
+	articles := []store.ArticleDoc{
+		{ID: "a", Source: "naver", PublishedAt: day("2026-03-03").Add(10 * time.Hour)},
+		{ID: "b", Source: "naver", PublishedAt: day("2026-03-04").Add(10 * time.Hour)},
+	}
+	res := Study(articles, symbol, bench, hours, horizons)
+	if len(res.Events) != 2 {
+		t.Fatalf("got %d events, %d skipped", len(res.Events), res.Skipped)
+	}
+
+	a := res.Events[0].Returns["1d"]
+	if a.Abnormal == nil || math.Abs(*a.Abnormal-(0.10-0.05)) > 1e-9 {
+		t.Errorf("abnormal with benchmark = %v", a.Abnormal)
+	}
+	// Adjusting by the 2026-03-03 benchmark close would be stale
+	if b := res.Events[1].Returns["1d"]; b.Abnormal != nil {
+		t.Errorf("abnormal with stale benchmark = %v", *b.Abnormal)
+	}
+
+	st := res.Overall["1d"]
+	if st.N != 2 || st.NAbnormal != 1 || st.MeanAbnormal == nil || math.Abs(*st.MeanAbnormal-0.05) > 1e-9 {
+		t.Errorf("stats = %+v", st)
+	}
+}
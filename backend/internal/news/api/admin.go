@@ -0,0 +1,234 @@
+package api
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"dx-unified/internal/news/pipeline"
+	"dx-unified/internal/news/store"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SearchAdminHandler manages the search configuration of the news store
+type SearchAdminHandler struct {
+	store     store.NewsStore
+	processor *pipeline.Processor
+}
+
+// NewSearchAdminHandler creates a new search admin handler. The processor
+// provides the raw archive for migrations that rebuild from it.
+func NewSearchAdminHandler(newsStore store.NewsStore, processor *pipeline.Processor) *SearchAdminHandler {
+	return &SearchAdminHandler{store: newsStore, processor: processor}
+}
+
+// RegisterRoutes registers routes under /admin/search
+func (h *SearchAdminHandler) RegisterRoutes(rg *gin.RouterGroup) {
+	searchGroup := rg.Group("/admin/search")
+	{
+		searchGroup.GET("/synonyms", h.GetSynonyms)
+		searchGroup.PUT("/synonyms", h.ReplaceSynonyms)
+		searchGroup.POST("/synonyms", h.AddSynonyms)
+
+		searchGroup.GET("/migrations", h.GetMigrations)
+		searchGroup.POST("/migrations", h.StartMigration)
+		searchGroup.POST("/migrations/:id/rollback", h.RollbackMigration)
+	}
+}
+
+func (h *SearchAdminHandler) synonymStore(c *gin.Context) (store.SynonymStore, bool) {
+	ss, ok := h.store.(store.SynonymStore)
+	if !ok {
+		c.JSON(http.StatusNotImplemented, gin.H{"error": "news store does not support synonyms"})
+	}
+	return ss, ok
+}
+
+// GetSynonyms returns the configured synonyms
+func (h *SearchAdminHandler) GetSynonyms(c *gin.Context) {
+	ss, ok := h.synonymStore(c)
+	if !ok {
+		return
+	}
+	synonyms, err := ss.GetSynonyms()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"count": len(synonyms), "synonyms": synonyms})
+}
+
+// ReplaceSynonyms replaces the whole synonym set with the body, a map of
+// term -> terms it also matches. An empty map removes all synonyms.
+func (h *SearchAdminHandler) ReplaceSynonyms(c *gin.Context) {
+	ss, ok := h.synonymStore(c)
+	if !ok {
+		return
+	}
+	var synonyms map[string][]string
+	if err := c.ShouldBindJSON(&synonyms); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := ss.UpdateSynonyms(synonyms); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"count": len(synonyms), "synonyms": synonyms})
+}
+
+// AddSynonyms merges a group of equivalent terms, {"terms": ["삼전", "삼성전자"]},
+// into the current synonyms
+func (h *SearchAdminHandler) AddSynonyms(c *gin.Context) {
+	ss, ok := h.synonymStore(c)
+	if !ok {
+		return
+	}
+	var req struct {
+		Terms []string `json:"terms"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	var terms []string
+	for _, t := range req.Terms {
+		if t = strings.TrimSpace(t); t != "" && !slices.Contains(terms, t) {
+			terms = append(terms, t)
+		}
+	}
+	if len(terms) < 2 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "terms needs at least two distinct terms"})
+		return
+	}
+
+	synonyms, err := ss.GetSynonyms()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	for term, alts := range store.MutualSynonyms(terms) {
+		for _, alt := range alts {
+			if !slices.Contains(synonyms[term], alt) {
+				synonyms[term] = append(synonyms[term], alt)
+			}
+		}
+	}
+	if err := ss.UpdateSynonyms(synonyms); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"count": len(synonyms), "synonyms": synonyms})
+}
+
+func (h *SearchAdminHandler) migrator(c *gin.Context) (store.IndexMigrator, bool) {
+	m, ok := h.store.(store.IndexMigrator)
+	if !ok {
+		c.JSON(http.StatusNotImplemented, gin.H{"error": "news store does not support index migrations"})
+	}
+	return m, ok
+}
+
+// GetMigrations returns the index versions and the migrations with their
+// progress
+func (h *SearchAdminHandler) GetMigrations(c *gin.Context) {
+	m, ok := h.migrator(c)
+	if !ok {
+		return
+	}
+	status, err := m.MigrationStatus()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, status)
+}
+
+// MigrationRequest selects the documents of a migration
+type MigrationRequest struct {
+	Source string `json:"source"` // index (default) or archive
+	From   string `json:"from"`   // archive date range, YYYYMMDD or YYYY-MM-DD (KST)
+	To     string `json:"to"`
+}
+
+// StartMigration migrates the index to the latest version in the background.
+// Progress is visible through GetMigrations.
+func (h *SearchAdminHandler) StartMigration(c *gin.Context) {
+	m, ok := h.migrator(c)
+	if !ok {
+		return
+	}
+	var req MigrationRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	opts := store.MigrationOptions{Source: req.Source}
+	if opts.Source == "" {
+		opts.Source = store.MigrationSourceIndex
+	}
+	if opts.Source == store.MigrationSourceArchive {
+		if h.processor == nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "archive is not available"})
+			return
+		}
+		if req.From == "" || req.To == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "from and to are required for the archive source"})
+			return
+		}
+		loc, _ := time.LoadLocation(defaultTimeZone)
+		from, err := parseFilterTime(req.From, loc, false)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid from: " + err.Error()})
+			return
+		}
+		to, err := parseFilterTime(req.To, loc, true)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid to: " + err.Error()})
+			return
+		}
+		if from.After(to) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "from is after to"})
+			return
+		}
+		opts.Documents = h.processor.ArchiveDocuments(from, to)
+	}
+
+	migration, err := m.StartMigration(opts)
+	switch {
+	case errors.Is(err, store.ErrMigrationRunning), errors.Is(err, store.ErrIndexUpToDate):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusAccepted, migration)
+}
+
+// RollbackMigration swaps the previous index version back in. Only the latest
+// completed migration can be rolled back.
+func (h *SearchAdminHandler) RollbackMigration(c *gin.Context) {
+	m, ok := h.migrator(c)
+	if !ok {
+		return
+	}
+	migration, err := m.RollbackMigration(c.Param("id"))
+	switch {
+	case errors.Is(err, store.ErrNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "migration not found"})
+		return
+	case errors.Is(err, store.ErrMigrationRunning), errors.Is(err, store.ErrCannotRollback):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, migration)
+}
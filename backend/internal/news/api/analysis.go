@@ -0,0 +1,199 @@
+package api
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	candleDB "dx-unified/internal/candle/database"
+	"dx-unified/internal/candle/model"
+	"dx-unified/internal/news/analysis"
+	"dx-unified/internal/news/store"
+	"dx-unified/internal/shared/config"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	eventStudyPageSize    = 500
+	eventStudyMaxArticles = 2000
+	eventStudyDefaultDays = 30
+)
+
+// AnalysisHandler serves news analytics that join articles with candles
+type AnalysisHandler struct {
+	cfg   *config.Config
+	store store.NewsStore
+}
+
+// NewAnalysisHandler creates a new news analysis handler
+func NewAnalysisHandler(cfg *config.Config, newsStore store.NewsStore) *AnalysisHandler {
+	return &AnalysisHandler{cfg: cfg, store: newsStore}
+}
+
+// RegisterRoutes registers analysis routes under /news/analysis
+func (h *AnalysisHandler) RegisterRoutes(rg *gin.RouterGroup) {
+	analysisGroup := rg.Group("/news/analysis")
+	{
+		analysisGroup.GET("/event-study", h.EventStudy)
+	}
+}
+
+// EventStudy measures price reactions after article publication.
+//
+//	symbol     ticker whose candles are used (required)
+//	market     KR (default) or US
+//	benchmark  benchmark symbol, "none" for raw returns only (default by exchange)
+//	horizon    repeated or comma-separated, e.g. 5m,30m,1d,5d
+//	timeframe  1m or 1d; by default minute bars, or daily bars when the
+//	           symbol has none (intraday horizons are then skipped)
+//
+// Articles are selected with the common filters; without tag or q they are
+// the articles tagged with the symbol. The date range defaults to 30 days.
+func (h *AnalysisHandler) EventStudy(c *gin.Context) {
+	symbol := strings.TrimSpace(c.Query("symbol"))
+	if symbol == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "symbol is required"})
+		return
+	}
+	market := strings.ToUpper(c.DefaultQuery("market", model.MarketKR))
+	if market != model.MarketKR && market != model.MarketUS {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "market must be KR or US"})
+		return
+	}
+
+	labels := queryList(c, "horizon")
+	if len(labels) == 0 {
+		labels = analysis.DefaultHorizons
+	}
+	timeframe := c.Query("timeframe")
+	if timeframe != "" && timeframe != "1m" && timeframe != "1d" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "timeframe must be 1m or 1d"})
+		return
+	}
+	horizons, err := analysis.ParseHorizons(labels)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	filter, err := parseArticleFilter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	query := c.Query("q")
+	if len(filter.Tags) == 0 && query == "" {
+		filter.Tags = []string{symbol}
+	}
+	if filter.PublishedTo.IsZero() {
+		filter.PublishedTo = time.Now()
+	}
+	if filter.PublishedFrom.IsZero() {
+		filter.PublishedFrom = filter.PublishedTo.AddDate(0, 0, -eventStudyDefaultDays)
+	}
+
+	if candleDB.DB == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "candle database is not available"})
+		return
+	}
+
+	articles, err := h.loadArticles(query, filter)
+	if err != nil {
+		searchFailed(c, err)
+		return
+	}
+
+	// Prices from a week before the first article (the base price of
+	// weekend news) until the longest day horizon has passed
+	tsFrom := filter.PublishedFrom.AddDate(0, 0, -7).Unix()
+	tsTo := filter.PublishedTo.AddDate(0, 0, 2*analysis.MaxSessions(horizons)+7).Unix()
+
+	var series []model.Candle
+	for _, tf := range []string{"1m", "1d"} {
+		if timeframe != "" && tf != timeframe {
+			continue
+		}
+		series, err = candleDB.QueryCandleSeries(market, symbol, tf, tsFrom, tsTo)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		if len(series) > 0 {
+			timeframe = tf
+			break
+		}
+	}
+	if len(series) == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "no candles for " + symbol})
+		return
+	}
+
+	benchmark := c.Query("benchmark")
+	if benchmark == "" {
+		benchmark = h.defaultBenchmark(market, symbol)
+	}
+	var benchSeries []model.Candle
+	if benchmark != "none" && benchmark != "" && benchmark != symbol {
+		benchSeries, err = candleDB.QueryCandleSeries(market, benchmark, timeframe, tsFrom, tsTo)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+	}
+	if len(benchSeries) == 0 {
+		benchmark = ""
+	}
+
+	result := analysis.Study(articles, series, benchSeries, analysis.Hours(market), horizons)
+
+	c.JSON(http.StatusOK, gin.H{
+		"symbol":       symbol,
+		"market":       market,
+		"benchmark":    benchmark,
+		"horizons":     labels,
+		"timeframe":    timeframe,
+		"date_from":    filter.PublishedFrom,
+		"date_to":      filter.PublishedTo,
+		"articles":     len(articles),
+		"skipped":      result.Skipped,
+		"overall":      result.Overall,
+		"by_source":    result.BySource,
+		"by_sentiment": result.BySent,
+		"by_story":     result.ByStory,
+		"events":       result.Events,
+	})
+}
+
+func (h *AnalysisHandler) loadArticles(query string, filter store.Filter) ([]store.ArticleDoc, error) {
+	var articles []store.ArticleDoc
+	for offset := 0; offset < eventStudyMaxArticles; offset += eventStudyPageSize {
+		result, err := h.store.SearchArticles(&store.SearchQuery{
+			Query:  query,
+			Filter: filter,
+			Sort:   []string{"published_at:asc"},
+			Limit:  eventStudyPageSize,
+			Offset: offset,
+		})
+		if err != nil {
+			return nil, err
+		}
+		articles = append(articles, result.Hits...)
+		if len(result.Hits) < eventStudyPageSize {
+			break
+		}
+	}
+	return articles, nil
+}
+
+// defaultBenchmark picks the index proxy for the symbol's exchange
+func (h *AnalysisHandler) defaultBenchmark(market, symbol string) string {
+	if market == model.MarketUS {
+		return h.cfg.EventStudyBenchmarks["US"]
+	}
+	exchange, _ := candleDB.GetInstrumentExchange(market, symbol)
+	if strings.Contains(strings.ToUpper(exchange), "KOSDAQ") {
+		return h.cfg.EventStudyBenchmarks["KOSDAQ"]
+	}
+	return h.cfg.EventStudyBenchmarks["KOSPI"]
+}
@@ -0,0 +1,97 @@
+package api
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"dx-unified/internal/news/store"
+
+	"github.com/gin-gonic/gin"
+)
+
+// defaultTimeZone applies to dates and datetimes given without an offset
+const defaultTimeZone = "Asia/Seoul"
+
+// parseArticleFilter reads the common article filter query parameters:
+//
+//	source, publisher, tag   repeated or comma-separated lists
+//	sentiment                repeated or comma-separated (positive, negative, neutral)
+//	dup_state, story_id      exact match
+//	date_from, date_to       YYYYMMDD, YYYY-MM-DD, YYYY-MM-DDTHH:MM[:SS] or RFC3339
+//	tz                       IANA zone for values without an offset (default Asia/Seoul)
+func parseArticleFilter(c *gin.Context) (store.Filter, error) {
+	f := store.Filter{
+		Sources:    queryList(c, "source"),
+		Publishers: queryList(c, "publisher"),
+		Tags:       queryList(c, "tag"),
+		DupState:   c.Query("dup_state"),
+		StoryID:    c.Query("story_id"),
+		Sentiments: queryList(c, "sentiment"),
+	}
+
+	loc, err := time.LoadLocation(c.DefaultQuery("tz", defaultTimeZone))
+	if err != nil {
+		return f, fmt.Errorf("invalid tz: %s", c.Query("tz"))
+	}
+
+	if v := c.Query("date_from"); v != "" {
+		if f.PublishedFrom, err = parseFilterTime(v, loc, false); err != nil {
+			return f, fmt.Errorf("invalid date_from: %w", err)
+		}
+	}
+	if v := c.Query("date_to"); v != "" {
+		if f.PublishedTo, err = parseFilterTime(v, loc, true); err != nil {
+			return f, fmt.Errorf("invalid date_to: %w", err)
+		}
+	}
+	if !f.PublishedFrom.IsZero() && !f.PublishedTo.IsZero() && f.PublishedFrom.After(f.PublishedTo) {
+		return f, fmt.Errorf("date_from is after date_to")
+	}
+
+	return f, nil
+}
+
+// queryList accepts both ?tag=a&tag=b and ?tag=a,b
+func queryList(c *gin.Context, key string) []string {
+	var out []string
+	for _, v := range c.QueryArray(key) {
+		for _, part := range strings.Split(v, ",") {
+			if part = strings.TrimSpace(part); part != "" {
+				out = append(out, part)
+			}
+		}
+	}
+	return out
+}
+
+var dateOnlyLayouts = []string{"20060102", "2006-01-02"}
+
+var dateTimeLayouts = []string{
+	"2006-01-02T15:04:05",
+	"2006-01-02T15:04",
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+}
+
+// parseFilterTime parses a filter bound. A bare date expands to the start of
+// the day, or to its last second when endOfDay is set, so date_to is inclusive.
+func parseFilterTime(v string, loc *time.Location, endOfDay bool) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, v); err == nil {
+		return t, nil
+	}
+	for _, layout := range dateTimeLayouts {
+		if t, err := time.ParseInLocation(layout, v, loc); err == nil {
+			return t, nil
+		}
+	}
+	for _, layout := range dateOnlyLayouts {
+		if t, err := time.ParseInLocation(layout, v, loc); err == nil {
+			if endOfDay {
+				t = t.AddDate(0, 0, 1).Add(-time.Second)
+			}
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("unsupported format %q", v)
+}
@@ -1,23 +1,30 @@
 package api
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"net/http"
+	"slices"
 	"strconv"
+	"strings"
+	"time"
 
-	"dx-unified/internal/news/store/meili"
+	"dx-unified/internal/news/pipeline"
+	"dx-unified/internal/news/store"
 
 	"github.com/gin-gonic/gin"
-	"github.com/meilisearch/meilisearch-go"
 )
 
 // Handler holds dependencies for News API handlers
 type Handler struct {
-	store *meili.Store
+	store     store.NewsStore
+	processor *pipeline.Processor
 }
 
 // NewHandler creates a new News API handler
-func NewHandler(store *meili.Store) *Handler {
-	return &Handler{store: store}
+func NewHandler(newsStore store.NewsStore, processor *pipeline.Processor) *Handler {
+	return &Handler{store: newsStore, processor: processor}
 }
 
 // RegisterRoutes registers all News API routes under /news prefix
@@ -31,6 +38,9 @@ func (h *Handler) RegisterRoutes(rg *gin.RouterGroup) {
 
 		// Migration
 		news.POST("/migration", h.IngestArticles)
+		news.POST("/reprocess", h.Reprocess)
+		news.GET("/reprocess", h.GetReprocessJobs)
+		news.GET("/reprocess/:id", h.GetReprocessJob)
 	}
 }
 
@@ -38,56 +48,31 @@ func (h *Handler) RegisterRoutes(rg *gin.RouterGroup) {
 func (h *Handler) GetArticles(c *gin.Context) {
 	limitStr := c.DefaultQuery("limit", "20")
 	offsetStr := c.DefaultQuery("offset", "0")
-	source := c.Query("source")
 	keyword := c.Query("keyword")
-	dateTo := c.Query("date_to") // Expecting YYYYMMDD or ISO
 
 	limit, _ := strconv.Atoi(limitStr)
 	offset, _ := strconv.Atoi(offsetStr)
 
-	filter := []string{}
-	if source != "" {
-		filter = append(filter, "source = \""+source+"\"")
-	}
-	if dateTo != "" {
-		// For date filtering to work properly, Meilisearch needs numeric timestamps.
-		// Since published_at is stored as ISO string, string comparison may not work reliably.
-		// For now, skip datetime-based filters (containing 'T') and only filter by date.
-		// A proper fix would convert published_at to Unix timestamps at storage time.
-		if len(dateTo) == 8 {
-			// YYYYMMDD format
-			dateTo = dateTo[:4] + "-" + dateTo[4:6] + "-" + dateTo[6:]
-			filter = append(filter, "published_at <= \""+dateTo+"T23:59:59Z\"")
-		} else if len(dateTo) == 10 {
-			// YYYY-MM-DD format
-			filter = append(filter, "published_at <= \""+dateTo+"T23:59:59Z\"")
-		}
-		// Datetime with time (contains T) is skipped for now since comparison is unreliable
+	filter, err := parseArticleFilter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
 	}
 
-	searchReq := &meilisearch.SearchRequest{
-		Limit:  int64(limit),
-		Offset: int64(offset),
+	result, err := h.store.SearchArticles(&store.SearchQuery{
+		Query:  keyword,
+		Filter: filter,
 		Sort:   []string{"published_at:desc"},
-	}
-
-	if len(filter) > 0 {
-		searchReq.Filter = filter
-	}
-
-	query := ""
-	if keyword != "" {
-		query = keyword
-	}
-
-	result, err := h.store.Client.Index(meili.IndexArticles).Search(query, searchReq)
+		Limit:  limit,
+		Offset: offset,
+	})
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		searchFailed(c, err)
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{
-		"total":    result.EstimatedTotalHits,
+		"total":    result.Total,
 		"count":    len(result.Hits),
 		"offset":   offset,
 		"articles": result.Hits,
@@ -98,58 +83,85 @@ func (h *Handler) GetArticles(c *gin.Context) {
 func (h *Handler) GetArticle(c *gin.Context) {
 	id := c.Param("id")
 
-	var article map[string]interface{}
-	err := h.store.Client.Index(meili.IndexArticles).GetDocument(id, nil, &article)
+	article, err := h.store.GetArticle(id)
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Article not found"})
+		if errors.Is(err, store.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Article not found"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
 		return
 	}
 
 	c.JSON(http.StatusOK, article)
 }
 
-// SearchArticles performs full-text search on articles
+// SearchArticles performs full-text search on articles.
+//
+//	facets     repeated or comma-separated fields to count, see store.FacetFields
+//	highlight  true to return highlighted title/summary and a body snippet
 func (h *Handler) SearchArticles(c *gin.Context) {
 	query := c.Query("q")
 	limitStr := c.DefaultQuery("limit", "20")
-	dateTo := c.Query("date_to")
+	offsetStr := c.DefaultQuery("offset", "0")
 
 	limit, _ := strconv.Atoi(limitStr)
+	offset, _ := strconv.Atoi(offsetStr)
 
-	filter := []string{}
-	if dateTo != "" {
-		// Same logic as GetArticles - only filter by date, not datetime
-		if len(dateTo) == 8 {
-			dateTo = dateTo[:4] + "-" + dateTo[4:6] + "-" + dateTo[6:]
-			filter = append(filter, "published_at <= \""+dateTo+"T23:59:59Z\"")
-		} else if len(dateTo) == 10 {
-			filter = append(filter, "published_at <= \""+dateTo+"T23:59:59Z\"")
-		}
-	}
-
-	// Allow empty query to list all/recent articles
-	// Meilisearch treats empty string as placeholder search
-	searchReq := &meilisearch.SearchRequest{
-		Limit: int64(limit),
-		Sort:  []string{"published_at:desc"},
+	filter, err := parseArticleFilter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
 	}
 
-	if len(filter) > 0 {
-		searchReq.Filter = filter
+	facets := queryList(c, "facets")
+	for _, f := range facets {
+		if !slices.Contains(store.FacetFields, f) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("invalid facet %q, expected one of %s", f, strings.Join(store.FacetFields, ", "))})
+			return
+		}
 	}
+	highlight, _ := strconv.ParseBool(c.DefaultQuery("highlight", "false"))
 
-	result, err := h.store.Client.Index(meili.IndexArticles).Search(query, searchReq)
+	// Allow empty query to list all/recent articles
+	result, err := h.store.SearchArticles(&store.SearchQuery{
+		Query:     query,
+		Filter:    filter,
+		Sort:      []string{"published_at:desc"},
+		Limit:     limit,
+		Offset:    offset,
+		Facets:    facets,
+		Highlight: highlight,
+	})
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		searchFailed(c, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{
+	resp := gin.H{
 		"query":    query,
-		"total":    result.EstimatedTotalHits,
+		"total":    result.Total,
 		"count":    len(result.Hits),
 		"articles": result.Hits,
-	})
+	}
+	if len(facets) > 0 {
+		resp["facets"] = result.Facets
+	}
+	if highlight {
+		resp["highlights"] = result.Highlights
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// searchFailed responds to a failed search; a query needing a newer index
+// version is a conflict that migrating the index resolves
+func searchFailed(c *gin.Context, err error) {
+	var migration *store.MigrationRequiredError
+	if errors.As(err, &migration) {
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error(), "required_version": migration.Version})
+		return
+	}
+	c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 }
 
 // GetRuns returns batch run logs
@@ -157,26 +169,21 @@ func (h *Handler) GetRuns(c *gin.Context) {
 	limitStr := c.DefaultQuery("limit", "10")
 	limit, _ := strconv.Atoi(limitStr)
 
-	searchReq := &meilisearch.SearchRequest{
-		Limit: int64(limit),
-		Sort:  []string{"started_at:desc"},
-	}
-
-	result, err := h.store.Client.Index(meili.IndexRuns).Search("", searchReq)
+	runs, err := h.store.ListRuns(limit)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{
-		"count": len(result.Hits),
-		"runs":  result.Hits,
+		"count": len(runs),
+		"runs":  runs,
 	})
 }
 
 // IngestArticles handles batch ingestion of news articles
 func (h *Handler) IngestArticles(c *gin.Context) {
-	var articles []meili.ArticleDoc // Using meili package directly for now as store expects it
+	var articles []store.ArticleDoc
 	if err := c.ShouldBindJSON(&articles); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
@@ -192,3 +199,75 @@ func (h *Handler) IngestArticles(c *gin.Context) {
 		"count":   len(articles),
 	})
 }
+
+// ReprocessRequest selects the archived date range to replay
+type ReprocessRequest struct {
+	From    string   `json:"from" binding:"required"` // YYYYMMDD or YYYY-MM-DD (KST)
+	To      string   `json:"to" binding:"required"`
+	Sources []string `json:"sources"`
+	DryRun  *bool    `json:"dry_run"` // defaults to true
+}
+
+// Reprocess starts replaying archived raw articles through the current
+// pipeline in the background and returns the job; its report is available
+// from GET /news/reprocess/:id. Without "dry_run": false only the diff
+// against the store is computed.
+func (h *Handler) Reprocess(c *gin.Context) {
+	var req ReprocessRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	loc, _ := time.LoadLocation(defaultTimeZone)
+	from, err := parseFilterTime(req.From, loc, false)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid from: " + err.Error()})
+		return
+	}
+	to, err := parseFilterTime(req.To, loc, true)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid to: " + err.Error()})
+		return
+	}
+	if from.After(to) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "from is after to"})
+		return
+	}
+
+	dryRun := req.DryRun == nil || *req.DryRun
+
+	// The job outlives the request
+	job, err := h.processor.StartReprocess(context.Background(), pipeline.ReprocessOptions{
+		From:    from,
+		To:      to,
+		Sources: req.Sources,
+		DryRun:  dryRun,
+	})
+	if err != nil {
+		if errors.Is(err, pipeline.ErrReprocessRunning) {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusAccepted, job)
+}
+
+// GetReprocessJobs returns the recent reprocess jobs, newest first
+func (h *Handler) GetReprocessJobs(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"jobs": h.processor.ReprocessJobs()})
+}
+
+// GetReprocessJob returns the status of a reprocess job and, once it has
+// finished, its report
+func (h *Handler) GetReprocessJob(c *gin.Context) {
+	job, ok := h.processor.ReprocessJob(c.Param("id"))
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "reprocess job not found"})
+		return
+	}
+	c.JSON(http.StatusOK, job)
+}
@@ -0,0 +1,183 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"dx-unified/internal/news/registry"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SourceHandler manages the news sources and search queries the processor
+// runs. Changes apply from the next fetch run.
+type SourceHandler struct {
+	registry *registry.Registry
+}
+
+// NewSourceHandler creates a new source registry handler
+func NewSourceHandler(reg *registry.Registry) *SourceHandler {
+	return &SourceHandler{registry: reg}
+}
+
+// RegisterRoutes registers /news/sources and /news/queries
+func (h *SourceHandler) RegisterRoutes(rg *gin.RouterGroup) {
+	news := rg.Group("/news")
+	{
+		news.GET("/sources", h.ListSources)
+		news.POST("/sources", h.CreateSource)
+		news.GET("/sources/:name", h.GetSource)
+		news.PATCH("/sources/:name", h.UpdateSource)
+		news.DELETE("/sources/:name", h.DeleteSource)
+
+		news.GET("/queries", h.ListQueries)
+		news.POST("/queries", h.CreateQuery)
+		news.GET("/queries/:id", h.GetQuery)
+		news.PATCH("/queries/:id", h.UpdateQuery)
+		news.DELETE("/queries/:id", h.DeleteQuery)
+	}
+}
+
+// registryError maps registry errors to status codes
+func registryError(c *gin.Context, err error) {
+	status := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, registry.ErrNotFound):
+		status = http.StatusNotFound
+	case errors.Is(err, registry.ErrExists):
+		status = http.StatusConflict
+	case errors.Is(err, registry.ErrInvalid):
+		status = http.StatusBadRequest
+	}
+	c.JSON(status, gin.H{"error": err.Error()})
+}
+
+// ListSources returns the search sources and feeds
+func (h *SourceHandler) ListSources(c *gin.Context) {
+	sources := h.registry.Sources()
+	c.JSON(http.StatusOK, gin.H{"count": len(sources), "sources": sources})
+}
+
+// GetSource returns a source by name
+func (h *SourceHandler) GetSource(c *gin.Context) {
+	s, err := h.registry.Source(c.Param("name"))
+	if err != nil {
+		registryError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, s)
+}
+
+// CreateSource adds an RSS/Atom feed:
+//
+//	{"name": "hankyung_it", "url": "https://...", "publisher": "한국경제",
+//	 "schedule": "0 * * * *", "tags": ["IT"], "enabled": true}
+//
+// enabled defaults to true.
+func (h *SourceHandler) CreateSource(c *gin.Context) {
+	var req struct {
+		registry.Source
+		Enabled *bool `json:"enabled"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	req.Source.Enabled = req.Enabled == nil || *req.Enabled
+
+	s, err := h.registry.AddSource(req.Source)
+	if err != nil {
+		registryError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, s)
+}
+
+// UpdateSource changes the fields present in the body, e.g. {"enabled": false}
+func (h *SourceHandler) UpdateSource(c *gin.Context) {
+	var patch registry.SourcePatch
+	if err := c.ShouldBindJSON(&patch); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	s, err := h.registry.UpdateSource(c.Param("name"), patch)
+	if err != nil {
+		registryError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, s)
+}
+
+// DeleteSource removes a feed; search sources can only be disabled
+func (h *SourceHandler) DeleteSource(c *gin.Context) {
+	if err := h.registry.DeleteSource(c.Param("name")); err != nil {
+		registryError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// ListQueries returns the search queries, optionally of one source
+func (h *SourceHandler) ListQueries(c *gin.Context) {
+	queries := h.registry.Queries(c.Query("source"))
+	c.JSON(http.StatusOK, gin.H{"count": len(queries), "queries": queries})
+}
+
+// GetQuery returns a query by ID
+func (h *SourceHandler) GetQuery(c *gin.Context) {
+	q, err := h.registry.Query(c.Param("id"))
+	if err != nil {
+		registryError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, q)
+}
+
+// CreateQuery adds a search query to a search source:
+//
+//	{"source": "newsapi", "query": "semiconductor OR chip", "language": "en",
+//	 "schedule": "0 */2 * * *", "tags": ["반도체"], "enabled": true}
+//
+// enabled defaults to true.
+func (h *SourceHandler) CreateQuery(c *gin.Context) {
+	var req struct {
+		registry.Query
+		Enabled *bool `json:"enabled"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	req.Query.Enabled = req.Enabled == nil || *req.Enabled
+
+	q, err := h.registry.AddQuery(req.Query)
+	if err != nil {
+		registryError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, q)
+}
+
+// UpdateQuery changes the fields present in the body
+func (h *SourceHandler) UpdateQuery(c *gin.Context) {
+	var patch registry.QueryPatch
+	if err := c.ShouldBindJSON(&patch); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	q, err := h.registry.UpdateQuery(c.Param("id"), patch)
+	if err != nil {
+		registryError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, q)
+}
+
+// DeleteQuery removes a query
+func (h *SourceHandler) DeleteQuery(c *gin.Context) {
+	if err := h.registry.DeleteQuery(c.Param("id")); err != nil {
+		registryError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,67 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"dx-unified/internal/news/trending"
+
+	"github.com/gin-gonic/gin"
+)
+
+// TrendingHandler serves keyword bursts from the trending tracker
+type TrendingHandler struct {
+	tracker *trending.Tracker
+}
+
+// NewTrendingHandler creates a new trending handler
+func NewTrendingHandler(tracker *trending.Tracker) *TrendingHandler {
+	return &TrendingHandler{tracker: tracker}
+}
+
+// RegisterRoutes registers /news/trending
+func (h *TrendingHandler) RegisterRoutes(rg *gin.RouterGroup) {
+	rg.GET("/news/trending", h.GetTrending)
+}
+
+// GetTrending returns the terms spiking in the window compared with the
+// preceding windows of the same length.
+//
+//	window     1h ~ 48h in whole hours (default 6h)
+//	min_count  minimum occurrences in the window (default 3)
+//	limit      number of terms (default 20)
+func (h *TrendingHandler) GetTrending(c *gin.Context) {
+	windowHours, err := parseWindowHours(c.DefaultQuery("window", "6h"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	minCount, _ := strconv.Atoi(c.DefaultQuery("min_count", "3"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+
+	report := h.tracker.Trending(trending.Options{
+		WindowHours: windowHours,
+		MinCount:    minCount,
+		Limit:       limit,
+	})
+
+	c.JSON(http.StatusOK, report)
+}
+
+// parseWindowHours accepts "6h", "90m" (rounded up) or a bare number of hours
+func parseWindowHours(v string) (int, error) {
+	if n, err := strconv.Atoi(v); err == nil {
+		v = fmt.Sprintf("%dh", n)
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil {
+		return 0, fmt.Errorf("invalid window: %s", v)
+	}
+	hours := int((d + time.Hour - 1) / time.Hour)
+	if hours < 1 || hours > trending.MaxWindowHours {
+		return 0, fmt.Errorf("window must be between 1h and %dh", trending.MaxWindowHours)
+	}
+	return hours, nil
+}
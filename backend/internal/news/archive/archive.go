@@ -0,0 +1,165 @@
+package archive
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"dx-unified/internal/news/fetcher"
+	"dx-unified/internal/shared/timeutil"
+)
+
+// Record is one archived article as the fetcher returned it, before any
+// normalisation. Raw is the provider's item as received; records written
+// before it was kept only have the converted Article.
+type Record struct {
+	RunID     string          `json:"run_id"`
+	Source    string          `json:"source"`
+	FetchedAt time.Time       `json:"fetched_at"`
+	Article   fetcher.Article `json:"article"`
+	Raw       json.RawMessage `json:"raw,omitempty"`
+}
+
+// Archive stores raw fetch results as gzipped JSONL, one file per run and
+// source:
+//
+//	{dir}/date=2024-01-02/source=naver/150405-{run_id}.jsonl.gz
+//
+// Dates are KST so a partition matches the trading day.
+type Archive struct {
+	dir string
+}
+
+// New creates an archive rooted at dir
+func New(dir string) *Archive {
+	return &Archive{dir: dir}
+}
+
+const dateLayout = "2006-01-02"
+
+// Write archives the articles of one fetcher within a run
+func (a *Archive) Write(runID, source string, fetchedAt time.Time, articles []fetcher.Article) error {
+	if len(articles) == 0 {
+		return nil
+	}
+
+	local := fetchedAt.In(timeutil.KST)
+	dir := filepath.Join(a.dir, "date="+local.Format(dateLayout), "source="+source)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+
+	path := filepath.Join(dir, fmt.Sprintf("%s-%s.jsonl.gz", local.Format("150405"), runID))
+	tmp, err := os.CreateTemp(dir, ".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	gz := gzip.NewWriter(tmp)
+	enc := json.NewEncoder(gz)
+	for _, article := range articles {
+		rec := Record{RunID: runID, Source: source, FetchedAt: fetchedAt, Article: article, Raw: article.Raw}
+		if err := enc.Encode(&rec); err != nil {
+			tmp.Close()
+			return err
+		}
+	}
+	if err := gz.Close(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	// Readers never see a partially written file
+	return os.Rename(tmp.Name(), path)
+}
+
+// Read calls fn for every record archived between the from and to dates
+// (inclusive, KST), oldest file first. An empty sources list means all.
+func (a *Archive) Read(from, to time.Time, sources []string, fn func(Record) error) error {
+	wanted := make(map[string]bool)
+	for _, s := range sources {
+		wanted[s] = true
+	}
+
+	start := truncateDay(from.In(timeutil.KST))
+	end := truncateDay(to.In(timeutil.KST))
+
+	for day := start; !day.After(end); day = day.AddDate(0, 0, 1) {
+		dayDir := filepath.Join(a.dir, "date="+day.Format(dateLayout))
+		sourceDirs, err := os.ReadDir(dayDir)
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+
+		// Files from all sources are replayed in time order, so a later
+		// fetch of the same article wins
+		var files []string
+		for _, sd := range sourceDirs {
+			source, ok := strings.CutPrefix(sd.Name(), "source=")
+			if !sd.IsDir() || !ok || (len(wanted) > 0 && !wanted[source]) {
+				continue
+			}
+			matches, err := filepath.Glob(filepath.Join(dayDir, sd.Name(), "*.jsonl.gz"))
+			if err != nil {
+				return err
+			}
+			files = append(files, matches...)
+		}
+		sort.Slice(files, func(i, j int) bool {
+			return filepath.Base(files[i]) < filepath.Base(files[j])
+		})
+
+		for _, path := range files {
+			if err := readFile(path, fn); err != nil {
+				return fmt.Errorf("%s: %w", path, err)
+			}
+		}
+	}
+	return nil
+}
+
+func readFile(path string, fn func(Record) error) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	gz, err := gzip.NewReader(bufio.NewReader(f))
+	if err != nil {
+		return err
+	}
+	defer gz.Close()
+
+	dec := json.NewDecoder(gz)
+	for {
+		var rec Record
+		if err := dec.Decode(&rec); err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		if err := fn(rec); err != nil {
+			return err
+		}
+	}
+}
+
+func truncateDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
@@ -0,0 +1,113 @@
+package fetcher
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// Cursor is the high-water mark of one incremental query: the newest item
+// seen so far. Items at or behind it are not fetched again.
+type Cursor struct {
+	LatestPublished time.Time `json:"latest_published"`
+	LatestLink      string    `json:"latest_link"`
+	UpdatedAt       time.Time `json:"updated_at"`
+}
+
+// Reached reports whether an item is at or behind the high-water mark
+func (c Cursor) Reached(publishedAt time.Time, link string) bool {
+	if c.LatestPublished.IsZero() {
+		return false
+	}
+	return publishedAt.Before(c.LatestPublished) || link == c.LatestLink
+}
+
+// CursorFetcher is implemented by fetchers that can fetch incrementally.
+// Cursors are keyed by query; the returned cursors should only be persisted
+// once the articles have been stored, so a failed run is fetched again.
+type CursorFetcher interface {
+	Fetcher
+	FetchSince(ctx context.Context, cursors map[string]Cursor) ([]Article, map[string]Cursor, error)
+}
+
+// CursorStore persists cursors per fetcher between runs
+type CursorStore interface {
+	LoadCursors(source string) (map[string]Cursor, error)
+	SaveCursors(source string, cursors map[string]Cursor) error
+}
+
+// FileCursorStore keeps all cursors in a single JSON file
+type FileCursorStore struct {
+	path string
+	mu   sync.Mutex
+}
+
+// NewFileCursorStore creates a cursor store backed by the given file
+func NewFileCursorStore(path string) *FileCursorStore {
+	return &FileCursorStore{path: path}
+}
+
+func (s *FileCursorStore) LoadCursors(source string) (map[string]Cursor, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	all, err := s.readAll()
+	if err != nil {
+		return nil, err
+	}
+	cursors := all[source]
+	if cursors == nil {
+		cursors = make(map[string]Cursor)
+	}
+	return cursors, nil
+}
+
+func (s *FileCursorStore) SaveCursors(source string, cursors map[string]Cursor) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	all, err := s.readAll()
+	if err != nil {
+		return err
+	}
+	if all[source] == nil {
+		all[source] = make(map[string]Cursor)
+	}
+	for key, c := range cursors {
+		all[source][key] = c
+	}
+
+	data, err := json.MarshalIndent(all, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(s.path), 0755); err != nil {
+		return err
+	}
+	// Write to a temp file and rename so a crash never leaves a truncated file
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, s.path)
+}
+
+func (s *FileCursorStore) readAll() (map[string]map[string]Cursor, error) {
+	all := make(map[string]map[string]Cursor)
+
+	data, err := os.ReadFile(s.path)
+	if os.IsNotExist(err) {
+		return all, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &all); err != nil {
+		return nil, err
+	}
+	return all, nil
+}
@@ -3,9 +3,12 @@ package naver
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"log"
 	"net/http"
 	"net/url"
+	"strconv"
 	"strings"
 	"time"
 
@@ -18,9 +21,14 @@ import (
 const (
 	BaseURL = "https://openapi.naver.com/v1/search/news.json"
 	Source  = "naver_search_api"
+
+	// The search API returns at most 100 items per page and rejects start > 1000
+	PageSize = 100
+	MaxStart = 1000
 )
 
 type Client struct {
+	baseURL     string
 	cfg         *config.Config
 	rateLimiter *rate.Limiter
 	httpClient  *http.Client
@@ -32,6 +40,8 @@ type Response struct {
 	Start         int    `json:"start"`
 	Display       int    `json:"display"`
 	Items         []Item `json:"items"`
+
+	RawItems []json.RawMessage `json:"-"` // Items as received
 }
 
 type Item struct {
@@ -42,11 +52,18 @@ type Item struct {
 	PubDate      string `json:"pubDate"`
 }
 
+var (
+	_ fetcher.CursorFetcher = (*Client)(nil)
+	_ fetcher.TargetFetcher = (*Client)(nil)
+	_ fetcher.RawDecoder    = (*Client)(nil)
+)
+
 func New(cfg *config.Config) *Client {
 	// 9 QPS limit as per requirements (conservative)
 	limiter := rate.NewLimiter(rate.Limit(9), 1)
 
 	return &Client{
+		baseURL:     BaseURL,
 		cfg:         cfg,
 		rateLimiter: limiter,
 		httpClient:  &http.Client{Timeout: 10 * time.Second},
@@ -57,45 +74,143 @@ func (c *Client) Name() string {
 	return Source
 }
 
-func (c *Client) Fetch() ([]fetcher.Article, error) {
-	var allArticles []fetcher.Article
+func (c *Client) HTTPClient() *http.Client {
+	return c.httpClient
+}
+
+func (c *Client) Fetch(ctx context.Context) ([]fetcher.Article, error) {
+	articles, _, err := c.FetchSince(ctx, nil)
+	return articles, err
+}
 
+// FetchSince pages through each configured query until the query's
+// high-water mark is reached or the API's start limit is hit, and returns
+// the advanced cursors.
+func (c *Client) FetchSince(ctx context.Context, cursors map[string]fetcher.Cursor) ([]fetcher.Article, map[string]fetcher.Cursor, error) {
+	targets := make([]fetcher.Target, 0, len(c.cfg.NaverQueries))
 	for _, query := range c.cfg.NaverQueries {
-		if err := c.rateLimiter.Wait(context.Background()); err != nil { // Wait needs a context
-			return nil, fmt.Errorf("rate limiter wait: %w", err)
+		targets = append(targets, fetcher.Target{Key: query, Query: query})
+	}
+	return c.FetchTargets(ctx, targets, cursors)
+}
+
+// FetchTargets fetches the given queries incrementally. The search API has
+// no language filter, so Target.Language is ignored.
+func (c *Client) FetchTargets(ctx context.Context, targets []fetcher.Target, cursors map[string]fetcher.Cursor) ([]fetcher.Article, map[string]fetcher.Cursor, error) {
+	var allArticles []fetcher.Article
+	var errs []error
+	next := make(map[string]fetcher.Cursor)
+
+	for _, t := range targets {
+		if ctx.Err() != nil {
+			errs = append(errs, ctx.Err())
+			break
 		}
 
-		articles, err := c.fetchQuery(query)
+		articles, cursor, err := c.fetchQuery(ctx, t.Query, cursors[t.Key])
 		if err != nil {
-			// Log error but continue with other queries?
-			// For now, let's just print/log and continue, gathering partial results is better than none.
-			fmt.Printf("Error fetching query %s: %v\n", query, err)
-			continue
+			// Continue with other queries; partial results are better than none
+			errs = append(errs, fmt.Errorf("query %s: %w", t.Query, err))
+		}
+		for i := range articles {
+			articles[i].Tags = t.Tags
 		}
 		allArticles = append(allArticles, articles...)
+		if !cursor.LatestPublished.IsZero() {
+			next[t.Key] = cursor
+		}
+	}
+
+	return allArticles, next, errors.Join(errs...)
+}
+
+// fetchQuery returns the new articles for a query and its next cursor. On a
+// mid-pagination error the articles gathered so far are returned with an
+// unchanged cursor, so the rest is fetched again next run. When the page
+// limit is hit before the cursor is reached, the cursor still advances:
+// later runs start from the newest result again and cannot page any deeper,
+// so the articles between the limit and the cursor are lost.
+func (c *Client) fetchQuery(ctx context.Context, query string, cursor fetcher.Cursor) ([]fetcher.Article, fetcher.Cursor, error) {
+	var articles []fetcher.Article
+	nextCursor := cursor
+	complete := false // reached the cursor or the end of the results
+
+	for page := 0; page < c.maxPages(); page++ {
+		start := page*PageSize + 1
+		if start > MaxStart {
+			break
+		}
+
+		if err := c.rateLimiter.Wait(ctx); err != nil {
+			return articles, cursor, fmt.Errorf("rate limiter wait: %w", err)
+		}
+
+		result, err := c.fetchPage(ctx, query, start)
+		if err != nil {
+			return articles, cursor, err
+		}
+
+		reached := false
+		now := time.Now()
+		for i, item := range result.Items {
+			a := toArticle(item, now)
+			a.Raw = result.RawItems[i]
+
+			if page == 0 && i == 0 {
+				nextCursor = fetcher.Cursor{
+					LatestPublished: a.PublishedAt,
+					LatestLink:      a.URL,
+					UpdatedAt:       time.Now(),
+				}
+			}
+
+			// Results are sorted by date, so everything after the mark was seen before
+			if cursor.Reached(a.PublishedAt, a.URL) {
+				reached = true
+				break
+			}
+			articles = append(articles, a)
+		}
+
+		if reached || len(result.Items) < PageSize || start+PageSize > result.Total {
+			complete = true
+			break
+		}
 	}
 
-	return allArticles, nil
+	if !complete && !cursor.LatestPublished.IsZero() {
+		log.Printf("[NEWS] Naver query %q: page limit hit after %d articles, articles back to %s cannot be fetched",
+			query, len(articles), cursor.LatestPublished.Format(time.RFC3339))
+	}
+	return articles, nextCursor, nil
+}
+
+func (c *Client) maxPages() int {
+	if c.cfg.NaverMaxPages > 0 {
+		return c.cfg.NaverMaxPages
+	}
+	return MaxStart/PageSize + 1
 }
 
-func (c *Client) fetchQuery(query string) ([]fetcher.Article, error) {
+func (c *Client) fetchPage(ctx context.Context, query string, start int) (*Response, error) {
 	// Sort by date to get latest news
-	u, _ := url.Parse(BaseURL)
+	u, _ := url.Parse(c.baseURL)
 	q := u.Query()
 	q.Set("query", query)
-	q.Set("display", "100") // Max allowed
+	q.Set("display", strconv.Itoa(PageSize)) // Max allowed
+	q.Set("start", strconv.Itoa(start))
 	q.Set("sort", "date")
 	u.RawQuery = q.Encode()
 
-	req, err := http.NewRequest("GET", u.String(), nil)
-	if err != nil {
-		return nil, err
-	}
-
-	req.Header.Set("X-Naver-Client-Id", c.cfg.NaverClientID)
-	req.Header.Set("X-Naver-Client-Secret", c.cfg.NaverClientSecret)
-
-	resp, err := c.httpClient.Do(req)
+	resp, err := fetcher.DoWithRetry(ctx, c.httpClient, c.cfg.NewsFetchRetries, func(ctx context.Context) (*http.Request, error) {
+		req, err := http.NewRequestWithContext(ctx, "GET", u.String(), nil)
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("X-Naver-Client-Id", c.cfg.NaverClientID)
+		req.Header.Set("X-Naver-Client-Secret", c.cfg.NaverClientSecret)
+		return req, nil
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -105,40 +220,62 @@ func (c *Client) fetchQuery(query string) ([]fetcher.Article, error) {
 		return nil, fmt.Errorf("naver api error: %d", resp.StatusCode)
 	}
 
-	var result Response
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+	// Items are decoded one by one to keep them as received for the archive
+	var page struct {
+		Response
+		Items []json.RawMessage `json:"items"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&page); err != nil {
 		return nil, err
 	}
-
-	var articles []fetcher.Article
-	for _, item := range result.Items {
-		pubDate, err := time.Parse(time.RFC1123Z, item.PubDate)
-		if err != nil {
-			// Try another format if needed or just skip/log
-			pubDate = time.Now() // Fallback
+	result := page.Response
+	result.Items = make([]Item, len(page.Items))
+	result.RawItems = page.Items
+	for i, raw := range page.Items {
+		if err := json.Unmarshal(raw, &result.Items[i]); err != nil {
+			return nil, err
 		}
+	}
 
-		// Prefer original link if available
-		link := item.Originallink
-		if link == "" {
-			link = item.Link
-		}
+	return &result, nil
+}
 
-		articles = append(articles, fetcher.Article{
-			Title:       stripHTML(item.Title),
-			Summary:     stripHTML(item.Description),
-			URL:         link,
-			Source:      Source,
-			PublishedAt: pubDate,
-			FetchedAt:   time.Now(),
-			RawProviderData: map[string]interface{}{
-				"original_title": item.Title,
-				"description":    item.Description,
-			},
-		})
+// DecodeRaw rebuilds an article from an archived search item
+func (c *Client) DecodeRaw(raw json.RawMessage, fetchedAt time.Time) (fetcher.Article, error) {
+	var item Item
+	if err := json.Unmarshal(raw, &item); err != nil {
+		return fetcher.Article{}, err
+	}
+	a := toArticle(item, fetchedAt)
+	a.Raw = raw
+	return a, nil
+}
+
+func toArticle(item Item, now time.Time) fetcher.Article {
+	pubDate, err := time.Parse(time.RFC1123Z, item.PubDate)
+	if err != nil {
+		// Try another format if needed or just skip/log
+		pubDate = now // Fallback
 	}
 
-	return articles, nil
+	// Prefer original link if available
+	link := item.Originallink
+	if link == "" {
+		link = item.Link
+	}
+
+	return fetcher.Article{
+		Title:       stripHTML(item.Title),
+		Summary:     stripHTML(item.Description),
+		URL:         link,
+		Source:      Source,
+		PublishedAt: pubDate,
+		FetchedAt:   now,
+		RawProviderData: map[string]interface{}{
+			"original_title": item.Title,
+			"description":    item.Description,
+		},
+	}
 }
 
 func stripHTML(s string) string {
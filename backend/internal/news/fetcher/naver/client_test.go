@@ -0,0 +1,109 @@
+package naver
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"dx-unified/internal/news/fetcher"
+	"dx-unified/internal/shared/config"
+)
+
+var newest = time.Date(2026, 10, 16, 15, 0, 0, 0, time.FixedZone("KST", 9*60*60))
+
+// testItem is the i-th newest search result, one minute apart
+func testItem(i int) Item {
+	return Item{
+		Title:        fmt.Sprintf("<b>기사</b> %d", i),
+		Originallink: fmt.Sprintf("https://example.com/%d", i),
+		Link:         fmt.Sprintf("https://n.news.naver.com/%d", i),
+		PubDate:      newest.Add(-time.Duration(i) * time.Minute).Format(time.RFC1123Z),
+	}
+}
+
+// newTestClient serves total results sorted by date, like the search API,
+// and fails the page at failStart when it is set
+func newTestClient(t *testing.T, total, maxPages, failStart int) *Client {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start, _ := strconv.Atoi(r.URL.Query().Get("start"))
+		if start == failStart {
+			http.Error(w, "bad request", http.StatusBadRequest)
+			return
+		}
+		display, _ := strconv.Atoi(r.URL.Query().Get("display"))
+		resp := Response{Total: total, Start: start, Display: display, Items: []Item{}}
+		for i := start - 1; i < total && i < start-1+display; i++ {
+			resp.Items = append(resp.Items, testItem(i))
+		}
+		json.NewEncoder(w).Encode(resp)
+	}))
+	t.Cleanup(srv.Close)
+
+	c := New(&config.Config{NaverMaxPages: maxPages})
+	c.baseURL = srv.URL
+	return c
+}
+
+func cursorAt(i int) fetcher.Cursor {
+	a := toArticle(testItem(i), time.Now())
+	return fetcher.Cursor{LatestPublished: a.PublishedAt, LatestLink: a.URL}
+}
+
+func TestFetchQuery(t *testing.T) {
+	tests := []struct {
+		name       string
+		total      int
+		maxPages   int
+		failStart  int
+		cursor     fetcher.Cursor
+		wantCount  int
+		wantCursor fetcher.Cursor
+	}{
+		{"first run", 250, 0, 0, fetcher.Cursor{}, 250, cursorAt(0)},
+		{"up to the cursor", 250, 0, 0, cursorAt(150), 150, cursorAt(0)},
+		{"nothing new", 250, 0, 0, cursorAt(0), 0, cursorAt(0)},
+		{"first run over the page limit", 250, 1, 0, fetcher.Cursor{}, 100, cursorAt(0)},
+		// Later runs cannot page past the limit either, so the articles
+		// between the limit and the cursor are given up
+		{"page limit before the cursor", 250, 1, 0, cursorAt(150), 100, cursorAt(0)},
+		{"API start limit before the cursor", 1500, 0, 0, cursorAt(1200), 1000, cursorAt(0)},
+		// The rest is fetched again next run
+		{"error before the cursor", 250, 0, 101, cursorAt(150), 100, cursorAt(150)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newTestClient(t, tt.total, tt.maxPages, tt.failStart)
+			articles, cursor, err := c.fetchQuery(context.Background(), "삼성전자", tt.cursor)
+			if (err != nil) != (tt.failStart > 0) {
+				t.Fatalf("err = %v", err)
+			}
+			if len(articles) != tt.wantCount {
+				t.Errorf("got %d articles, want %d", len(articles), tt.wantCount)
+			}
+			if !cursor.LatestPublished.Equal(tt.wantCursor.LatestPublished) || cursor.LatestLink != tt.wantCursor.LatestLink {
+				t.Errorf("cursor = %s %s, want %s %s", cursor.LatestPublished, cursor.LatestLink,
+					tt.wantCursor.LatestPublished, tt.wantCursor.LatestLink)
+			}
+		})
+	}
+}
+
+func TestDecodeRaw(t *testing.T) {
+	c := newTestClient(t, 1, 0, 0)
+	articles, _, err := c.fetchQuery(context.Background(), "삼성전자", fetcher.Cursor{})
+	if err != nil || len(articles) != 1 {
+		t.Fatalf("fetchQuery = %v, %v", articles, err)
+	}
+	got, err := c.DecodeRaw(articles[0].Raw, articles[0].FetchedAt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Title != "기사 0" || got.URL != "https://example.com/0" || !got.PublishedAt.Equal(newest) {
+		t.Errorf("DecodeRaw = %+v", got)
+	}
+}
@@ -1,7 +1,9 @@
 package newsapi
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"net/url"
@@ -14,6 +16,11 @@ import (
 const (
 	BaseURL = "https://newsapi.org/v2/everything"
 	Source  = "newsapi"
+
+	// Keywords selected to maximize economic/market news coverage, used
+	// until the source registry has queries of its own
+	DefaultQuery    = "economy OR stock OR market OR finance OR business"
+	DefaultLanguage = "en"
 )
 
 type Client struct {
@@ -45,6 +52,11 @@ type SourceInfo struct {
 	Name string `json:"name"`
 }
 
+var (
+	_ fetcher.TargetFetcher = (*Client)(nil)
+	_ fetcher.RawDecoder    = (*Client)(nil)
+)
+
 func New(cfg *config.Config) *Client {
 	return &Client{
 		cfg:        cfg,
@@ -56,27 +68,67 @@ func (c *Client) Name() string {
 	return Source
 }
 
-func (c *Client) Fetch() ([]fetcher.Article, error) {
-	// Strategy: Use /v2/everything with English keywords to fetch US/Global business news.
-	// We stay within 100 reqs/day by running every 15 minutes (96 reqs/day).
-	// Keywords are selected to maximize economic/market news coverage.
+func (c *Client) HTTPClient() *http.Client {
+	return c.httpClient
+}
+
+func (c *Client) Fetch(ctx context.Context) ([]fetcher.Article, error) {
+	return c.fetchQuery(ctx, DefaultQuery, DefaultLanguage)
+}
+
+// FetchTargets runs one request per query. Results are sorted by
+// publication date and not paged, so there are no cursors.
+func (c *Client) FetchTargets(ctx context.Context, targets []fetcher.Target, _ map[string]fetcher.Cursor) ([]fetcher.Article, map[string]fetcher.Cursor, error) {
+	var allArticles []fetcher.Article
+	var errs []error
+
+	for _, t := range targets {
+		if ctx.Err() != nil {
+			errs = append(errs, ctx.Err())
+			break
+		}
+
+		language := t.Language
+		if language == "" {
+			language = DefaultLanguage
+		}
+		articles, err := c.fetchQuery(ctx, t.Query, language)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("query %s: %w", t.Query, err))
+			continue
+		}
+		for i := range articles {
+			articles[i].Tags = t.Tags
+		}
+		allArticles = append(allArticles, articles...)
+	}
+
+	return allArticles, nil, errors.Join(errs...)
+}
+
+func (c *Client) fetchQuery(ctx context.Context, query, language string) ([]fetcher.Article, error) {
+	// Strategy: Use /v2/everything sorted by date to fetch US/Global business news.
+	// The free plan allows 100 reqs/day; the 15-minute cron needs 96 with a
+	// single query, and the quota manager (news_daily_budgets) skips runs once
+	// retries or extra queries eat the margin.
 
 	u, _ := url.Parse(BaseURL)
 	q := u.Query()
-	q.Set("q", "economy OR stock OR market OR finance OR business")
-	q.Set("language", "en")
+	q.Set("q", query)
+	q.Set("language", language)
 	q.Set("sortBy", "publishedAt")
 	q.Set("pageSize", "100")
 	u.RawQuery = q.Encode()
 
-	req, err := http.NewRequest("GET", u.String(), nil)
-	if err != nil {
-		return nil, err
-	}
-
-	req.Header.Set("X-Api-Key", c.cfg.NewsAPIKey)
-
-	resp, err := c.httpClient.Do(req)
+	// The free plan's daily quota is hard, so rate limits are not retried
+	resp, err := fetcher.DoWithQuotaRetry(ctx, c.httpClient, c.cfg.NewsFetchRetries, func(ctx context.Context) (*http.Request, error) {
+		req, err := http.NewRequestWithContext(ctx, "GET", u.String(), nil)
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("X-Api-Key", c.cfg.NewsAPIKey)
+		return req, nil
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -86,7 +138,11 @@ func (c *Client) Fetch() ([]fetcher.Article, error) {
 		return nil, fmt.Errorf("newsapi error: %d", resp.StatusCode)
 	}
 
-	var result Response
+	// Articles are decoded one by one to keep them as received for the archive
+	var result struct {
+		Response
+		Articles []json.RawMessage `json:"articles"`
+	}
 	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
 		return nil, err
 	}
@@ -95,27 +151,49 @@ func (c *Client) Fetch() ([]fetcher.Article, error) {
 		return nil, fmt.Errorf("newsapi error: %s - %s", result.Code, result.Message)
 	}
 
+	now := time.Now()
 	var articles []fetcher.Article
-	for _, item := range result.Articles {
-		pubDate, err := time.Parse(time.RFC3339, item.PublishedAt)
-		if err != nil {
-			pubDate = time.Now()
+	for _, raw := range result.Articles {
+		var item Item
+		if err := json.Unmarshal(raw, &item); err != nil {
+			return nil, err
 		}
-
-		articles = append(articles, fetcher.Article{
-			Title:       item.Title,
-			Summary:     item.Description,
-			URL:         item.Url,
-			Source:      Source,
-			Publisher:   item.Source.Name,
-			PublishedAt: pubDate,
-			FetchedAt:   time.Now(),
-			RawProviderData: map[string]interface{}{
-				"author":  item.Author,
-				"content": item.Content,
-			},
-		})
+		a := toArticle(item, now)
+		a.Raw = raw
+		articles = append(articles, a)
 	}
 
 	return articles, nil
 }
+
+// DecodeRaw rebuilds an article from an archived result item
+func (c *Client) DecodeRaw(raw json.RawMessage, fetchedAt time.Time) (fetcher.Article, error) {
+	var item Item
+	if err := json.Unmarshal(raw, &item); err != nil {
+		return fetcher.Article{}, err
+	}
+	a := toArticle(item, fetchedAt)
+	a.Raw = raw
+	return a, nil
+}
+
+func toArticle(item Item, now time.Time) fetcher.Article {
+	pubDate, err := time.Parse(time.RFC3339, item.PublishedAt)
+	if err != nil {
+		pubDate = now
+	}
+
+	return fetcher.Article{
+		Title:       item.Title,
+		Summary:     item.Description,
+		URL:         item.Url,
+		Source:      Source,
+		Publisher:   item.Source.Name,
+		PublishedAt: pubDate,
+		FetchedAt:   now,
+		RawProviderData: map[string]interface{}{
+			"author":  item.Author,
+			"content": item.Content,
+		},
+	}
+}
@@ -0,0 +1,107 @@
+package fetcher
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	retryBaseDelay = 1 * time.Second
+	retryMaxDelay  = 30 * time.Second
+)
+
+// DoWithRetry sends a request, retrying network errors, 429 and 5xx
+// responses with exponential backoff (honouring Retry-After). newReq is
+// called for every attempt because a request body cannot be replayed. The
+// last response is returned as-is, so callers still check the status code.
+func DoWithRetry(ctx context.Context, client *http.Client, maxAttempts int, newReq func(ctx context.Context) (*http.Request, error)) (*http.Response, error) {
+	return doWithRetry(ctx, client, maxAttempts, true, newReq)
+}
+
+// DoWithQuotaRetry is DoWithRetry for providers with a hard request quota:
+// a 429 is returned at once, since retrying would only spend more of the
+// quota. The quota manager pauses the source when it sees the 429.
+func DoWithQuotaRetry(ctx context.Context, client *http.Client, maxAttempts int, newReq func(ctx context.Context) (*http.Request, error)) (*http.Response, error) {
+	return doWithRetry(ctx, client, maxAttempts, false, newReq)
+}
+
+func doWithRetry(ctx context.Context, client *http.Client, maxAttempts int, retryRateLimited bool, newReq func(ctx context.Context) (*http.Request, error)) (*http.Response, error) {
+	if maxAttempts < 1 {
+		maxAttempts = 1
+	}
+
+	var lastErr error
+	for attempt := 0; attempt < maxAttempts; attempt++ {
+		if attempt > 0 {
+			delay := backoff(attempt, lastErr)
+			select {
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			case <-time.After(delay):
+			}
+		}
+
+		req, err := newReq(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		resp, err := client.Do(req)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			lastErr = err
+			continue
+		}
+
+		retryable := retryableStatus(resp.StatusCode) && (retryRateLimited || resp.StatusCode != http.StatusTooManyRequests)
+		if !retryable || attempt == maxAttempts-1 {
+			return resp, nil
+		}
+
+		lastErr = &retryableError{status: resp.StatusCode, retryAfter: parseRetryAfter(resp.Header.Get("Retry-After"))}
+		resp.Body.Close()
+	}
+
+	return nil, fmt.Errorf("giving up after %d attempts: %w", maxAttempts, lastErr)
+}
+
+type retryableError struct {
+	status     int
+	retryAfter time.Duration
+}
+
+func (e *retryableError) Error() string {
+	return fmt.Sprintf("status %d", e.status)
+}
+
+func retryableStatus(code int) bool {
+	return code == http.StatusTooManyRequests || code >= 500
+}
+
+func backoff(attempt int, lastErr error) time.Duration {
+	if re, ok := lastErr.(*retryableError); ok && re.retryAfter > 0 {
+		return min(re.retryAfter, retryMaxDelay)
+	}
+	delay := retryBaseDelay << (attempt - 1)
+	delay += time.Duration(rand.Int63n(int64(delay) / 2))
+	return min(delay, retryMaxDelay)
+}
+
+func parseRetryAfter(v string) time.Duration {
+	if v == "" {
+		return 0
+	}
+	if secs, err := strconv.Atoi(v); err == nil {
+		return time.Duration(secs) * time.Second
+	}
+	if t, err := http.ParseTime(v); err == nil {
+		return time.Until(t)
+	}
+	return 0
+}
@@ -0,0 +1,50 @@
+package fetcher
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestDoWithRetry(t *testing.T) {
+	tests := []struct {
+		name      string
+		quota     bool
+		status    int
+		wantCalls int
+	}{
+		{"server error", false, http.StatusBadGateway, 2},
+		{"rate limited", false, http.StatusTooManyRequests, 2},
+		{"not found", false, http.StatusNotFound, 1},
+		{"quota server error", true, http.StatusBadGateway, 2},
+		// Retrying would spend more of a hard quota
+		{"quota rate limited", true, http.StatusTooManyRequests, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := 0
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				calls++
+				w.Header().Set("Retry-After", "1")
+				w.WriteHeader(tt.status)
+			}))
+			defer srv.Close()
+
+			do := DoWithRetry
+			if tt.quota {
+				do = DoWithQuotaRetry
+			}
+			resp, err := do(context.Background(), srv.Client(), 2, func(ctx context.Context) (*http.Request, error) {
+				return http.NewRequestWithContext(ctx, "GET", srv.URL, nil)
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp.Body.Close()
+			if resp.StatusCode != tt.status || calls != tt.wantCalls {
+				t.Errorf("status %d after %d calls, want %d after %d", resp.StatusCode, calls, tt.status, tt.wantCalls)
+			}
+		})
+	}
+}
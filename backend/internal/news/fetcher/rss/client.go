@@ -0,0 +1,482 @@
+package rss
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"net/http"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"dx-unified/internal/news/fetcher"
+	"dx-unified/internal/shared/config"
+	"dx-unified/internal/shared/timeutil"
+
+	"golang.org/x/net/html/charset"
+)
+
+const (
+	Source = "rss"
+
+	userAgent = "Mozilla/5.0 (compatible; dx-unified-news/1.0)"
+)
+
+type Client struct {
+	cfg        *config.Config
+	httpClient *http.Client
+
+	// Conditional GET validators per feed URL, kept in memory between runs
+	mu         sync.Mutex
+	validators map[string]validator
+}
+
+type validator struct {
+	ETag         string
+	LastModified string
+}
+
+// RSS 2.0 document (RSS 1.0/RDF keeps items next to the channel)
+type rssDoc struct {
+	Channel struct {
+		Title string    `xml:"title"`
+		Items []rssItem `xml:"item"`
+	} `xml:"channel"`
+	Items []rssItem `xml:"item"`
+}
+
+type rssItem struct {
+	Title       string `xml:"title"`
+	Link        string `xml:"link"`
+	GUID        string `xml:"guid"`
+	Description string `xml:"description"`
+	PubDate     string `xml:"pubDate"`
+	DCDate      string `xml:"http://purl.org/dc/elements/1.1/ date"`
+	Author      string `xml:"author"`
+	Creator     string `xml:"http://purl.org/dc/elements/1.1/ creator"`
+	XML         string `xml:",innerxml"`
+}
+
+// Atom 1.0 document
+type atomDoc struct {
+	Title   string      `xml:"title"`
+	Entries []atomEntry `xml:"entry"`
+}
+
+type atomEntry struct {
+	ID        string     `xml:"id"`
+	Title     string     `xml:"title"`
+	Links     []atomLink `xml:"link"`
+	Summary   string     `xml:"summary"`
+	Content   string     `xml:"content"`
+	Published string     `xml:"published"`
+	Updated   string     `xml:"updated"`
+	Author    struct {
+		Name string `xml:"name"`
+	} `xml:"author"`
+	XML string `xml:",innerxml"`
+}
+
+type atomLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr"`
+}
+
+var (
+	_ fetcher.TargetFetcher = (*Client)(nil)
+	_ fetcher.RawDecoder    = (*Client)(nil)
+)
+
+func New(cfg *config.Config) *Client {
+	return &Client{
+		cfg:        cfg,
+		httpClient: &http.Client{Timeout: 15 * time.Second},
+		validators: make(map[string]validator),
+	}
+}
+
+func (c *Client) Name() string {
+	return Source
+}
+
+func (c *Client) HTTPClient() *http.Client {
+	return c.httpClient
+}
+
+func (c *Client) Fetch(ctx context.Context) ([]fetcher.Article, error) {
+	targets := make([]fetcher.Target, 0, len(c.cfg.RSSFeeds))
+	for _, feed := range c.cfg.RSSFeeds {
+		targets = append(targets, fetcher.Target{Key: feed.Name, URL: feed.URL, Publisher: feed.Publisher})
+	}
+	articles, _, err := c.FetchTargets(ctx, targets, nil)
+	return articles, err
+}
+
+// FetchTargets polls the given feeds. Conditional GET replaces cursors.
+func (c *Client) FetchTargets(ctx context.Context, targets []fetcher.Target, _ map[string]fetcher.Cursor) ([]fetcher.Article, map[string]fetcher.Cursor, error) {
+	var allArticles []fetcher.Article
+	var errs []error
+
+	for _, t := range targets {
+		if ctx.Err() != nil {
+			errs = append(errs, ctx.Err())
+			break
+		}
+
+		feed := config.RSSFeed{Name: t.Key, URL: t.URL, Publisher: t.Publisher}
+		articles, err := c.fetchFeed(ctx, feed)
+		if err != nil {
+			// One broken feed should not hide the others
+			errs = append(errs, fmt.Errorf("feed %s: %w", feed.Name, err))
+			continue
+		}
+		for i := range articles {
+			articles[i].Tags = t.Tags
+		}
+		allArticles = append(allArticles, articles...)
+	}
+
+	return allArticles, nil, errors.Join(errs...)
+}
+
+func (c *Client) fetchFeed(ctx context.Context, feed config.RSSFeed) ([]fetcher.Article, error) {
+	c.mu.Lock()
+	v := c.validators[feed.URL]
+	c.mu.Unlock()
+
+	resp, err := fetcher.DoWithRetry(ctx, c.httpClient, c.cfg.NewsFetchRetries, func(ctx context.Context) (*http.Request, error) {
+		req, err := http.NewRequestWithContext(ctx, "GET", feed.URL, nil)
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("User-Agent", userAgent)
+		req.Header.Set("Accept", "application/rss+xml, application/atom+xml, application/xml;q=0.9, */*;q=0.8")
+		if v.ETag != "" {
+			req.Header.Set("If-None-Match", v.ETag)
+		}
+		if v.LastModified != "" {
+			req.Header.Set("If-Modified-Since", v.LastModified)
+		}
+		return req, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotModified {
+		return nil, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("feed error: %d", resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	articles, err := parseFeed(body, feed)
+	if err != nil {
+		return nil, err
+	}
+
+	// Only remember validators once the body parsed, otherwise a broken
+	// response would be skipped forever with 304s.
+	c.mu.Lock()
+	c.validators[feed.URL] = validator{
+		ETag:         resp.Header.Get("ETag"),
+		LastModified: resp.Header.Get("Last-Modified"),
+	}
+	c.mu.Unlock()
+
+	return articles, nil
+}
+
+func parseFeed(body []byte, feed config.RSSFeed) ([]fetcher.Article, error) {
+	root, err := rootElement(body)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	var articles []fetcher.Article
+	add := func(a fetcher.Article, ok bool, raw rawItem) error {
+		if !ok {
+			return nil
+		}
+		var err error
+		if a.Raw, err = json.Marshal(raw); err != nil {
+			return err
+		}
+		articles = append(articles, a)
+		return nil
+	}
+
+	switch root {
+	case "rss", "RDF":
+		var doc rssDoc
+		if err := newDecoder(body).Decode(&doc); err != nil {
+			return nil, fmt.Errorf("decode rss: %w", err)
+		}
+		publisher := publisherFor(feed, doc.Channel.Title)
+
+		for _, item := range append(doc.Channel.Items, doc.Items...) {
+			a, ok := rssArticle(item, feed.Name, publisher, now)
+			if err := add(a, ok, rawItem{Feed: feed.Name, Publisher: publisher, Format: formatRSS, XML: item.XML}); err != nil {
+				return nil, err
+			}
+		}
+
+	case "feed":
+		var doc atomDoc
+		if err := newDecoder(body).Decode(&doc); err != nil {
+			return nil, fmt.Errorf("decode atom: %w", err)
+		}
+		publisher := publisherFor(feed, doc.Title)
+
+		for _, entry := range doc.Entries {
+			a, ok := atomArticle(entry, feed.Name, publisher, now)
+			if err := add(a, ok, rawItem{Feed: feed.Name, Publisher: publisher, Format: formatAtom, XML: entry.XML}); err != nil {
+				return nil, err
+			}
+		}
+
+	default:
+		return nil, fmt.Errorf("unsupported feed format: <%s>", root)
+	}
+
+	return articles, nil
+}
+
+// rssArticle converts an RSS item; ok is false for items without a link
+func rssArticle(item rssItem, feedName, publisher string, now time.Time) (a fetcher.Article, ok bool) {
+	link := strings.TrimSpace(item.Link)
+	if link == "" && strings.HasPrefix(item.GUID, "http") {
+		link = strings.TrimSpace(item.GUID)
+	}
+	if link == "" {
+		return a, false
+	}
+
+	dateStr := item.PubDate
+	if dateStr == "" {
+		dateStr = item.DCDate
+	}
+
+	author := item.Author
+	if author == "" {
+		author = item.Creator
+	}
+
+	return fetcher.Article{
+		Title:       cleanText(item.Title),
+		Summary:     cleanText(item.Description),
+		URL:         link,
+		Source:      Source,
+		Publisher:   publisher,
+		PublishedAt: parseDate(dateStr, now),
+		FetchedAt:   now,
+		RawProviderData: map[string]interface{}{
+			"feed":     feedName,
+			"guid":     item.GUID,
+			"pub_date": dateStr,
+			"author":   strings.TrimSpace(author),
+		},
+	}, true
+}
+
+// atomArticle converts an Atom entry; ok is false for entries without a link
+func atomArticle(entry atomEntry, feedName, publisher string, now time.Time) (a fetcher.Article, ok bool) {
+	link := atomEntryLink(entry)
+	if link == "" {
+		return a, false
+	}
+
+	dateStr := entry.Published
+	if dateStr == "" {
+		dateStr = entry.Updated
+	}
+
+	summary := entry.Summary
+	if summary == "" {
+		summary = entry.Content
+	}
+
+	return fetcher.Article{
+		Title:       cleanText(entry.Title),
+		Summary:     cleanText(summary),
+		URL:         link,
+		Source:      Source,
+		Publisher:   publisher,
+		PublishedAt: parseDate(dateStr, now),
+		FetchedAt:   now,
+		RawProviderData: map[string]interface{}{
+			"feed":     feedName,
+			"guid":     entry.ID,
+			"pub_date": dateStr,
+			"author":   strings.TrimSpace(entry.Author.Name),
+		},
+	}, true
+}
+
+// Formats of a rawItem
+const (
+	formatRSS  = "rss"
+	formatAtom = "atom"
+)
+
+// rawItem is the archived form of a feed item: its XML as received,
+// decoded to UTF-8, and the feed it came from
+type rawItem struct {
+	Feed      string `json:"feed"`
+	Publisher string `json:"publisher"`
+	Format    string `json:"format"`
+	XML       string `json:"xml"`
+}
+
+// DecodeRaw rebuilds an article from an archived feed item
+func (c *Client) DecodeRaw(raw json.RawMessage, fetchedAt time.Time) (fetcher.Article, error) {
+	var r rawItem
+	if err := json.Unmarshal(raw, &r); err != nil {
+		return fetcher.Article{}, err
+	}
+
+	var a fetcher.Article
+	ok := false
+	switch r.Format {
+	case formatRSS:
+		// The dc prefix was declared on the feed's root element
+		var item rssItem
+		if err := newDecoder([]byte(`<item xmlns:dc="http://purl.org/dc/elements/1.1/">` + r.XML + `</item>`)).Decode(&item); err != nil {
+			return a, fmt.Errorf("decode rss item: %w", err)
+		}
+		a, ok = rssArticle(item, r.Feed, r.Publisher, fetchedAt)
+	case formatAtom:
+		var entry atomEntry
+		if err := newDecoder([]byte(`<entry>` + r.XML + `</entry>`)).Decode(&entry); err != nil {
+			return a, fmt.Errorf("decode atom entry: %w", err)
+		}
+		a, ok = atomArticle(entry, r.Feed, r.Publisher, fetchedAt)
+	default:
+		return a, fmt.Errorf("unknown raw item format %q", r.Format)
+	}
+	if !ok {
+		return a, errors.New("archived item has no link")
+	}
+	a.Raw = raw
+	return a, nil
+}
+
+// newDecoder handles non-UTF-8 feeds (EUC-KR is still common on Korean sites)
+func newDecoder(body []byte) *xml.Decoder {
+	d := xml.NewDecoder(bytes.NewReader(body))
+	d.CharsetReader = charset.NewReaderLabel
+	d.Strict = false
+	d.Entity = xml.HTMLEntity
+	return d
+}
+
+func rootElement(body []byte) (string, error) {
+	d := newDecoder(body)
+	for {
+		tok, err := d.Token()
+		if err != nil {
+			return "", fmt.Errorf("read feed root: %w", err)
+		}
+		if se, ok := tok.(xml.StartElement); ok {
+			return se.Name.Local, nil
+		}
+	}
+}
+
+func atomEntryLink(entry atomEntry) string {
+	for _, l := range entry.Links {
+		if l.Rel == "" || l.Rel == "alternate" {
+			return strings.TrimSpace(l.Href)
+		}
+	}
+	if len(entry.Links) > 0 {
+		return strings.TrimSpace(entry.Links[0].Href)
+	}
+	if strings.HasPrefix(entry.ID, "http") {
+		return strings.TrimSpace(entry.ID)
+	}
+	return ""
+}
+
+func publisherFor(feed config.RSSFeed, feedTitle string) string {
+	if feed.Publisher != "" {
+		return feed.Publisher
+	}
+	if t := cleanText(feedTitle); t != "" {
+		return t
+	}
+	return feed.Name
+}
+
+var (
+	tagPattern   = regexp.MustCompile(`(?s)<[^>]*>`)
+	spacePattern = regexp.MustCompile(`\s+`)
+)
+
+// cleanText strips markup and resolves entities. Some publishers escape
+// their descriptions twice (&amp;quot;), so entities are resolved until stable.
+func cleanText(s string) string {
+	for i := 0; i < 3; i++ {
+		unescaped := html.UnescapeString(s)
+		if unescaped == s {
+			break
+		}
+		s = unescaped
+	}
+	s = tagPattern.ReplaceAllString(s, " ")
+	s = strings.ReplaceAll(s, "\u00a0", " ")
+	s = spacePattern.ReplaceAllString(s, " ")
+	return strings.TrimSpace(s)
+}
+
+var dateLayouts = []string{
+	time.RFC1123Z,
+	time.RFC1123,
+	"Mon, 2 Jan 2006 15:04:05 -0700",
+	"Mon, 2 Jan 2006 15:04:05 MST",
+	"Mon, 02 Jan 2006 15:04 -0700",
+	"02 Jan 2006 15:04:05 -0700",
+	time.RFC822Z,
+	time.RFC822,
+	time.RFC3339,
+	"2006-01-02T15:04:05Z0700",
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+	"2006.01.02 15:04:05",
+	"2006.01.02 15:04",
+	"2006/01/02 15:04:05",
+	"2006-01-02",
+	"2006.01.02",
+}
+
+func parseDate(s string, fallback time.Time) time.Time {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return fallback
+	}
+	// Some feeds use "KST" which Go cannot resolve to an offset
+	s = strings.Replace(s, " KST", " +0900", 1)
+
+	// Timestamps without a zone are KST, Korean feeds mean local time
+	for _, layout := range dateLayouts {
+		t, err := time.ParseInLocation(layout, s, timeutil.KST)
+		if err == nil {
+			return t
+		}
+	}
+	return fallback
+}
@@ -0,0 +1,94 @@
+package rss
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"dx-unified/internal/shared/config"
+)
+
+const testRSS = `<?xml version="1.0" encoding="UTF-8"?>
+<rss version="2.0" xmlns:dc="http://purl.org/dc/elements/1.1/">
+<channel>
+<title>한국은행 보도자료</title>
+<item>
+  <title><![CDATA[기준금리 &amp; 통화정책방향]]></title>
+  <link>https://example.com/press/1</link>
+  <description>&lt;p&gt;금융통화위원회 결정&lt;/p&gt;</description>
+  <dc:date>2026-10-16T10:00:00+09:00</dc:date>
+  <dc:creator>공보관</dc:creator>
+</item>
+<item>
+  <title>no link</title>
+</item>
+</channel>
+</rss>`
+
+const testAtom = `<?xml version="1.0" encoding="UTF-8"?>
+<feed xmlns="http://www.w3.org/2005/Atom">
+<title>Exchange notices</title>
+<entry>
+  <id>tag:example.com,2026:2</id>
+  <title>Trading halt</title>
+  <link rel="alternate" href="https://example.com/notice/2"/>
+  <summary>Halted pending disclosure</summary>
+  <updated>2026-10-16T01:00:00Z</updated>
+  <author><name>Market Operations</name></author>
+</entry>
+</feed>`
+
+// TestDecodeRaw checks that archived items convert to the same article as
+// the fetched feed
+func TestDecodeRaw(t *testing.T) {
+	c := New(&config.Config{})
+	tests := []struct {
+		name string
+		body string
+		want string
+	}{
+		{"rss", testRSS, "https://example.com/press/1"},
+		{"atom", testAtom, "https://example.com/notice/2"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			articles, err := parseFeed([]byte(tt.body), config.RSSFeed{Name: "feed"})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(articles) != 1 || articles[0].URL != tt.want {
+				t.Fatalf("parseFeed = %+v", articles)
+			}
+			fetched := articles[0]
+			if len(fetched.Raw) == 0 {
+				t.Fatal("article has no raw item")
+			}
+
+			got, err := c.DecodeRaw(fetched.Raw, fetched.FetchedAt)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, fetched) {
+				t.Errorf("DecodeRaw =\n%+v\nwant\n%+v", got, fetched)
+			}
+		})
+	}
+}
+
+func TestDecodeRawDCDate(t *testing.T) {
+	articles, err := parseFeed([]byte(testRSS), config.RSSFeed{Name: "bok"})
+	if err != nil || len(articles) != 1 {
+		t.Fatalf("parseFeed = %v, %v", articles, err)
+	}
+	got, err := New(&config.Config{}).DecodeRaw(articles[0].Raw, time.Time{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := time.Date(2026, 10, 16, 1, 0, 0, 0, time.UTC)
+	if !got.PublishedAt.Equal(want) || got.RawProviderData["author"] != "공보관" || got.Publisher != "한국은행 보도자료" {
+		t.Errorf("DecodeRaw = %+v", got)
+	}
+	if got.Title != "기준금리 & 통화정책방향" || got.Summary != "금융통화위원회 결정" {
+		t.Errorf("title %q, summary %q", got.Title, got.Summary)
+	}
+}
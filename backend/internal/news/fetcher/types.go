@@ -1,21 +1,69 @@
 package fetcher
 
-import "time"
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+)
 
 // Article represents a normalized news article from any source
 type Article struct {
 	Title           string                 `json:"title"`
-	Summary         string                 `json:"summary"` // description
+	Summary         string                 `json:"summary"`        // description
+	Body            string                 `json:"body,omitempty"` // full text, filled by the optional extraction stage
 	URL             string                 `json:"url"`
 	CanonicalURL    string                 `json:"canonical_url"`
 	Source          string                 `json:"source"` // e.g., "naver", "newsapi"
 	Publisher       string                 `json:"publisher,omitempty"`
 	PublishedAt     time.Time              `json:"published_at"`
 	FetchedAt       time.Time              `json:"fetched_at"`
+	Tags            []string               `json:"tags,omitempty"` // from the registry query or feed that found it
 	RawProviderData map[string]interface{} `json:"raw_provider_data,omitempty"`
+
+	// Raw is the provider's item as received, kept by the archive so
+	// reprocessing can rebuild the article (see RawDecoder)
+	Raw json.RawMessage `json:"-"`
 }
 
+// Fetcher fetches articles from one provider. Implementations must stop
+// promptly when ctx is cancelled and may return partial results together
+// with an error.
 type Fetcher interface {
-	Fetch() ([]Article, error)
+	Fetch(ctx context.Context) ([]Article, error)
 	Name() string
 }
+
+// HTTPFetcher is implemented by fetchers whose HTTP client can be wrapped,
+// e.g. to meter requests against a quota
+type HTTPFetcher interface {
+	Fetcher
+	HTTPClient() *http.Client
+}
+
+// RawDecoder is implemented by fetchers that can rebuild an article from
+// its archived Article.Raw, so reprocessing picks up conversion changes.
+// fetchedAt replaces the current time wherever the conversion falls back to it.
+type RawDecoder interface {
+	Fetcher
+	DecodeRaw(raw json.RawMessage, fetchedAt time.Time) (Article, error)
+}
+
+// Target is one search query or feed of a fetch run, as configured in the
+// news source registry
+type Target struct {
+	Key       string   // cursor key: the registry query ID, or the query text or feed name
+	Query     string   // search sources
+	URL       string   // feeds
+	Publisher string   // feeds, falls back to the feed title when empty
+	Language  string   // ISO 639-1, used by providers that filter on it
+	Tags      []string // added to every article fetched for the target
+}
+
+// TargetFetcher is implemented by fetchers whose queries or feeds are
+// managed at runtime. The processor passes the targets due in each run;
+// cursors are keyed by Target.Key as in CursorFetcher.
+type TargetFetcher interface {
+	Fetcher
+	FetchTargets(ctx context.Context, targets []Target, cursors map[string]Cursor) ([]Article, map[string]Cursor, error)
+}
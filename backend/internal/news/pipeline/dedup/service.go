@@ -5,17 +5,17 @@ import (
 	"encoding/hex"
 
 	"dx-unified/internal/news/fetcher"
-	"dx-unified/internal/news/store/meili"
+	"dx-unified/internal/news/store"
 	"dx-unified/internal/shared/config"
 )
 
 type Service struct {
 	cfg   *config.Config
-	store *meili.Store
+	store store.NewsStore
 }
 
-func NewService(cfg *config.Config, store *meili.Store) *Service {
-	return &Service{cfg: cfg, store: store}
+func NewService(cfg *config.Config, newsStore store.NewsStore) *Service {
+	return &Service{cfg: cfg, store: newsStore}
 }
 
 // IsDuplicate checks if the article is a duplicate.
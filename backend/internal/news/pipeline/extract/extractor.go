@@ -0,0 +1,238 @@
+package extract
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"dx-unified/internal/news/store"
+	"dx-unified/internal/shared/config"
+
+	"github.com/PuerkitoBio/goquery"
+	"golang.org/x/net/html/charset"
+)
+
+const (
+	userAgent = "Mozilla/5.0 (compatible; dx-unified-news/1.0)"
+
+	maxWorkers   = 4
+	maxPageBytes = 3 << 20 // 3MB
+	maxBodyRunes = 20000
+	minBodyRunes = 100
+
+	// Recently extracted URLs are not fetched again; Naver returns the same
+	// top 100 results for a query across many runs.
+	seenCapacity = 5000
+)
+
+// Body extraction states recorded on the stored document
+const (
+	StatusOK     = "ok"
+	StatusFailed = "failed"
+)
+
+// Extractor fetches article pages politely and pulls out the main body text
+type Extractor struct {
+	cfg        *config.Config
+	httpClient *http.Client
+
+	mu       sync.Mutex
+	hostNext map[string]time.Time // earliest time the next request to a host may start
+	seen     map[string]bool
+	seenKeys []string
+}
+
+// New creates a new Extractor
+func New(cfg *config.Config) *Extractor {
+	return &Extractor{
+		cfg:        cfg,
+		httpClient: &http.Client{Timeout: 15 * time.Second},
+		hostNext:   make(map[string]time.Time),
+		seen:       make(map[string]bool),
+	}
+}
+
+// Enrich fills Body, BodyStatus and BodyError of the given documents in
+// place. Documents with a body, and URLs extracted in an earlier run, are
+// skipped. Failures never abort the batch; a failed URL is tried again the
+// next time it is fetched.
+func (e *Extractor) Enrich(ctx context.Context, docs []store.ArticleDoc) {
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+
+	for w := 0; w < maxWorkers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				d := &docs[i]
+				body, err := e.Extract(ctx, d.URL)
+				if err != nil {
+					d.BodyStatus, d.BodyError = StatusFailed, err.Error()
+					continue
+				}
+				d.Body, d.BodyStatus = body, StatusOK
+				e.markSeen(d.URL)
+			}
+		}()
+	}
+
+	queued := make(map[string]bool)
+	for i := range docs {
+		if ctx.Err() != nil {
+			break
+		}
+		u := docs[i].URL
+		if docs[i].Body != "" || u == "" || queued[u] || e.wasSeen(u) {
+			continue
+		}
+		queued[u] = true
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+}
+
+// Extract downloads a single page and returns its main text
+func (e *Extractor) Extract(ctx context.Context, rawURL string) (string, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Host == "" {
+		return "", fmt.Errorf("invalid url: %s", rawURL)
+	}
+
+	if err := e.waitForHost(ctx, u.Host); err != nil {
+		return "", err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", rawURL, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("User-Agent", userAgent)
+	req.Header.Set("Accept", "text/html,application/xhtml+xml")
+	req.Header.Set("Accept-Language", "ko-KR,ko;q=0.9,en;q=0.8")
+
+	resp, err := e.httpClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("status %d", resp.StatusCode)
+	}
+
+	contentType := resp.Header.Get("Content-Type")
+	if contentType != "" && !strings.Contains(contentType, "html") {
+		return "", fmt.Errorf("unsupported content type: %s", contentType)
+	}
+
+	// Converts EUC-KR/CP949 and other legacy charsets to UTF-8, using the
+	// Content-Type header first and <meta charset> sniffing otherwise
+	reader, err := charset.NewReader(io.LimitReader(resp.Body, maxPageBytes), contentType)
+	if err != nil {
+		return "", fmt.Errorf("charset: %w", err)
+	}
+
+	doc, err := goquery.NewDocumentFromReader(reader)
+	if err != nil {
+		return "", fmt.Errorf("parse html: %w", err)
+	}
+
+	// Redirects (e.g. Naver mobile links) may land on a different host
+	host := resp.Request.URL.Host
+
+	var text string
+	if selector := e.selectorFor(host); selector != "" {
+		text = textOf(doc.Find(selector).First())
+	}
+	if runeLen(text) < minBodyRunes {
+		text = Readability(doc)
+	}
+
+	if runeLen(text) < minBodyRunes {
+		return "", fmt.Errorf("no article body found")
+	}
+
+	return truncateRunes(text, maxBodyRunes), nil
+}
+
+func (e *Extractor) selectorFor(host string) string {
+	host = strings.ToLower(host)
+	if s, ok := e.cfg.NewsBodySelectors[host]; ok {
+		return s
+	}
+	// Allow "hankyung.com" to cover "www.hankyung.com" and subdomains
+	for h, s := range e.cfg.NewsBodySelectors {
+		if strings.HasSuffix(host, "."+strings.TrimPrefix(h, "www.")) {
+			return s
+		}
+	}
+	return ""
+}
+
+// waitForHost enforces the configured delay between requests to one host
+func (e *Extractor) waitForHost(ctx context.Context, host string) error {
+	delay := time.Duration(e.cfg.NewsBodyHostDelay) * time.Millisecond
+
+	e.mu.Lock()
+	now := time.Now()
+	next := e.hostNext[host]
+	if next.Before(now) {
+		next = now
+	}
+	e.hostNext[host] = next.Add(delay)
+	e.mu.Unlock()
+
+	if wait := time.Until(next); wait > 0 {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(wait):
+		}
+	}
+	return nil
+}
+
+func (e *Extractor) wasSeen(u string) bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.seen[u]
+}
+
+// markSeen remembers a URL whose body was extracted
+func (e *Extractor) markSeen(u string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.seen[u] {
+		return
+	}
+	e.seen[u] = true
+	e.seenKeys = append(e.seenKeys, u)
+
+	if len(e.seenKeys) > seenCapacity {
+		drop := len(e.seenKeys) - seenCapacity
+		for _, k := range e.seenKeys[:drop] {
+			delete(e.seen, k)
+		}
+		e.seenKeys = e.seenKeys[drop:]
+	}
+}
+
+func runeLen(s string) int {
+	return len([]rune(s))
+}
+
+func truncateRunes(s string, max int) string {
+	r := []rune(s)
+	if len(r) <= max {
+		return s
+	}
+	return string(r[:max])
+}
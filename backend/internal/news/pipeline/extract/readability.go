@@ -0,0 +1,190 @@
+package extract
+
+import (
+	"math"
+	"regexp"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+	"golang.org/x/net/html"
+)
+
+// Readability-style scoring, simplified from the Arc90 algorithm. Korean news
+// sites often put the body directly in a <div> separated by <br>, so divs with
+// their own text are scored like paragraphs.
+
+var (
+	unlikelyPattern = regexp.MustCompile(`(?i)comment|footer|sidebar|share|sns|related|recommend|popular|rank|advert|banner|\bad[-_]|promo|subscribe|copyright|reporter|byline|breadcrumb|menu|nav|popup|modal|widget|tag-list`)
+	positivePattern = regexp.MustCompile(`(?i)article|body|content|entry|main|news|post|text|story|view|txt|cont`)
+	negativePattern = regexp.MustCompile(`(?i)hidden|comment|footer|sidebar|share|sns|related|advert|banner|promo|meta|outbrain|photo|caption`)
+)
+
+const (
+	noiseSelector     = "script, style, noscript, iframe, form, button, svg, nav, header, footer, aside, figure, figcaption, select, input, textarea"
+	candidateSelector = "p, div, section, article, td, pre"
+	minParagraphRunes = 25
+)
+
+var blockElements = map[string]bool{
+	"p": true, "div": true, "section": true, "article": true, "br": true,
+	"h1": true, "h2": true, "h3": true, "h4": true, "h5": true, "h6": true,
+	"li": true, "ul": true, "ol": true, "table": true, "tr": true, "td": true,
+	"blockquote": true, "pre": true,
+}
+
+var inlineElements = map[string]bool{
+	"span": true, "b": true, "strong": true, "em": true, "i": true,
+	"a": true, "font": true, "u": true, "mark": true, "sup": true, "sub": true,
+}
+
+// Readability returns the main text of the document, or "" when no
+// candidate scores high enough
+func Readability(doc *goquery.Document) string {
+	doc.Find(noiseSelector).Remove()
+	doc.Find("*").Each(func(_ int, s *goquery.Selection) {
+		if s.Is("html, body, article") {
+			return
+		}
+		id, _ := s.Attr("id")
+		class, _ := s.Attr("class")
+		if unlikelyPattern.MatchString(id+" "+class) && !positivePattern.MatchString(id) {
+			s.Remove()
+		}
+	})
+
+	scores := make(map[*html.Node]float64)
+	nodes := make(map[*html.Node]*goquery.Selection)
+
+	addScore := func(s *goquery.Selection, score float64) {
+		if s.Length() == 0 {
+			return
+		}
+		n := s.Get(0)
+		if _, ok := scores[n]; !ok {
+			scores[n] = classWeight(s)
+			nodes[n] = s
+		}
+		scores[n] += score
+	}
+
+	doc.Find(candidateSelector).Each(func(_ int, s *goquery.Selection) {
+		text := ownText(s)
+		length := len([]rune(text))
+		if length < minParagraphRunes {
+			return
+		}
+
+		score := 1 + float64(strings.Count(text, ",")+strings.Count(text, "，")+strings.Count(text, "다.")) +
+			math.Min(float64(length)/100, 3)
+
+		if s.Is("p, pre, td") {
+			addScore(s.Parent(), score)
+			addScore(s.Parent().Parent(), score/2)
+		} else {
+			addScore(s, score)
+			addScore(s.Parent(), score/2)
+		}
+	})
+
+	var best *html.Node
+	bestScore := 0.0
+	for n, score := range scores {
+		score *= 1 - linkDensity(nodes[n])
+		if score > bestScore {
+			best, bestScore = n, score
+		}
+	}
+	if best == nil {
+		return ""
+	}
+
+	return textOf(nodes[best])
+}
+
+func classWeight(s *goquery.Selection) float64 {
+	weight := 0.0
+	for _, attr := range []string{"id", "class"} {
+		v, ok := s.Attr(attr)
+		if !ok || v == "" {
+			continue
+		}
+		if negativePattern.MatchString(v) {
+			weight -= 25
+		}
+		if positivePattern.MatchString(v) {
+			weight += 25
+		}
+	}
+	return weight
+}
+
+func linkDensity(s *goquery.Selection) float64 {
+	total := len([]rune(s.Text()))
+	if total == 0 {
+		return 0
+	}
+	links := 0
+	s.Find("a").Each(func(_ int, a *goquery.Selection) {
+		links += len([]rune(a.Text()))
+	})
+	return float64(links) / float64(total)
+}
+
+// ownText is the text of direct text children and inline elements, so a
+// container div does not get credit for its nested paragraphs
+func ownText(s *goquery.Selection) string {
+	var sb strings.Builder
+	for _, n := range s.Nodes {
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			switch {
+			case c.Type == html.TextNode:
+				sb.WriteString(c.Data)
+			case c.Type == html.ElementNode && inlineElements[c.Data]:
+				sb.WriteString(goquery.NewDocumentFromNode(c).Text())
+			}
+		}
+	}
+	return strings.TrimSpace(sb.String())
+}
+
+// textOf renders a selection as plain text, one line per block element
+func textOf(s *goquery.Selection) string {
+	if s.Length() == 0 {
+		return ""
+	}
+
+	var sb strings.Builder
+	var walk func(n *html.Node)
+	walk = func(n *html.Node) {
+		switch n.Type {
+		case html.TextNode:
+			sb.WriteString(n.Data)
+			return
+		case html.ElementNode:
+			if n.Data == "script" || n.Data == "style" {
+				return
+			}
+			if blockElements[n.Data] {
+				sb.WriteString("\n")
+			}
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+		if n.Type == html.ElementNode && blockElements[n.Data] {
+			sb.WriteString("\n")
+		}
+	}
+	for _, n := range s.Nodes {
+		walk(n)
+	}
+
+	var lines []string
+	for _, line := range strings.Split(sb.String(), "\n") {
+		line = strings.Join(strings.Fields(line), " ")
+		if line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
@@ -1,37 +1,121 @@
 package pipeline
 
 import (
+	"context"
 	"fmt"
 	"log"
+	"path/filepath"
+	"slices"
+	"sync"
 	"time"
 
+	"dx-unified/internal/news/archive"
 	"dx-unified/internal/news/fetcher"
 	"dx-unified/internal/news/pipeline/dedup"
-	"dx-unified/internal/news/store/meili"
+	"dx-unified/internal/news/pipeline/extract"
+	"dx-unified/internal/news/quota"
+	"dx-unified/internal/news/registry"
+	"dx-unified/internal/news/sink"
+	"dx-unified/internal/news/store"
+	"dx-unified/internal/news/trending"
 	"dx-unified/internal/shared/config"
 
 	"github.com/google/uuid"
 )
 
 type Processor struct {
-	cfg      *config.Config
-	fetchers []fetcher.Fetcher
-	filter   *Filter
-	dedupSvc *dedup.Service
-	store    *meili.Store
+	cfg       *config.Config
+	fetchers  []fetcher.Fetcher
+	filter    *Filter
+	dedupSvc  *dedup.Service
+	extractor *extract.Extractor // nil when body extraction is disabled
+	cursors   fetcher.CursorStore
+	archive   *archive.Archive
+	trends    *trending.Tracker  // optional, counts terms of stored articles
+	quota     *quota.Manager     // optional, request budgets per source
+	registry  *registry.Registry // optional, runtime queries and feeds
+	parquet   *sink.Parquet      // optional, analytics copy of processed articles
+	store     store.NewsStore
+
+	stories  *storyIndex // recent titles for story assignment
+	seedOnce sync.Once   // loads the recent stories on the first run
+
+	reprocessMu   sync.Mutex
+	jobsMu        sync.Mutex
+	reprocessJobs []*ReprocessJob // oldest first, at most maxReprocessJobs
 }
 
-func NewProcessor(cfg *config.Config, store *meili.Store, fetchers []fetcher.Fetcher) *Processor {
-	return &Processor{
+func NewProcessor(cfg *config.Config, newsStore store.NewsStore, fetchers []fetcher.Fetcher) *Processor {
+	p := &Processor{
 		cfg:      cfg,
 		fetchers: fetchers,
-		store:    store,
+		store:    newsStore,
 		filter:   NewFilter(cfg),
-		dedupSvc: dedup.NewService(cfg, store),
+		dedupSvc: dedup.NewService(cfg, newsStore),
+		cursors:  fetcher.NewFileCursorStore(cfg.NewsCursorPath),
+		archive:  archive.New(filepath.Join(cfg.StorageDir, "news", "raw")),
+		stories:  newStoryIndex(),
+	}
+	if cfg.NewsBodyExtraction {
+		p.extractor = extract.New(cfg)
+	}
+	return p
+}
+
+// SetTrendTracker enables term counting for trending keywords
+func (p *Processor) SetTrendTracker(t *trending.Tracker) {
+	p.trends = t
+}
+
+// SetQuota enables request budgets: requests of HTTP fetchers are metered
+// and fetchers over budget are skipped
+func (p *Processor) SetQuota(q *quota.Manager) {
+	p.quota = q
+	for _, f := range p.fetchers {
+		if hf, ok := f.(fetcher.HTTPFetcher); ok {
+			client := hf.HTTPClient()
+			client.Transport = q.Transport(f.Name(), client.Transport)
+		}
+	}
+}
+
+// SetRegistry makes fetchers that support it run the queries and feeds of
+// the registry instead of the configured ones. Changes apply from the next run.
+func (p *Processor) SetRegistry(r *registry.Registry) {
+	p.registry = r
+}
+
+// SetParquetSink appends every stored article to a Parquet dataset for
+// analytics in DuckDB
+func (p *Processor) SetParquetSink(s *sink.Parquet) {
+	p.parquet = s
+}
+
+// writeParquet copies stored articles to the Parquet sink. Failures are
+// logged only; the store stays the source of truth.
+func (p *Processor) writeParquet(runID string, docs []store.ArticleDoc) {
+	if p.parquet == nil || len(docs) == 0 {
+		return
+	}
+	if err := p.parquet.Write(runID, docs); err != nil {
+		log.Printf("[Run %s] Failed to write articles to parquet: %v", runID, err)
 	}
 }
 
-func (p *Processor) Run() {
+// fetchResult is the outcome of one fetcher within a run
+type fetchResult struct {
+	articles []fetcher.Article
+	cursors  map[string]fetcher.Cursor
+	targets  []fetcher.Target // registry targets that were run, nil without the registry
+	started  time.Time
+	err      error
+	latency  time.Duration
+	skipped  string // why the registry or the quota did not let the fetcher run
+}
+
+// Run fetches from all fetchers concurrently and stores the results.
+// Cancelling ctx (server shutdown) aborts in-flight fetches.
+func (p *Processor) Run(ctx context.Context) {
 	runID := uuid.New().String()
 	startTime := time.Now()
 
@@ -44,54 +128,63 @@ func (p *Processor) Run() {
 	// totalFiltered := 0
 	totalStored := 0
 	totalDups := 0
+	var stored []store.ArticleDoc
 
-	for _, f := range p.fetchers {
-		log.Printf("[Run %s] Fetching from %s...", runID, f.Name())
-		articles, err := f.Fetch()
-		if err != nil {
-			msg := fmt.Sprintf("Fetcher %s error: %v", f.Name(), err)
+	p.seedOnce.Do(p.seedStories)
+	results := p.fetchAll(ctx, runID)
+
+	for i, f := range p.fetchers {
+		res := results[i]
+		articles, nextCursors := res.articles, res.cursors
+
+		if res.skipped != "" {
+			stats[f.Name()] = map[string]interface{}{
+				"fetched": 0,
+				"saved":   0,
+				"skipped": res.skipped,
+			}
+			continue
+		}
+
+		sourceErrors := 0
+		if res.err != nil {
+			// Partial results are still processed, also when the run was
+			// cancelled; cursors for failed queries are left unchanged by
+			// the fetcher
+			msg := fmt.Sprintf("Fetcher %s error: %v", f.Name(), res.err)
 			log.Println(msg)
 			allErrors = append(allErrors, msg)
+			sourceErrors++
+		}
+
+		if len(articles) == 0 {
+			p.markRun(runID, f, res)
+			stats[f.Name()] = map[string]int{
+				"fetched":    0,
+				"saved":      0,
+				"errors":     sourceErrors,
+				"latency_ms": int(res.latency.Milliseconds()),
+			}
 			continue
 		}
 
 		sourceFetched := len(articles)
 		totalFetched += sourceFetched
 
-		var docsToSave []meili.ArticleDoc
+		// Keep the provider payload so history can be reprocessed later
+		if err := p.archive.Write(runID, f.Name(), startTime, articles); err != nil {
+			log.Printf("[Run %s] Failed to archive raw articles for %s: %v", runID, f.Name(), err)
+		}
+
+		var docsToSave []store.ArticleDoc
 
 		for _, a := range articles {
-			// 1. Normalize
-			NormalizeArticle(&a)
-			// passed, reason := p.filter.IsEconomicOrStockNews(&a)
-			// if !passed {
-			// 	log.Printf("[Run %s] Filtered out article '%s': %s", runID, a.Title, reason)
-			// 	continue
-			// }
-
-			// 3. Dedup
-			isDup, dupOf, score := p.dedupSvc.IsDuplicate(&a)
-			dupState := "unique"
-			if isDup {
-				dupState = "duplicate"
-				totalDups++
+			doc, ok := p.buildDoc(a, p.stories)
+			if !ok {
+				continue
 			}
-
-			// 4. Transform to Doc
-			doc := meili.ArticleDoc{
-				ID:           GenerateID(a.CanonicalURL),
-				Title:        a.Title,
-				Summary:      a.Summary,
-				URL:          a.URL,
-				CanonicalURL: a.CanonicalURL,
-				Source:       a.Source,
-				Publisher:    a.Publisher,
-				PublishedAt:  a.PublishedAt,
-				FetchedAt:    a.FetchedAt,
-				DupState:     dupState,
-				DupOf:        dupOf,
-				DupScore:     score,
-				Tags:         []string{}, // TODO: tagger
+			if doc.DupState == "duplicate" {
+				totalDups++
 			}
 
 			// If Duplicate, we might still save it with dup_state=duplicate (as per plan/requirements: "Record discarded items in runs or store with duplicate state")
@@ -100,25 +193,79 @@ func (p *Processor) Run() {
 			totalStored++
 		}
 
+		// Optional full-text enrichment of the articles that passed the
+		// filter and are not duplicates; failures are recorded per article
+		bodyExtracted, bodyFailed := 0, 0
+		if p.extractor != nil {
+			var unique []int
+			var toEnrich []store.ArticleDoc
+			for i, doc := range docsToSave {
+				if doc.DupState != "duplicate" {
+					unique = append(unique, i)
+					toEnrich = append(toEnrich, doc)
+				}
+			}
+			p.extractor.Enrich(ctx, toEnrich)
+			for j, i := range unique {
+				docsToSave[i] = toEnrich[j]
+				switch toEnrich[j].BodyStatus {
+				case extract.StatusOK:
+					bodyExtracted++
+				case extract.StatusFailed:
+					bodyFailed++
+				}
+			}
+		}
+
 		if err := p.store.SaveArticles(docsToSave); err != nil {
 			msg := fmt.Sprintf("Store error for %s: %v", f.Name(), err)
 			log.Println(msg)
 			allErrors = append(allErrors, msg)
+			sourceErrors++
+		} else {
+			// Only advance high-water marks once the articles are stored
+			if len(nextCursors) > 0 {
+				if err := p.cursors.SaveCursors(f.Name(), nextCursors); err != nil {
+					log.Printf("[Run %s] Failed to save cursors for %s: %v", runID, f.Name(), err)
+				}
+			}
+			p.markRun(runID, f, res)
+			if p.trends != nil {
+				p.trends.Add(docsToSave)
+			}
+			stored = append(stored, docsToSave...)
 		}
 
-		stats[f.Name()] = map[string]int{
-			"fetched": sourceFetched,
-			"saved":   len(docsToSave),
+		sourceStats := map[string]int{
+			"fetched":    sourceFetched,
+			"saved":      len(docsToSave),
+			"errors":     sourceErrors,
+			"latency_ms": int(res.latency.Milliseconds()),
+		}
+		if p.extractor != nil {
+			sourceStats["body_extracted"] = bodyExtracted
+			sourceStats["body_failed"] = bodyFailed
+		}
+		stats[f.Name()] = sourceStats
+	}
+
+	p.writeParquet(runID, stored)
+
+	if p.trends != nil {
+		if err := p.trends.Save(); err != nil {
+			log.Printf("[Run %s] Failed to save trending counts: %v", runID, err)
 		}
 	}
 
 	endTime := time.Now()
 	status := "success"
-	if len(allErrors) > 0 {
+	if ctx.Err() != nil {
+		status = "cancelled"
+	} else if len(allErrors) > 0 {
 		status = "partial_failure" // or failed
 	}
 
-	runLog := &meili.RunLog{
+	runLog := &store.RunLog{
 		RunID:     runID,
 		StartedAt: startTime,
 		EndedAt:   endTime,
@@ -138,3 +285,185 @@ func (p *Processor) Run() {
 
 	log.Printf("[Run %s] Finished in %v. Stored: %d, Dups: %d", runID, endTime.Sub(startTime), totalStored, totalDups)
 }
+
+// buildDoc runs one article through normalise -> filter -> dedup -> story ->
+// tag -> sentiment, assigning the story from stories. It reports false when
+// the article is filtered out.
+func (p *Processor) buildDoc(a fetcher.Article, stories *storyIndex) (store.ArticleDoc, bool) {
+	// 1. Normalize
+	NormalizeArticle(&a)
+
+	// 2. Filter
+	// passed, reason := p.filter.IsEconomicOrStockNews(&a)
+	// if !passed {
+	// 	log.Printf("Filtered out article '%s': %s", a.Title, reason)
+	// 	return store.ArticleDoc{}, false
+	// }
+
+	// 3. Dedup
+	isDup, dupOf, score := p.dedupSvc.IsDuplicate(&a)
+	dupState := "unique"
+	if isDup {
+		dupState = "duplicate"
+	}
+
+	// 4. Story
+	id := GenerateID(a.CanonicalURL)
+	storyID := stories.assign(id, a.Title, a.PublishedAt)
+
+	// 5. Transform to Doc
+	return store.ArticleDoc{
+		ID:           id,
+		Title:        a.Title,
+		Summary:      a.Summary,
+		Body:         a.Body,
+		URL:          a.URL,
+		CanonicalURL: a.CanonicalURL,
+		Source:       a.Source,
+		Publisher:    a.Publisher,
+		PublishedAt:  a.PublishedAt,
+		FetchedAt:    a.FetchedAt,
+		DupState:     dupState,
+		DupOf:        dupOf,
+		DupScore:     score,
+		StoryID:      storyID,
+		Tags:         tagsOf(a),
+		Sentiment:    Sentiment(a.Title, a.Summary),
+	}, true
+}
+
+// tagsOf returns the registry tags of an article. TODO: tagger
+func tagsOf(a fetcher.Article) []string {
+	tags := []string{}
+	for _, t := range a.Tags {
+		if !slices.Contains(tags, t) {
+			tags = append(tags, t)
+		}
+	}
+	return tags
+}
+
+// fetchAll runs the fetchers with bounded parallelism, each under its own
+// timeout. Results are returned in fetcher order.
+func (p *Processor) fetchAll(ctx context.Context, runID string) []fetchResult {
+	results := make([]fetchResult, len(p.fetchers))
+
+	concurrency := p.cfg.NewsFetchConcurrency
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+
+	for i, f := range p.fetchers {
+		wg.Add(1)
+		go func(i int, f fetcher.Fetcher) {
+			defer wg.Done()
+
+			select {
+			case sem <- struct{}{}:
+				defer func() { <-sem }()
+			case <-ctx.Done():
+				results[i] = fetchResult{err: ctx.Err()}
+				return
+			}
+
+			var targets []fetcher.Target
+			if _, ok := f.(fetcher.TargetFetcher); ok && p.registry != nil {
+				var reason string
+				if targets, reason = p.registry.Targets(f.Name(), time.Now()); len(targets) == 0 {
+					log.Printf("[Run %s] Skipping %s: %s", runID, f.Name(), reason)
+					results[i] = fetchResult{skipped: reason}
+					return
+				}
+			}
+
+			if p.quota != nil {
+				if ok, reason := p.quota.Begin(f.Name()); !ok {
+					log.Printf("[Run %s] Skipping %s: %s", runID, f.Name(), reason)
+					results[i] = fetchResult{skipped: reason}
+					return
+				}
+			}
+
+			fctx, cancel := context.WithTimeout(ctx, p.timeoutFor(f.Name()))
+			defer cancel()
+
+			log.Printf("[Run %s] Fetching from %s...", runID, f.Name())
+			start := time.Now()
+			articles, cursors, err := p.fetch(fctx, f, targets)
+			if p.quota != nil {
+				if err := p.quota.End(f.Name(), err); err != nil {
+					log.Printf("[Run %s] Failed to save quota counters: %v", runID, err)
+				}
+			}
+			results[i] = fetchResult{
+				articles: articles,
+				cursors:  cursors,
+				targets:  targets,
+				started:  start,
+				err:      err,
+				latency:  time.Since(start),
+			}
+		}(i, f)
+	}
+
+	wg.Wait()
+	return results
+}
+
+// markRun advances the registry schedules of a fetcher's targets once its
+// articles are stored. A failed or timed out fetch leaves them due, so the
+// next run fetches their window again.
+func (p *Processor) markRun(runID string, f fetcher.Fetcher, res fetchResult) {
+	if res.targets == nil || res.err != nil {
+		return
+	}
+	if err := p.registry.MarkRun(f.Name(), res.targets, res.started); err != nil {
+		log.Printf("[Run %s] Failed to save source registry: %v", runID, err)
+	}
+}
+
+func (p *Processor) timeoutFor(name string) time.Duration {
+	if secs, ok := p.cfg.NewsFetcherTimeouts[name]; ok && secs > 0 {
+		return time.Duration(secs) * time.Second
+	}
+	if p.cfg.NewsFetchTimeout > 0 {
+		return time.Duration(p.cfg.NewsFetchTimeout) * time.Second
+	}
+	return 3 * time.Minute
+}
+
+// fetch runs a fetcher with the registry targets when given, otherwise
+// incrementally from its stored cursors when supported
+func (p *Processor) fetch(ctx context.Context, f fetcher.Fetcher, targets []fetcher.Target) ([]fetcher.Article, map[string]fetcher.Cursor, error) {
+	tf, isTarget := f.(fetcher.TargetFetcher)
+	cf, isCursor := f.(fetcher.CursorFetcher)
+	if !isCursor && (!isTarget || targets == nil) {
+		articles, err := f.Fetch(ctx)
+		return articles, nil, err
+	}
+
+	var cursors map[string]fetcher.Cursor
+	if isCursor {
+		var err error
+		cursors, err = p.cursors.LoadCursors(f.Name())
+		if err != nil {
+			// Without cursors the fetcher falls back to a full fetch
+			log.Printf("Failed to load cursors for %s: %v", f.Name(), err)
+			cursors = nil
+		}
+	}
+	if isTarget && targets != nil {
+		// Registry queries used to be keyed by their text
+		for _, t := range targets {
+			if _, ok := cursors[t.Key]; !ok && cursors != nil && t.Query != "" {
+				if c, ok := cursors[t.Query]; ok {
+					cursors[t.Key] = c
+				}
+			}
+		}
+		return tf.FetchTargets(ctx, targets, cursors)
+	}
+	return cf.FetchSince(ctx, cursors)
+}
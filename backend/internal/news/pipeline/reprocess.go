@@ -0,0 +1,370 @@
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"reflect"
+	"time"
+
+	"dx-unified/internal/news/archive"
+	"dx-unified/internal/news/fetcher"
+	"dx-unified/internal/news/store"
+
+	"github.com/google/uuid"
+)
+
+const (
+	reprocessBatchSize = 500
+	maxReportDiffs     = 200
+)
+
+// ErrReprocessRunning is returned when a reprocess is already in progress
+var ErrReprocessRunning = errors.New("reprocess already running")
+
+// ReprocessOptions selects the archived data to replay
+type ReprocessOptions struct {
+	From    time.Time
+	To      time.Time
+	Sources []string // empty means all sources
+	DryRun  bool     // compute the diff without writing
+}
+
+// FieldChange is a single field that differs from the stored document
+type FieldChange struct {
+	Old interface{} `json:"old"`
+	New interface{} `json:"new"`
+}
+
+// ArticleDiff describes how a reprocessed article differs from the store
+type ArticleDiff struct {
+	ID      string                 `json:"id"`
+	URL     string                 `json:"url"`
+	Status  string                 `json:"status"` // new, changed
+	Changes map[string]FieldChange `json:"changes,omitempty"`
+}
+
+// ReprocessReport summarises a reprocess run
+type ReprocessReport struct {
+	RunID     string        `json:"run_id"`
+	DryRun    bool          `json:"dry_run"`
+	Records   int           `json:"records"`  // archived records read
+	Articles  int           `json:"articles"` // distinct articles after the pipeline
+	New       int           `json:"new"`
+	Changed   int           `json:"changed"`
+	Unchanged int           `json:"unchanged"`
+	Saved     int           `json:"saved"`
+	Diffs     []ArticleDiff `json:"diffs,omitempty"` // first maxReportDiffs only
+	Errors    []string      `json:"errors,omitempty"`
+}
+
+// Reprocess job statuses
+const (
+	ReprocessRunning   = "running"
+	ReprocessCompleted = "completed"
+	ReprocessFailed    = "failed"
+)
+
+// How many finished reprocess jobs are kept for status queries
+const maxReprocessJobs = 20
+
+// ReprocessJob is a reprocess run in the background
+type ReprocessJob struct {
+	ID        string           `json:"id"` // run ID of the reprocess
+	From      string           `json:"from"`
+	To        string           `json:"to"`
+	Sources   []string         `json:"sources,omitempty"`
+	DryRun    bool             `json:"dry_run"`
+	Status    string           `json:"status"`
+	Error     string           `json:"error,omitempty"`
+	StartedAt time.Time        `json:"started_at"`
+	EndedAt   time.Time        `json:"ended_at,omitempty"`
+	Report    *ReprocessReport `json:"report,omitempty"`
+}
+
+// StartReprocess runs Reprocess in the background and returns the job,
+// whose progress ReprocessJob reports. Only one reprocess runs at a time.
+func (p *Processor) StartReprocess(ctx context.Context, opts ReprocessOptions) (*ReprocessJob, error) {
+	if !p.reprocessMu.TryLock() {
+		return nil, ErrReprocessRunning
+	}
+
+	job := &ReprocessJob{
+		ID:        uuid.New().String(),
+		From:      opts.From.Format("2006-01-02"),
+		To:        opts.To.Format("2006-01-02"),
+		Sources:   opts.Sources,
+		DryRun:    opts.DryRun,
+		Status:    ReprocessRunning,
+		StartedAt: time.Now(),
+	}
+	p.jobsMu.Lock()
+	p.reprocessJobs = append(p.reprocessJobs, job)
+	if len(p.reprocessJobs) > maxReprocessJobs {
+		p.reprocessJobs = p.reprocessJobs[1:]
+	}
+	started := *job
+	p.jobsMu.Unlock()
+
+	go func() {
+		defer p.reprocessMu.Unlock()
+		report, err := p.reprocess(ctx, job.ID, opts)
+
+		p.jobsMu.Lock()
+		defer p.jobsMu.Unlock()
+		job.EndedAt = time.Now()
+		job.Report = report
+		if err != nil {
+			job.Status = ReprocessFailed
+			job.Error = err.Error()
+		} else {
+			job.Status = ReprocessCompleted
+		}
+	}()
+	return &started, nil
+}
+
+// ReprocessJobs returns the recent reprocess jobs, newest first
+func (p *Processor) ReprocessJobs() []ReprocessJob {
+	p.jobsMu.Lock()
+	defer p.jobsMu.Unlock()
+	jobs := make([]ReprocessJob, 0, len(p.reprocessJobs))
+	for i := len(p.reprocessJobs) - 1; i >= 0; i-- {
+		jobs = append(jobs, *p.reprocessJobs[i])
+	}
+	return jobs
+}
+
+// ReprocessJob returns a recent reprocess job by ID
+func (p *Processor) ReprocessJob(id string) (ReprocessJob, bool) {
+	p.jobsMu.Lock()
+	defer p.jobsMu.Unlock()
+	for _, job := range p.reprocessJobs {
+		if job.ID == id {
+			return *job, true
+		}
+	}
+	return ReprocessJob{}, false
+}
+
+// Reprocess replays archived raw articles through the current pipeline and
+// upserts the results. Fields the pipeline does not produce (body, story)
+// are left as stored.
+func (p *Processor) Reprocess(ctx context.Context, opts ReprocessOptions) (*ReprocessReport, error) {
+	if !p.reprocessMu.TryLock() {
+		return nil, ErrReprocessRunning
+	}
+	defer p.reprocessMu.Unlock()
+	return p.reprocess(ctx, uuid.New().String(), opts)
+}
+
+func (p *Processor) reprocess(ctx context.Context, runID string, opts ReprocessOptions) (*ReprocessReport, error) {
+	startTime := time.Now()
+	report := &ReprocessReport{RunID: runID, DryRun: opts.DryRun}
+
+	log.Printf("[Reprocess %s] %s ~ %s (dry_run=%v)", runID,
+		opts.From.Format("2006-01-02"), opts.To.Format("2006-01-02"), opts.DryRun)
+
+	docs, order, records, err := p.replayArchive(ctx, opts.From, opts.To, opts.Sources)
+	report.Records = records
+	if err != nil {
+		return nil, fmt.Errorf("read archive: %w", err)
+	}
+	report.Articles = len(order)
+
+	var batch []store.ArticleDoc
+	flush := func() {
+		if len(batch) == 0 {
+			return
+		}
+		if err := p.store.SaveArticles(batch); err != nil {
+			report.Errors = append(report.Errors, fmt.Sprintf("save batch: %v", err))
+		} else {
+			report.Saved += len(batch)
+			p.writeParquet(runID, batch)
+		}
+		batch = batch[:0]
+	}
+
+	for _, id := range order {
+		if ctx.Err() != nil {
+			report.Errors = append(report.Errors, ctx.Err().Error())
+			break
+		}
+		doc := docs[id]
+
+		existing, err := p.store.GetArticle(id)
+		var diff *ArticleDiff
+		switch {
+		case errors.Is(err, store.ErrNotFound):
+			report.New++
+			diff = &ArticleDiff{ID: id, URL: doc.URL, Status: "new"}
+		case err != nil:
+			report.Errors = append(report.Errors, fmt.Sprintf("get %s: %v", id, err))
+			continue
+		default:
+			if changes := diffDocs(existing, &doc); len(changes) > 0 {
+				report.Changed++
+				diff = &ArticleDiff{ID: id, URL: doc.URL, Status: "changed", Changes: changes}
+			} else {
+				report.Unchanged++
+			}
+		}
+
+		if diff != nil && len(report.Diffs) < maxReportDiffs {
+			report.Diffs = append(report.Diffs, *diff)
+		}
+		if opts.DryRun || diff == nil {
+			continue
+		}
+
+		batch = append(batch, doc)
+		if len(batch) >= reprocessBatchSize {
+			flush()
+		}
+	}
+	if !opts.DryRun {
+		flush()
+
+		status := "success"
+		if len(report.Errors) > 0 {
+			status = "partial_failure"
+		}
+		runLog := &store.RunLog{
+			RunID:     runID,
+			StartedAt: startTime,
+			EndedAt:   time.Now(),
+			Status:    status,
+			Errors:    report.Errors,
+			Stats: map[string]interface{}{
+				"mode":      "reprocess",
+				"from":      opts.From.Format("2006-01-02"),
+				"to":        opts.To.Format("2006-01-02"),
+				"records":   report.Records,
+				"new":       report.New,
+				"changed":   report.Changed,
+				"unchanged": report.Unchanged,
+				"saved":     report.Saved,
+			},
+		}
+		if err := p.store.SaveRun(runLog); err != nil {
+			log.Printf("[Reprocess %s] Failed to save run log: %v", runID, err)
+		}
+	}
+
+	log.Printf("[Reprocess %s] Finished in %v. Records: %d, New: %d, Changed: %d, Saved: %d",
+		runID, time.Since(startTime), report.Records, report.New, report.Changed, report.Saved)
+
+	return report, nil
+}
+
+// replayArchive runs the archived records through the pipeline. Later
+// records of the same article replace earlier ones; order lists the IDs in
+// order of first appearance.
+func (p *Processor) replayArchive(ctx context.Context, from, to time.Time, sources []string) (map[string]store.ArticleDoc, []string, int, error) {
+	docs := make(map[string]store.ArticleDoc)
+	var order []string
+	records := 0
+	// Stories are rebuilt from the replayed range alone, in archive order
+	stories := newStoryIndex()
+
+	err := p.archive.Read(from, to, sources, func(rec archive.Record) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		records++
+
+		doc, ok := p.buildDoc(p.rebuildArticle(rec), stories)
+		if !ok {
+			return nil
+		}
+		if _, seen := docs[doc.ID]; !seen {
+			order = append(order, doc.ID)
+		}
+		docs[doc.ID] = doc
+		return nil
+	})
+	return docs, order, records, err
+}
+
+// rebuildArticle converts the archived provider item again with the
+// fetcher's current conversion. Records without one, or of sources that
+// cannot decode them, replay the archived Article.
+func (p *Processor) rebuildArticle(rec archive.Record) fetcher.Article {
+	if len(rec.Raw) == 0 {
+		return rec.Article
+	}
+	for _, f := range p.fetchers {
+		d, ok := f.(fetcher.RawDecoder)
+		if !ok || f.Name() != rec.Source {
+			continue
+		}
+		a, err := d.DecodeRaw(rec.Raw, rec.FetchedAt)
+		if err != nil {
+			log.Printf("[Reprocess] Failed to decode raw %s item of run %s: %v", rec.Source, rec.RunID, err)
+			return rec.Article
+		}
+		// Tags come from the query or feed that found the item
+		a.Tags = rec.Article.Tags
+		return a
+	}
+	return rec.Article
+}
+
+// ArchiveDocuments returns a store.DocumentSource that replays the archive
+// between from and to through the pipeline, used to rebuild a search index
+func (p *Processor) ArchiveDocuments(from, to time.Time) store.DocumentSource {
+	return func(ctx context.Context, fn func([]store.ArticleDoc) error) error {
+		docs, order, _, err := p.replayArchive(ctx, from, to, nil)
+		if err != nil {
+			return fmt.Errorf("read archive: %w", err)
+		}
+		for start := 0; start < len(order); start += reprocessBatchSize {
+			end := min(start+reprocessBatchSize, len(order))
+			batch := make([]store.ArticleDoc, 0, end-start)
+			for _, id := range order[start:end] {
+				batch = append(batch, docs[id])
+			}
+			if err := fn(batch); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+// diffDocs compares the fields the pipeline is responsible for
+func diffDocs(old, cur *store.ArticleDoc) map[string]FieldChange {
+	changes := make(map[string]FieldChange)
+	add := func(field string, o, n interface{}) {
+		if !reflect.DeepEqual(o, n) {
+			changes[field] = FieldChange{Old: o, New: n}
+		}
+	}
+
+	add("title", old.Title, cur.Title)
+	add("summary", old.Summary, cur.Summary)
+	add("url", old.URL, cur.URL)
+	add("canonical_url", old.CanonicalURL, cur.CanonicalURL)
+	add("source", old.Source, cur.Source)
+	add("publisher", old.Publisher, cur.Publisher)
+	add("dup_state", old.DupState, cur.DupState)
+	add("dup_of", old.DupOf, cur.DupOf)
+	add("tags", normalizeTags(old.Tags), normalizeTags(cur.Tags))
+	add("sentiment", old.Sentiment, cur.Sentiment)
+	add("story_id", old.StoryID, cur.StoryID)
+	// Stores keep second precision
+	if old.PublishedAt.Unix() != cur.PublishedAt.Unix() {
+		changes["published_at"] = FieldChange{Old: old.PublishedAt, New: cur.PublishedAt}
+	}
+	return changes
+}
+
+// normalizeTags treats nil and empty tag lists as equal
+func normalizeTags(tags []string) []string {
+	if len(tags) == 0 {
+		return []string{}
+	}
+	return tags
+}
@@ -0,0 +1,85 @@
+package pipeline
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Sentiment buckets of store.ArticleDoc
+const (
+	SentimentPositive = "positive"
+	SentimentNegative = "negative"
+	SentimentNeutral  = "neutral"
+)
+
+// Market lexicon of the sentiment scorer. Korean terms match as substrings
+// (particles attach to them), English terms as whole words. Terms must not
+// contain each other, or they are counted twice.
+var (
+	positiveKO = []string{
+		"상승", "급등", "강세", "반등", "호재", "호실적", "흑자", "최고치", "신고가",
+		"어닝서프라이즈", "수주", "상향", "개선", "돌파", "순매수", "선방", "훈풍",
+	}
+	negativeKO = []string{
+		"하락", "급락", "약세", "폭락", "악재", "부진", "적자", "신저가", "어닝쇼크",
+		"하향", "우려", "손실", "순매도", "리콜", "횡령", "배임", "파산", "부도",
+		"상장폐지", "제재", "한파",
+	}
+	positiveEN = words(
+		"surge", "surges", "surged", "soar", "soars", "soared", "jump", "jumps", "jumped",
+		"rally", "rallies", "rallied", "gain", "gains", "gained", "rise", "rises", "rose",
+		"beat", "beats", "upgrade", "upgraded", "profit", "profits", "growth",
+		"rebound", "rebounds", "rebounded", "outperform", "bullish", "strong",
+	)
+	negativeEN = words(
+		"plunge", "plunges", "plunged", "slump", "slumps", "slumped", "fall", "falls", "fell",
+		"drop", "drops", "dropped", "tumble", "tumbles", "tumbled", "miss", "misses", "missed",
+		"loss", "losses", "downgrade", "downgraded", "lawsuit", "recall", "bankruptcy",
+		"fraud", "decline", "declines", "declined", "bearish", "weak", "layoffs",
+	)
+)
+
+func words(list ...string) map[string]bool {
+	m := make(map[string]bool, len(list))
+	for _, w := range list {
+		m[w] = true
+	}
+	return m
+}
+
+// Sentiment scores an article with the market lexicon. Title terms count
+// twice; more positive than negative terms is positive and vice versa.
+func Sentiment(title, summary string) string {
+	score := 2*lexiconScore(title) + lexiconScore(summary)
+	switch {
+	case score > 0:
+		return SentimentPositive
+	case score < 0:
+		return SentimentNegative
+	default:
+		return SentimentNeutral
+	}
+}
+
+// lexiconScore is the number of positive minus negative terms in s
+func lexiconScore(s string) int {
+	score := 0
+	for _, term := range positiveKO {
+		score += strings.Count(s, term)
+	}
+	for _, term := range negativeKO {
+		score -= strings.Count(s, term)
+	}
+
+	fields := strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r)
+	})
+	for _, w := range fields {
+		if positiveEN[w] {
+			score++
+		} else if negativeEN[w] {
+			score--
+		}
+	}
+	return score
+}
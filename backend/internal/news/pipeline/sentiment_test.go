@@ -0,0 +1,27 @@
+package pipeline
+
+import "testing"
+
+func TestSentiment(t *testing.T) {
+	tests := []struct {
+		title, summary string
+		want           string
+	}{
+		{"삼성전자, 3분기 호실적에 주가 급등", "", SentimentPositive},
+		{"SK하이닉스 신고가 경신…외국인 순매수", "", SentimentPositive},
+		{"카카오 적자전환, 주가 급락", "", SentimentNegative},
+		{"코스피 하락 마감", "외국인 순매도 확대로 약세", SentimentNegative},
+		{"한국은행 기준금리 동결", "금융통화위원회는 기준금리를 연 3.0%로 유지했다.", SentimentNeutral},
+		// Title terms outweigh the summary
+		{"반도체주 반등", "우려는 여전하다", SentimentPositive},
+		{"Nvidia shares surge after earnings beat", "", SentimentPositive},
+		{"Stocks fell as oil prices slumped", "", SentimentNegative},
+		{"Fed holds rates steady", "", SentimentNeutral},
+		{"", "", SentimentNeutral},
+	}
+	for _, tt := range tests {
+		if got := Sentiment(tt.title, tt.summary); got != tt.want {
+			t.Errorf("Sentiment(%q, %q) = %s, want %s", tt.title, tt.summary, got, tt.want)
+		}
+	}
+}
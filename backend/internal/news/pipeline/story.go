@@ -0,0 +1,170 @@
+package pipeline
+
+import (
+	"log"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"dx-unified/internal/news/store"
+)
+
+// Articles about the same event, such as wire copies, rewrites by other
+// publishers and updates of a report, form a story. An article joins the
+// story of the most similar article published within storyWindow of it,
+// compared on the character bigrams of the titles (Dice coefficient, which
+// works for Korean without a morphological analyzer); otherwise it starts a
+// story whose ID is its own article ID.
+const (
+	storyWindow     = 48 * time.Hour
+	storySimilarity = 0.5
+	storySeedLimit  = 5000 // recent articles loaded from the store on start
+	storySeedPage   = 500
+)
+
+type storyEntry struct {
+	article string
+	story   string
+	grams   map[string]struct{}
+	at      time.Time
+}
+
+// storyIndex holds the titles of recent articles for story assignment
+type storyIndex struct {
+	mu      sync.Mutex
+	entries []storyEntry
+	byID    map[string]string // article ID -> story ID of the entries
+	latest  time.Time
+}
+
+func newStoryIndex() *storyIndex {
+	return &storyIndex{byID: make(map[string]string)}
+}
+
+// assign returns the story of an article and adds the article to the index.
+// An article seen before keeps its story.
+func (s *storyIndex) assign(articleID, title string, at time.Time) string {
+	grams := titleGrams(title)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if story, ok := s.byID[articleID]; ok {
+		return story
+	}
+	story, best := articleID, 0.0
+	for _, e := range s.entries {
+		if d := at.Sub(e.at); d > storyWindow || d < -storyWindow {
+			continue
+		}
+		if score := dice(grams, e.grams); score >= storySimilarity && score > best {
+			story, best = e.story, score
+		}
+	}
+	s.addLocked(articleID, story, grams, at)
+	return story
+}
+
+// add records an article with a known story, e.g. one loaded from the store
+func (s *storyIndex) add(articleID, story, title string, at time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.byID[articleID]; !ok {
+		s.addLocked(articleID, story, titleGrams(title), at)
+	}
+}
+
+func (s *storyIndex) addLocked(articleID, story string, grams map[string]struct{}, at time.Time) {
+	s.byID[articleID] = story
+	s.entries = append(s.entries, storyEntry{article: articleID, story: story, grams: grams, at: at})
+	if at.After(s.latest) {
+		s.latest = at
+	}
+
+	// Drop what no new article can join, once in a while
+	if len(s.entries)%1000 != 0 {
+		return
+	}
+	cutoff := s.latest.Add(-2 * storyWindow)
+	kept := s.entries[:0]
+	for _, e := range s.entries {
+		if e.at.After(cutoff) {
+			kept = append(kept, e)
+		} else {
+			delete(s.byID, e.article)
+		}
+	}
+	s.entries = kept
+}
+
+// titleGrams returns the character bigrams of a title without bracketed
+// labels such as [속보] or (종합), spaces and punctuation
+func titleGrams(title string) map[string]struct{} {
+	var runes []rune
+	depth := 0
+	for _, r := range strings.ToLower(title) {
+		switch r {
+		case '[', '(', '【', '<':
+			depth++
+			continue
+		case ']', ')', '】', '>':
+			if depth > 0 {
+				depth--
+			}
+			continue
+		}
+		if depth == 0 && (unicode.IsLetter(r) || unicode.IsDigit(r)) {
+			runes = append(runes, r)
+		}
+	}
+	grams := make(map[string]struct{}, len(runes))
+	for i := 0; i+1 < len(runes); i++ {
+		grams[string(runes[i:i+2])] = struct{}{}
+	}
+	return grams
+}
+
+func dice(a, b map[string]struct{}) float64 {
+	if len(a) == 0 || len(b) == 0 {
+		return 0
+	}
+	if len(a) > len(b) {
+		a, b = b, a
+	}
+	shared := 0
+	for g := range a {
+		if _, ok := b[g]; ok {
+			shared++
+		}
+	}
+	return 2 * float64(shared) / float64(len(a)+len(b))
+}
+
+// seedStories loads the articles of the last storyWindow from the store, so
+// articles fetched after a restart join the stories they belong to
+func (p *Processor) seedStories() {
+	from := time.Now().Add(-storyWindow)
+	for offset := 0; offset < storySeedLimit; offset += storySeedPage {
+		res, err := p.store.SearchArticles(&store.SearchQuery{
+			Filter: store.Filter{PublishedFrom: from},
+			Sort:   []string{"published_at:asc"},
+			Limit:  storySeedPage,
+			Offset: offset,
+		})
+		if err != nil {
+			log.Printf("[NEWS] Failed to load recent stories: %v", err)
+			return
+		}
+		for _, d := range res.Hits {
+			story := d.StoryID
+			if story == "" {
+				story = d.ID
+			}
+			p.stories.add(d.ID, story, d.Title, d.PublishedAt)
+		}
+		if len(res.Hits) < storySeedPage {
+			return
+		}
+	}
+}
@@ -0,0 +1,45 @@
+package pipeline
+
+import (
+	"testing"
+	"time"
+)
+
+func TestStoryAssign(t *testing.T) {
+	at := time.Date(2026, 10, 16, 9, 0, 0, 0, time.UTC)
+	articles := []struct {
+		id    string
+		title string
+		at    time.Time
+		story string
+	}{
+		{"a", "삼성전자, 3분기 영업이익 12조원…시장 예상 웃돌아", at, "a"},
+		{"b", "[속보] 삼성전자 3분기 영업이익 12조원, 시장 예상 웃돌아", at.Add(time.Minute), "a"},
+		{"c", "(종합) 삼성전자 3분기 영업이익 12조원 시장예상 웃돌았다", at.Add(time.Hour), "a"},
+		{"d", "SK하이닉스, HBM 공급 계약 체결", at.Add(2 * time.Hour), "d"},
+		{"e", "Samsung Electronics Q3 operating profit beats estimates", at, "e"},
+		{"f", "Samsung Electronics Q3 operating profit beats estimates, shares rise", at.Add(time.Hour), "e"},
+		// Same title, but long after the story
+		{"g", "삼성전자, 3분기 영업이익 12조원…시장 예상 웃돌아", at.Add(72 * time.Hour), "g"},
+		// Seen before: keeps its story
+		{"d", "SK하이닉스, HBM 공급 계약 체결", at.Add(3 * time.Hour), "d"},
+	}
+	s := newStoryIndex()
+	for _, a := range articles {
+		if got := s.assign(a.id, a.title, a.at); got != a.story {
+			t.Errorf("%s %q: story %q, want %q", a.id, a.title, got, a.story)
+		}
+	}
+}
+
+func TestStoryPrune(t *testing.T) {
+	s := newStoryIndex()
+	at := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	for i := 0; i < 3000; i++ {
+		s.assign(GenerateID(at.String()), "기사 "+at.String(), at)
+		at = at.Add(10 * time.Minute)
+	}
+	if len(s.entries) > 1000+2*int(storyWindow/(10*time.Minute)) || len(s.byID) != len(s.entries) {
+		t.Errorf("%d entries, %d IDs after pruning", len(s.entries), len(s.byID))
+	}
+}
@@ -0,0 +1,386 @@
+package quota
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"dx-unified/internal/shared/timeutil"
+)
+
+const (
+	// Above this share of a budget, runs are spread over the rest of the
+	// period instead of running on every cron tick
+	nearlyExhausted = 0.8
+
+	// Default pause after a 429 without Retry-After, for sources without a
+	// daily budget
+	rateLimitPause = 15 * time.Minute
+
+	errorWindowHours = 24
+
+	// Health thresholds
+	degradedErrorRate   = 0.2
+	minRequestsForRate  = 5
+	failingConsecutives = 3
+)
+
+type hourCount struct {
+	Requests    int `json:"requests"`
+	Errors      int `json:"errors"`
+	RateLimited int `json:"rate_limited"`
+}
+
+// sourceState is persisted so budgets survive restarts
+type sourceState struct {
+	Day           string               `json:"day"` // KST date DayRequests belongs to
+	DayRequests   int                  `json:"day_requests"`
+	Month         string               `json:"month"`
+	MonthRequests int                  `json:"month_requests"`
+	TotalRequests int64                `json:"total_requests"`
+	Hours         map[int64]*hourCount `json:"hours"` // outcomes over the last day
+
+	RunCost             float64   `json:"run_cost"` // moving average of requests per run
+	LastRunAt           time.Time `json:"last_run_at"`
+	LastSuccessAt       time.Time `json:"last_success_at"`
+	LastErrorAt         time.Time `json:"last_error_at"`
+	LastError           string    `json:"last_error,omitempty"`
+	ConsecutiveFailures int       `json:"consecutive_failures"`
+	BlockedUntil        time.Time `json:"blocked_until"` // set by an upstream 429
+
+	runStart int64 // TotalRequests when the current run began
+}
+
+// Manager tracks the requests each news source makes against its daily and
+// monthly budgets together with the upstream error rate. Fetchers are
+// metered through Transport; the processor asks Begin before each run.
+type Manager struct {
+	path    string
+	daily   map[string]int
+	monthly map[string]int
+
+	mu      sync.Mutex
+	sources map[string]*sourceState
+	saveMu  sync.Mutex // serializes writes of the snapshot file
+}
+
+// NewManager creates a manager persisted at path, loading any saved counters
+func NewManager(path string, daily, monthly map[string]int) *Manager {
+	m := &Manager{
+		path:    path,
+		daily:   daily,
+		monthly: monthly,
+		sources: make(map[string]*sourceState),
+	}
+	if data, err := os.ReadFile(path); err == nil {
+		var sources map[string]*sourceState
+		if err := json.Unmarshal(data, &sources); err == nil && sources != nil {
+			m.sources = sources
+		}
+	}
+	return m
+}
+
+// stateLocked returns the state of a source with its counters rolled over
+// to the current day and month
+func (m *Manager) stateLocked(source string, now time.Time) *sourceState {
+	st := m.sources[source]
+	if st == nil {
+		st = &sourceState{}
+		m.sources[source] = st
+	}
+	if st.Hours == nil {
+		st.Hours = make(map[int64]*hourCount)
+	}
+
+	local := now.In(timeutil.KST)
+	if day := local.Format("2006-01-02"); st.Day != day {
+		st.Day, st.DayRequests = day, 0
+	}
+	if month := local.Format("2006-01"); st.Month != month {
+		st.Month, st.MonthRequests = month, 0
+	}
+	oldest := now.Add(-errorWindowHours*time.Hour).Unix() / 3600 * 3600
+	for hour := range st.Hours {
+		if hour < oldest {
+			delete(st.Hours, hour)
+		}
+	}
+	return st
+}
+
+// Transport wraps base (http.DefaultTransport when nil) so every request
+// counts against the source's budgets and its outcome feeds the error rate
+func (m *Manager) Transport(source string, base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &meteredTransport{m: m, source: source, base: base}
+}
+
+type meteredTransport struct {
+	m      *Manager
+	source string
+	base   http.RoundTripper
+}
+
+func (t *meteredTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := t.base.RoundTrip(req)
+
+	status, retryAfter := 0, ""
+	if resp != nil {
+		status, retryAfter = resp.StatusCode, resp.Header.Get("Retry-After")
+	}
+	// Requests cut short by our own timeout or shutdown still count against
+	// the budget, but say nothing about the upstream
+	outcome := err
+	if err != nil && req.Context().Err() != nil {
+		outcome = nil
+	}
+	t.m.record(t.source, status, retryAfter, outcome)
+	return resp, err
+}
+
+func (m *Manager) record(source string, status int, retryAfter string, err error) {
+	now := time.Now()
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	st := m.stateLocked(source, now)
+	st.DayRequests++
+	st.MonthRequests++
+	st.TotalRequests++
+
+	hour := now.Unix() / 3600 * 3600
+	h := st.Hours[hour]
+	if h == nil {
+		h = &hourCount{}
+		st.Hours[hour] = h
+	}
+	h.Requests++
+
+	switch {
+	case err != nil:
+		h.Errors++
+		st.LastErrorAt, st.LastError = now, err.Error()
+	case status == http.StatusTooManyRequests:
+		h.Errors++
+		h.RateLimited++
+		st.LastErrorAt, st.LastError = now, http.StatusText(status)
+		pause := rateLimitPause
+		if m.daily[source] > 0 {
+			// A source with a daily quota is out of it until the reset
+			local := now.In(timeutil.KST)
+			pause = time.Date(local.Year(), local.Month(), local.Day()+1, 0, 0, 0, 0, timeutil.KST).Sub(now)
+		}
+		if secs, err := strconv.Atoi(retryAfter); err == nil && secs > 0 {
+			pause = time.Duration(secs) * time.Second
+		}
+		st.BlockedUntil = now.Add(pause)
+	case status >= 400:
+		h.Errors++
+		st.LastErrorAt, st.LastError = now, fmt.Sprintf("status %d", status)
+	}
+}
+
+// Begin decides whether a source may run now. A refused run returns the
+// reason; an allowed run must be followed by End.
+func (m *Manager) Begin(source string) (bool, string) {
+	now := time.Now()
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	st := m.stateLocked(source, now)
+	if _, ok, reason := m.decideLocked(source, st, now); !ok {
+		return false, reason
+	}
+	st.runStart = st.TotalRequests
+	st.LastRunAt = now
+	return true, ""
+}
+
+// End records the outcome of a run started with Begin and saves the counters
+func (m *Manager) End(source string, runErr error) error {
+	now := time.Now()
+
+	m.mu.Lock()
+	st := m.stateLocked(source, now)
+	cost := float64(st.TotalRequests - st.runStart)
+	if st.RunCost == 0 {
+		st.RunCost = cost
+	} else {
+		st.RunCost = 0.7*st.RunCost + 0.3*cost
+	}
+	if runErr != nil {
+		st.ConsecutiveFailures++
+		st.LastErrorAt, st.LastError = now, runErr.Error()
+	} else {
+		st.ConsecutiveFailures = 0
+		st.LastSuccessAt = now
+	}
+	m.mu.Unlock()
+
+	return m.Save()
+}
+
+// decideLocked applies the budgets. The state is one of ok, blocked,
+// exhausted or throttled.
+func (m *Manager) decideLocked(source string, st *sourceState, now time.Time) (string, bool, string) {
+	if now.Before(st.BlockedUntil) {
+		return "blocked", false, fmt.Sprintf("rate limited upstream until %s", st.BlockedUntil.In(timeutil.KST).Format("15:04"))
+	}
+
+	cost := math.Max(st.RunCost, 1)
+	local := now.In(timeutil.KST)
+	dayEnd := time.Date(local.Year(), local.Month(), local.Day()+1, 0, 0, 0, 0, timeutil.KST)
+	monthEnd := time.Date(local.Year(), local.Month()+1, 1, 0, 0, 0, 0, timeutil.KST)
+
+	checks := []struct {
+		period string
+		used   int
+		budget int
+		end    time.Time
+	}{
+		{"daily", st.DayRequests, m.daily[source], dayEnd},
+		{"monthly", st.MonthRequests, m.monthly[source], monthEnd},
+	}
+	for _, c := range checks {
+		if c.budget <= 0 {
+			continue
+		}
+		remaining := float64(c.budget - c.used)
+		if remaining < cost {
+			return "exhausted", false, fmt.Sprintf("%s budget exhausted (%d/%d)", c.period, c.used, c.budget)
+		}
+		if float64(c.used) < nearlyExhausted*float64(c.budget) {
+			continue
+		}
+
+		// Spread the remaining runs evenly until the budget resets
+		runsLeft := math.Floor(remaining / cost)
+		interval := time.Duration(float64(c.end.Sub(now)) / (runsLeft + 1))
+		if next := st.LastRunAt.Add(interval); now.Before(next) {
+			return "throttled", false, fmt.Sprintf("%s budget nearly exhausted (%d/%d), next run after %s",
+				c.period, c.used, c.budget, next.In(timeutil.KST).Format("01-02 15:04"))
+		}
+	}
+	return "ok", true, ""
+}
+
+// Health is the budget and error state of one source
+type Health struct {
+	Status              string    `json:"status"` // ok, degraded, failing, throttled, exhausted, blocked
+	Reason              string    `json:"reason,omitempty"`
+	RequestsToday       int       `json:"requests_today"`
+	DailyBudget         int       `json:"daily_budget,omitempty"`
+	RequestsMonth       int       `json:"requests_month"`
+	MonthlyBudget       int       `json:"monthly_budget,omitempty"`
+	Requests24h         int       `json:"requests_24h"`
+	Errors24h           int       `json:"errors_24h"`
+	RateLimited24h      int       `json:"rate_limited_24h"`
+	ErrorRate           float64   `json:"error_rate"` // errors / requests over the last 24h
+	AvgRunCost          float64   `json:"avg_run_cost"`
+	ConsecutiveFailures int       `json:"consecutive_failures"`
+	LastRunAt           time.Time `json:"last_run_at"`
+	LastSuccessAt       time.Time `json:"last_success_at"`
+	LastErrorAt         time.Time `json:"last_error_at"`
+	LastError           string    `json:"last_error,omitempty"`
+	BlockedUntil        time.Time `json:"blocked_until"`
+}
+
+// Report returns the health of every source seen or budgeted
+func (m *Manager) Report() map[string]Health {
+	now := time.Now()
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	names := make(map[string]bool)
+	for name := range m.sources {
+		names[name] = true
+	}
+	for name := range m.daily {
+		names[name] = true
+	}
+	for name := range m.monthly {
+		names[name] = true
+	}
+	sorted := make([]string, 0, len(names))
+	for name := range names {
+		sorted = append(sorted, name)
+	}
+	sort.Strings(sorted)
+
+	out := make(map[string]Health, len(sorted))
+	for _, name := range sorted {
+		st := m.stateLocked(name, now)
+		state, _, reason := m.decideLocked(name, st, now)
+
+		h := Health{
+			Status:              state,
+			Reason:              reason,
+			RequestsToday:       st.DayRequests,
+			DailyBudget:         m.daily[name],
+			RequestsMonth:       st.MonthRequests,
+			MonthlyBudget:       m.monthly[name],
+			AvgRunCost:          math.Round(st.RunCost*10) / 10,
+			ConsecutiveFailures: st.ConsecutiveFailures,
+			LastRunAt:           st.LastRunAt,
+			LastSuccessAt:       st.LastSuccessAt,
+			LastErrorAt:         st.LastErrorAt,
+			LastError:           st.LastError,
+			BlockedUntil:        st.BlockedUntil,
+		}
+		for _, c := range st.Hours {
+			h.Requests24h += c.Requests
+			h.Errors24h += c.Errors
+			h.RateLimited24h += c.RateLimited
+		}
+		if h.Requests24h > 0 {
+			h.ErrorRate = math.Round(float64(h.Errors24h)/float64(h.Requests24h)*1000) / 1000
+		}
+		if h.Status == "ok" {
+			switch {
+			case h.ConsecutiveFailures >= failingConsecutives:
+				h.Status = "failing"
+			case h.Requests24h >= minRequestsForRate && h.ErrorRate >= degradedErrorRate:
+				h.Status = "degraded"
+			}
+		}
+		out[name] = h
+	}
+	return out
+}
+
+// Save writes the counters to disk atomically. Fetchers finish in
+// parallel, so writes are serialized and the last one holds the newest
+// counters.
+func (m *Manager) Save() error {
+	m.saveMu.Lock()
+	defer m.saveMu.Unlock()
+
+	m.mu.Lock()
+	data, err := json.Marshal(m.sources)
+	m.mu.Unlock()
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(m.path), 0755); err != nil {
+		return err
+	}
+	tmp := m.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, m.path)
+}
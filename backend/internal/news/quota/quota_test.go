@@ -0,0 +1,120 @@
+package quota
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"dx-unified/internal/shared/timeutil"
+)
+
+func TestBudgetAccounting(t *testing.T) {
+	status := http.StatusOK
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status)
+	}))
+	defer srv.Close()
+
+	m := NewManager(filepath.Join(t.TempDir(), "quota.json"), map[string]int{"newsapi": 3}, nil)
+	client := &http.Client{Transport: m.Transport("newsapi", nil)}
+
+	if ok, reason := m.Begin("newsapi"); !ok {
+		t.Fatalf("first run refused: %s", reason)
+	}
+	for i := 0; i < 3; i++ {
+		resp, err := client.Get(srv.URL)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+	}
+	if err := m.End("newsapi", nil); err != nil {
+		t.Fatal(err)
+	}
+
+	h := m.Report()["newsapi"]
+	if h.RequestsToday != 3 || h.AvgRunCost != 3 {
+		t.Errorf("requests today = %d, run cost = %v; want 3, 3", h.RequestsToday, h.AvgRunCost)
+	}
+	if ok, _ := m.Begin("newsapi"); ok {
+		t.Errorf("run allowed with the daily budget used up")
+	}
+	if h.Status != "exhausted" {
+		t.Errorf("status = %q, want exhausted", h.Status)
+	}
+
+	// A 429 blocks the source regardless of the budget
+	status = http.StatusTooManyRequests
+	m2 := NewManager(filepath.Join(t.TempDir(), "quota.json"), nil, nil)
+	resp, err := (&http.Client{Transport: m2.Transport("naver", nil)}).Get(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if ok, _ := m2.Begin("naver"); ok {
+		t.Errorf("run allowed after a 429")
+	}
+	if h := m2.Report()["naver"]; h.RateLimited24h != 1 || h.Status != "blocked" {
+		t.Errorf("rate limited = %d, status = %q", h.RateLimited24h, h.Status)
+	}
+}
+
+// TestRateLimitDailyQuota checks that a 429 of a source with a daily quota
+// blocks it until the quota resets
+func TestRateLimitDailyQuota(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer srv.Close()
+
+	m := NewManager(filepath.Join(t.TempDir(), "quota.json"), map[string]int{"newsapi": 100}, nil)
+	resp, err := (&http.Client{Transport: m.Transport("newsapi", nil)}).Get(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	now := time.Now().In(timeutil.KST)
+	midnight := time.Date(now.Year(), now.Month(), now.Day()+1, 0, 0, 0, 0, timeutil.KST)
+	if h := m.Report()["newsapi"]; !h.BlockedUntil.Equal(midnight) {
+		t.Errorf("blocked until %s, want %s", h.BlockedUntil, midnight)
+	}
+}
+
+func TestSaveConcurrent(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "quota.json")
+	m := NewManager(path, nil, nil)
+	sources := []string{"naver", "newsapi", "rss", "naver_search_api"}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		for _, source := range sources {
+			wg.Add(1)
+			go func(source string) {
+				defer wg.Done()
+				m.Begin(source)
+				if err := m.End(source, nil); err != nil {
+					t.Errorf("End: %v", err)
+				}
+			}(source)
+		}
+	}
+	wg.Wait()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var saved map[string]*sourceState
+	if err := json.Unmarshal(data, &saved); err != nil {
+		t.Fatalf("snapshot is corrupt: %v", err)
+	}
+	if len(saved) != len(sources) {
+		t.Errorf("snapshot has %d sources, want %d", len(saved), len(sources))
+	}
+}
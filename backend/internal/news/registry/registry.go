@@ -0,0 +1,612 @@
+package registry
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"dx-unified/internal/news/fetcher"
+	"dx-unified/internal/news/fetcher/naver"
+	"dx-unified/internal/news/fetcher/newsapi"
+	"dx-unified/internal/news/fetcher/rss"
+	"dx-unified/internal/shared/config"
+
+	"github.com/google/uuid"
+	"github.com/robfig/cron/v3"
+)
+
+// Source types. Search sources are built in, one per provider, and run the
+// queries registered for them; every feed is a source of its own.
+const (
+	TypeNaver   = "naver"
+	TypeNewsAPI = "newsapi"
+	TypeRSS     = "rss"
+)
+
+var (
+	ErrNotFound = errors.New("not found")
+	ErrExists   = errors.New("already exists")
+	ErrInvalid  = errors.New("invalid")
+)
+
+var (
+	namePattern     = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{0,63}$`)
+	languagePattern = regexp.MustCompile(`^[a-z]{2}$`)
+)
+
+// Source is a provider or feed the processor fetches from
+type Source struct {
+	Name      string    `json:"name"`
+	Type      string    `json:"type"`
+	Enabled   bool      `json:"enabled"`
+	URL       string    `json:"url,omitempty"`       // feeds only
+	Publisher string    `json:"publisher,omitempty"` // feeds only
+	Schedule  string    `json:"schedule,omitempty"`  // cron expression, empty runs with every fetch
+	Language  string    `json:"language,omitempty"`  // default for the source's queries
+	Tags      []string  `json:"tags,omitempty"`      // added to every article from the source
+	LastRunAt time.Time `json:"last_run_at"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Query is a search run against a search source
+type Query struct {
+	ID        string    `json:"id"`
+	Source    string    `json:"source"`
+	Query     string    `json:"query"`
+	Enabled   bool      `json:"enabled"`
+	Schedule  string    `json:"schedule,omitempty"`
+	Language  string    `json:"language,omitempty"`
+	Tags      []string  `json:"tags,omitempty"`
+	LastRunAt time.Time `json:"last_run_at"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// SourcePatch changes the given fields of a source
+type SourcePatch struct {
+	Enabled   *bool     `json:"enabled"`
+	URL       *string   `json:"url"`
+	Publisher *string   `json:"publisher"`
+	Schedule  *string   `json:"schedule"`
+	Language  *string   `json:"language"`
+	Tags      *[]string `json:"tags"`
+}
+
+// QueryPatch changes the given fields of a query
+type QueryPatch struct {
+	Query    *string   `json:"query"`
+	Enabled  *bool     `json:"enabled"`
+	Schedule *string   `json:"schedule"`
+	Language *string   `json:"language"`
+	Tags     *[]string `json:"tags"`
+}
+
+type snapshot struct {
+	Sources []*Source `json:"sources"`
+	Queries []*Query  `json:"queries"`
+}
+
+// clone copies the sources and queries so they can be changed without
+// touching the snapshot
+func (st snapshot) clone() snapshot {
+	out := snapshot{
+		Sources: make([]*Source, 0, len(st.Sources)),
+		Queries: make([]*Query, 0, len(st.Queries)),
+	}
+	for _, s := range st.Sources {
+		c := *s
+		c.Tags = slices.Clone(s.Tags)
+		out.Sources = append(out.Sources, &c)
+	}
+	for _, q := range st.Queries {
+		c := *q
+		c.Tags = slices.Clone(q.Tags)
+		out.Queries = append(out.Queries, &c)
+	}
+	return out
+}
+
+// Registry holds the news sources and search queries. Changes are saved
+// immediately and picked up by the processor on its next run; a change that
+// cannot be saved is not applied.
+type Registry struct {
+	path string
+
+	mu    sync.RWMutex
+	state snapshot
+}
+
+// New loads the registry saved at path. Without a saved registry it is
+// seeded from the configured Naver queries and RSS feeds and the default
+// NewsAPI query.
+func New(path string, cfg *config.Config) (*Registry, error) {
+	r := &Registry{path: path}
+
+	data, err := os.ReadFile(path)
+	switch {
+	case err == nil:
+		if err := json.Unmarshal(data, &r.state); err != nil {
+			return nil, fmt.Errorf("parse %s: %w", path, err)
+		}
+		return r, nil
+	case !os.IsNotExist(err):
+		return nil, err
+	}
+
+	now := time.Now()
+	r.state.Sources = []*Source{
+		{Name: naver.Source, Type: TypeNaver, Enabled: true, CreatedAt: now, UpdatedAt: now},
+		{Name: newsapi.Source, Type: TypeNewsAPI, Enabled: true, Language: newsapi.DefaultLanguage, CreatedAt: now, UpdatedAt: now},
+	}
+	for _, q := range cfg.NaverQueries {
+		r.state.Queries = append(r.state.Queries, newQuery(naver.Source, q, now))
+	}
+	r.state.Queries = append(r.state.Queries, newQuery(newsapi.Source, newsapi.DefaultQuery, now))
+	for _, feed := range cfg.RSSFeeds {
+		r.state.Sources = append(r.state.Sources, &Source{
+			Name:      feed.Name,
+			Type:      TypeRSS,
+			Enabled:   true,
+			URL:       feed.URL,
+			Publisher: feed.Publisher,
+			CreatedAt: now,
+			UpdatedAt: now,
+		})
+	}
+	return r, save(r.path, r.state)
+}
+
+func newQuery(source, text string, now time.Time) *Query {
+	return &Query{
+		ID:        uuid.New().String(),
+		Source:    source,
+		Query:     text,
+		Enabled:   true,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+}
+
+// Sources returns all sources
+func (r *Registry) Sources() []Source {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	out := make([]Source, 0, len(r.state.Sources))
+	for _, s := range r.state.Sources {
+		out = append(out, *s)
+	}
+	return out
+}
+
+// Source returns a source by name
+func (r *Registry) Source(name string) (Source, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	s := r.sourceLocked(name)
+	if s == nil {
+		return Source{}, fmt.Errorf("source %s: %w", name, ErrNotFound)
+	}
+	return *s, nil
+}
+
+// AddSource registers a feed. Search sources are built in and cannot be added.
+func (r *Registry) AddSource(s Source) (Source, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	s.Name = strings.TrimSpace(s.Name)
+	if s.Type == "" {
+		s.Type = TypeRSS
+	}
+	if s.Type != TypeRSS {
+		return Source{}, fmt.Errorf("%w: only %s sources can be added, search sources are built in", ErrInvalid, TypeRSS)
+	}
+	if !namePattern.MatchString(s.Name) {
+		return Source{}, fmt.Errorf("%w: name must be lowercase letters, digits, _ or -", ErrInvalid)
+	}
+	if r.sourceLocked(s.Name) != nil {
+		return Source{}, fmt.Errorf("source %s: %w", s.Name, ErrExists)
+	}
+	s.Tags = cleanTags(s.Tags)
+	if err := validateSource(&s); err != nil {
+		return Source{}, err
+	}
+
+	now := time.Now()
+	s.LastRunAt = time.Time{}
+	s.CreatedAt, s.UpdatedAt = now, now
+	next := r.state.clone()
+	next.Sources = append(next.Sources, &s)
+	if err := r.commitLocked(next); err != nil {
+		return Source{}, err
+	}
+	return s, nil
+}
+
+// UpdateSource applies a patch to a source
+func (r *Registry) UpdateSource(name string, patch SourcePatch) (Source, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	cur := r.sourceLocked(name)
+	if cur == nil {
+		return Source{}, fmt.Errorf("source %s: %w", name, ErrNotFound)
+	}
+	s := *cur
+	if patch.Enabled != nil {
+		s.Enabled = *patch.Enabled
+	}
+	if patch.URL != nil {
+		s.URL = strings.TrimSpace(*patch.URL)
+	}
+	if patch.Publisher != nil {
+		s.Publisher = strings.TrimSpace(*patch.Publisher)
+	}
+	if patch.Schedule != nil {
+		s.Schedule = strings.TrimSpace(*patch.Schedule)
+	}
+	if patch.Language != nil {
+		s.Language = strings.TrimSpace(*patch.Language)
+	}
+	if patch.Tags != nil {
+		s.Tags = cleanTags(*patch.Tags)
+	}
+	if err := validateSource(&s); err != nil {
+		return Source{}, err
+	}
+
+	s.UpdatedAt = time.Now()
+	next := r.state.clone()
+	*next.source(name) = s
+	if err := r.commitLocked(next); err != nil {
+		return Source{}, err
+	}
+	return s, nil
+}
+
+// DeleteSource removes a feed. Search sources can only be disabled.
+func (r *Registry) DeleteSource(name string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	i := slices.IndexFunc(r.state.Sources, func(s *Source) bool { return s.Name == name })
+	if i < 0 {
+		return fmt.Errorf("source %s: %w", name, ErrNotFound)
+	}
+	if r.state.Sources[i].Type != TypeRSS {
+		return fmt.Errorf("%w: search source %s is built in, disable it instead", ErrInvalid, name)
+	}
+	next := r.state.clone()
+	next.Sources = slices.Delete(next.Sources, i, i+1)
+	return r.commitLocked(next)
+}
+
+// Queries returns the queries of a source, or all queries when source is empty
+func (r *Registry) Queries(source string) []Query {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	out := make([]Query, 0, len(r.state.Queries))
+	for _, q := range r.state.Queries {
+		if source == "" || q.Source == source {
+			out = append(out, *q)
+		}
+	}
+	return out
+}
+
+// Query returns a query by ID
+func (r *Registry) Query(id string) (Query, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	q := r.queryLocked(id)
+	if q == nil {
+		return Query{}, fmt.Errorf("query %s: %w", id, ErrNotFound)
+	}
+	return *q, nil
+}
+
+// AddQuery registers a query with a search source
+func (r *Registry) AddQuery(q Query) (Query, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	q.Query = strings.TrimSpace(q.Query)
+	q.Tags = cleanTags(q.Tags)
+	if err := r.validateQueryLocked(&q, ""); err != nil {
+		return Query{}, err
+	}
+
+	now := time.Now()
+	q.ID = uuid.New().String()
+	q.LastRunAt = time.Time{}
+	q.CreatedAt, q.UpdatedAt = now, now
+	next := r.state.clone()
+	next.Queries = append(next.Queries, &q)
+	if err := r.commitLocked(next); err != nil {
+		return Query{}, err
+	}
+	return q, nil
+}
+
+// UpdateQuery applies a patch to a query
+func (r *Registry) UpdateQuery(id string, patch QueryPatch) (Query, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	cur := r.queryLocked(id)
+	if cur == nil {
+		return Query{}, fmt.Errorf("query %s: %w", id, ErrNotFound)
+	}
+	q := *cur
+	if patch.Query != nil {
+		q.Query = strings.TrimSpace(*patch.Query)
+	}
+	if patch.Enabled != nil {
+		q.Enabled = *patch.Enabled
+	}
+	if patch.Schedule != nil {
+		q.Schedule = strings.TrimSpace(*patch.Schedule)
+	}
+	if patch.Language != nil {
+		q.Language = strings.TrimSpace(*patch.Language)
+	}
+	if patch.Tags != nil {
+		q.Tags = cleanTags(*patch.Tags)
+	}
+	if err := r.validateQueryLocked(&q, id); err != nil {
+		return Query{}, err
+	}
+
+	q.UpdatedAt = time.Now()
+	next := r.state.clone()
+	*next.query(id) = q
+	if err := r.commitLocked(next); err != nil {
+		return Query{}, err
+	}
+	return q, nil
+}
+
+// DeleteQuery removes a query
+func (r *Registry) DeleteQuery(id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	i := slices.IndexFunc(r.state.Queries, func(q *Query) bool { return q.ID == id })
+	if i < 0 {
+		return fmt.Errorf("query %s: %w", id, ErrNotFound)
+	}
+	next := r.state.clone()
+	next.Queries = slices.Delete(next.Queries, i, i+1)
+	return r.commitLocked(next)
+}
+
+// Targets returns the queries or feeds a fetcher should run now. When there
+// is nothing to run it returns the reason instead.
+func (r *Registry) Targets(fetcherName string, now time.Time) ([]fetcher.Target, string) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	// Feeds share the RSS fetcher
+	if fetcherName == rss.Source {
+		var targets []fetcher.Target
+		for _, s := range r.state.Sources {
+			if s.Type == TypeRSS && s.Enabled && due(s.Schedule, s.LastRunAt, now) {
+				targets = append(targets, fetcher.Target{
+					Key:       s.Name,
+					URL:       s.URL,
+					Publisher: s.Publisher,
+					Language:  s.Language,
+					Tags:      s.Tags,
+				})
+			}
+		}
+		if len(targets) == 0 {
+			return nil, "no feeds due"
+		}
+		return targets, ""
+	}
+
+	s := r.sourceLocked(fetcherName)
+	switch {
+	case s == nil:
+		return nil, "not registered"
+	case !s.Enabled:
+		return nil, "disabled"
+	case !due(s.Schedule, s.LastRunAt, now):
+		return nil, "not scheduled"
+	}
+
+	var targets []fetcher.Target
+	for _, q := range r.state.Queries {
+		if q.Source != s.Name || !q.Enabled || !due(q.Schedule, q.LastRunAt, now) {
+			continue
+		}
+		language := q.Language
+		if language == "" {
+			language = s.Language
+		}
+		// Keyed by ID so editing the query text keeps its cursor
+		targets = append(targets, fetcher.Target{
+			Key:      q.ID,
+			Query:    q.Query,
+			Language: language,
+			Tags:     mergeTags(s.Tags, q.Tags),
+		})
+	}
+	if len(targets) == 0 {
+		return nil, "no queries due"
+	}
+	return targets, ""
+}
+
+// MarkRun records that a fetcher ran the given targets successfully, so
+// scheduled queries and feeds wait for their next activation
+func (r *Registry) MarkRun(fetcherName string, targets []fetcher.Target, at time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	keys := make(map[string]bool, len(targets))
+	for _, t := range targets {
+		keys[t.Key] = true
+	}
+
+	next := r.state.clone()
+	if fetcherName == rss.Source {
+		for _, s := range next.Sources {
+			if s.Type == TypeRSS && keys[s.Name] {
+				s.LastRunAt = at
+			}
+		}
+		return r.commitLocked(next)
+	}
+
+	if s := next.source(fetcherName); s != nil {
+		s.LastRunAt = at
+	}
+	for _, q := range next.Queries {
+		if q.Source == fetcherName && keys[q.ID] {
+			q.LastRunAt = at
+		}
+	}
+	return r.commitLocked(next)
+}
+
+// due reports whether a cron schedule has fired since the last run. An
+// empty or unparsable schedule is always due.
+func due(schedule string, lastRun, now time.Time) bool {
+	if schedule == "" || lastRun.IsZero() {
+		return true
+	}
+	sched, err := cron.ParseStandard(schedule)
+	if err != nil {
+		return true
+	}
+	return !sched.Next(lastRun).After(now)
+}
+
+func (r *Registry) sourceLocked(name string) *Source {
+	return r.state.source(name)
+}
+
+func (r *Registry) queryLocked(id string) *Query {
+	return r.state.query(id)
+}
+
+func (st snapshot) source(name string) *Source {
+	for _, s := range st.Sources {
+		if s.Name == name {
+			return s
+		}
+	}
+	return nil
+}
+
+func (st snapshot) query(id string) *Query {
+	for _, q := range st.Queries {
+		if q.ID == id {
+			return q
+		}
+	}
+	return nil
+}
+
+func validateSource(s *Source) error {
+	if s.Type == TypeRSS {
+		u, err := url.Parse(s.URL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("%w: url must be an http(s) URL", ErrInvalid)
+		}
+	} else if s.URL != "" || s.Publisher != "" {
+		return fmt.Errorf("%w: url and publisher only apply to %s sources", ErrInvalid, TypeRSS)
+	}
+	return validateCommon(s.Schedule, s.Language)
+}
+
+// validateQueryLocked checks a query; self is the ID of the query being
+// updated, which may keep its own text
+func (r *Registry) validateQueryLocked(q *Query, self string) error {
+	s := r.sourceLocked(q.Source)
+	if s == nil {
+		return fmt.Errorf("%w: unknown source %q", ErrInvalid, q.Source)
+	}
+	if s.Type == TypeRSS {
+		return fmt.Errorf("%w: %s is a feed, queries need a search source", ErrInvalid, q.Source)
+	}
+	if q.Query == "" {
+		return fmt.Errorf("%w: query is required", ErrInvalid)
+	}
+	for _, other := range r.state.Queries {
+		// The same text twice would only fetch the same articles twice
+		if other.ID != self && other.Source == q.Source && other.Query == q.Query {
+			return fmt.Errorf("query %q for %s: %w", q.Query, q.Source, ErrExists)
+		}
+	}
+	return validateCommon(q.Schedule, q.Language)
+}
+
+func validateCommon(schedule, language string) error {
+	if schedule != "" {
+		if _, err := cron.ParseStandard(schedule); err != nil {
+			return fmt.Errorf("%w: schedule: %v", ErrInvalid, err)
+		}
+	}
+	if language != "" && !languagePattern.MatchString(language) {
+		return fmt.Errorf("%w: language must be a two-letter ISO 639-1 code", ErrInvalid)
+	}
+	return nil
+}
+
+// cleanTags trims tags and drops empty and repeated ones
+func cleanTags(tags []string) []string {
+	var out []string
+	for _, t := range tags {
+		if t = strings.TrimSpace(t); t != "" && !slices.Contains(out, t) {
+			out = append(out, t)
+		}
+	}
+	return out
+}
+
+func mergeTags(a, b []string) []string {
+	return cleanTags(append(slices.Clone(a), b...))
+}
+
+// commitLocked saves a changed copy of the state and swaps it in, leaving
+// the state unchanged when it cannot be saved
+func (r *Registry) commitLocked(next snapshot) error {
+	if err := save(r.path, next); err != nil {
+		return err
+	}
+	r.state = next
+	return nil
+}
+
+// save writes a registry state to disk atomically
+func save(path string, state snapshot) error {
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
@@ -0,0 +1,178 @@
+package registry
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"dx-unified/internal/news/fetcher/naver"
+	"dx-unified/internal/news/fetcher/rss"
+	"dx-unified/internal/shared/config"
+)
+
+func newTestRegistry(t *testing.T) (*Registry, string) {
+	path := filepath.Join(t.TempDir(), "news", "registry.json")
+	r, err := New(path, &config.Config{
+		NaverQueries: []string{"코스피"},
+		RSSFeeds:     []config.RSSFeed{{Name: "hankyung", URL: "https://www.hankyung.com/feed/economy", Publisher: "한국경제"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return r, path
+}
+
+func TestRegistrySeedAndReload(t *testing.T) {
+	r, path := newTestRegistry(t)
+	if got := len(r.Sources()); got != 3 {
+		t.Fatalf("seeded %d sources, want naver, newsapi and one feed", got)
+	}
+	if _, err := r.AddQuery(Query{Source: naver.Source, Query: " 반도체 ", Enabled: true, Tags: []string{"semis", " ", "semis"}}); err != nil {
+		t.Fatal(err)
+	}
+
+	reloaded, err := New(path, &config.Config{NaverQueries: []string{"ignored"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	queries := reloaded.Queries(naver.Source)
+	if len(queries) != 2 || queries[1].Query != "반도체" || len(queries[1].Tags) != 1 {
+		t.Errorf("reloaded queries = %+v", queries)
+	}
+}
+
+func TestRegistryValidation(t *testing.T) {
+	r, _ := newTestRegistry(t)
+	tests := []struct {
+		name string
+		err  error
+		fn   func() error
+	}{
+		{"search source", ErrInvalid, func() error {
+			_, err := r.AddSource(Source{Name: "other", Type: TypeNaver})
+			return err
+		}},
+		{"bad name", ErrInvalid, func() error {
+			_, err := r.AddSource(Source{Name: "Bad Name", URL: "https://example.com/rss"})
+			return err
+		}},
+		{"bad url", ErrInvalid, func() error {
+			_, err := r.AddSource(Source{Name: "feed", URL: "ftp://example.com/rss"})
+			return err
+		}},
+		{"existing feed", ErrExists, func() error {
+			_, err := r.AddSource(Source{Name: "hankyung", URL: "https://example.com/rss"})
+			return err
+		}},
+		{"bad schedule", ErrInvalid, func() error {
+			_, err := r.AddQuery(Query{Source: naver.Source, Query: "금리", Schedule: "every hour"})
+			return err
+		}},
+		{"query for a feed", ErrInvalid, func() error {
+			_, err := r.AddQuery(Query{Source: "hankyung", Query: "금리"})
+			return err
+		}},
+		{"repeated query", ErrExists, func() error {
+			_, err := r.AddQuery(Query{Source: naver.Source, Query: "코스피"})
+			return err
+		}},
+		{"delete search source", ErrInvalid, func() error { return r.DeleteSource(naver.Source) }},
+		{"unknown query", ErrNotFound, func() error { return r.DeleteQuery("missing") }},
+	}
+	for _, tt := range tests {
+		if err := tt.fn(); !errors.Is(err, tt.err) {
+			t.Errorf("%s: err = %v, want %v", tt.name, err, tt.err)
+		}
+	}
+}
+
+// TestRegistrySaveFailure checks that a change that cannot be saved is not
+// applied
+func TestRegistrySaveFailure(t *testing.T) {
+	r, path := newTestRegistry(t)
+	// The registry directory becomes a file, so saving fails
+	dir := filepath.Dir(path)
+	if err := os.RemoveAll(dir); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(dir, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	disabled := false
+	if _, err := r.UpdateSource("hankyung", SourcePatch{Enabled: &disabled}); err == nil {
+		t.Error("update saved")
+	}
+	if _, err := r.AddQuery(Query{Source: naver.Source, Query: "금리"}); err == nil {
+		t.Error("query saved")
+	}
+	if err := r.DeleteSource("hankyung"); err == nil {
+		t.Error("delete saved")
+	}
+	if err := r.MarkRun(rss.Source, nil, time.Now()); err == nil {
+		t.Error("run saved")
+	}
+
+	s, err := r.Source("hankyung")
+	if err != nil || !s.Enabled {
+		t.Errorf("feed after failed saves: %+v, %v", s, err)
+	}
+	if got := len(r.Queries(naver.Source)); got != 1 {
+		t.Errorf("%d queries after a failed add", got)
+	}
+}
+
+// TestRegistrySchedule checks that a scheduled query is due again only
+// after its next activation, and that targets keep their cursor keys
+func TestRegistrySchedule(t *testing.T) {
+	r, _ := newTestRegistry(t)
+	q := r.Queries(naver.Source)[0]
+	hourly := "0 * * * *"
+	if _, err := r.UpdateQuery(q.ID, QueryPatch{Schedule: &hourly}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.UpdateSource("hankyung", SourcePatch{Schedule: &hourly}); err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Date(2026, 3, 2, 9, 10, 0, 0, time.Local)
+	targets, reason := r.Targets(naver.Source, now)
+	if len(targets) != 1 || targets[0].Key != q.ID || targets[0].Query != "코스피" {
+		t.Fatalf("targets = %+v (%s)", targets, reason)
+	}
+	feeds, _ := r.Targets(rss.Source, now)
+	if len(feeds) != 1 || feeds[0].Key != "hankyung" || feeds[0].Publisher != "한국경제" {
+		t.Fatalf("feeds = %+v", feeds)
+	}
+	if err := r.MarkRun(naver.Source, targets, now); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.MarkRun(rss.Source, feeds, now); err != nil {
+		t.Fatal(err)
+	}
+
+	if targets, reason := r.Targets(naver.Source, now.Add(30*time.Minute)); len(targets) != 0 || reason != "no queries due" {
+		t.Errorf("before the next hour: %+v (%s)", targets, reason)
+	}
+	if feeds, reason := r.Targets(rss.Source, now.Add(30*time.Minute)); len(feeds) != 0 || reason != "no feeds due" {
+		t.Errorf("feeds before the next hour: %+v (%s)", feeds, reason)
+	}
+
+	// Editing the text keeps the key, and with it the fetcher's cursor
+	text := "코스피 지수"
+	if _, err := r.UpdateQuery(q.ID, QueryPatch{Query: &text}); err != nil {
+		t.Fatal(err)
+	}
+	targets, reason = r.Targets(naver.Source, now.Add(50*time.Minute))
+	if len(targets) != 1 || targets[0].Key != q.ID || targets[0].Query != text {
+		t.Errorf("at the next hour: %+v (%s)", targets, reason)
+	}
+
+	disabled := false
+	r.UpdateSource(naver.Source, SourcePatch{Enabled: &disabled})
+	if _, reason := r.Targets(naver.Source, now.Add(time.Hour)); reason != "disabled" {
+		t.Errorf("disabled source: %s", reason)
+	}
+}
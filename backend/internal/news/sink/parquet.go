@@ -0,0 +1,256 @@
+package sink
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"dx-unified/internal/news/store"
+	"dx-unified/internal/shared/timeutil"
+
+	"github.com/parquet-go/parquet-go"
+)
+
+// ViewName is the DuckDB view over the dataset with the latest row per article
+const ViewName = "news_articles"
+
+// ParquetArticle is one processed article for analytics. Articles are
+// appended every time they are processed, so the latest row by
+// processed_at is the current state.
+type ParquetArticle struct {
+	ID           string    `parquet:"id"`
+	RunID        string    `parquet:"run_id,dict"`
+	Title        string    `parquet:"title"`
+	URL          string    `parquet:"url"`
+	Source       string    `parquet:"source,dict"`
+	Publisher    string    `parquet:"publisher,dict"`
+	PublishedAt  time.Time `parquet:"published_at"`
+	FetchedAt    time.Time `parquet:"fetched_at"`
+	ProcessedAt  time.Time `parquet:"processed_at"`
+	PublishedTS  int64     `parquet:"published_at_ts"` // unix seconds, comparable with epoch(timestamp) of candles
+	FetchedTS    int64     `parquet:"fetched_at_ts"`
+	Tags         []string  `parquet:"tags,list"`
+	Sentiment    string    `parquet:"sentiment,dict"`
+	DupState     string    `parquet:"dup_state,dict"`
+	DupOf        string    `parquet:"dup_of"`
+	DupScore     float64   `parquet:"dup_score"`
+	StoryID      string    `parquet:"story_id"`
+	HasBody      bool      `parquet:"has_body"`
+	BodyStatus   string    `parquet:"body_status,dict"`
+	PublishedYMD string    `parquet:"published_ymd,dict"` // KST date, for joins with daily candles
+}
+
+// Parquet writes processed articles to a Hive-partitioned Parquet dataset:
+//
+//	{dir}/year=YYYY/month=MM/{run_id}-{seq}.parquet
+//
+// partitioned by KST publication month like the candle data, so DuckDB can
+// prune partitions and join both datasets. Every run adds a file to each
+// month it touched; Compact merges them into one file per month.
+type Parquet struct {
+	dir string
+	seq atomic.Int64
+
+	compactMu sync.Mutex
+
+	mu       sync.Mutex
+	db       *sql.DB // DuckDB connection the view is registered on
+	viewDone bool
+}
+
+// NewParquet creates a sink writing under dir
+func NewParquet(dir string) *Parquet {
+	return &Parquet{dir: dir}
+}
+
+// Glob returns the read_parquet pattern of the whole dataset
+func (p *Parquet) Glob() string {
+	return filepath.Join(p.dir, "year=*", "month=*", "*.parquet")
+}
+
+// Write appends docs, one file per publication month. Files are renamed
+// into place so readers never see a partial file.
+func (p *Parquet) Write(runID string, docs []store.ArticleDoc) error {
+	if len(docs) == 0 {
+		return nil
+	}
+
+	now := time.Now()
+	partitions := make(map[string][]ParquetArticle)
+	for _, d := range docs {
+		published := d.PublishedAt.In(timeutil.KST)
+		part := filepath.Join(
+			fmt.Sprintf("year=%04d", published.Year()),
+			fmt.Sprintf("month=%02d", published.Month()),
+		)
+		tags := d.Tags
+		if tags == nil {
+			tags = []string{}
+		}
+		partitions[part] = append(partitions[part], ParquetArticle{
+			ID:           d.ID,
+			RunID:        runID,
+			Title:        d.Title,
+			URL:          d.URL,
+			Source:       d.Source,
+			Publisher:    d.Publisher,
+			PublishedAt:  d.PublishedAt,
+			FetchedAt:    d.FetchedAt,
+			ProcessedAt:  now,
+			PublishedTS:  d.PublishedAt.Unix(),
+			FetchedTS:    d.FetchedAt.Unix(),
+			Tags:         tags,
+			Sentiment:    d.Sentiment,
+			DupState:     d.DupState,
+			DupOf:        d.DupOf,
+			DupScore:     d.DupScore,
+			StoryID:      d.StoryID,
+			HasBody:      d.Body != "",
+			BodyStatus:   d.BodyStatus,
+			PublishedYMD: published.Format("2006-01-02"),
+		})
+	}
+
+	for part, rows := range partitions {
+		if err := p.writeFile(filepath.Join(p.dir, part), runID, rows); err != nil {
+			return err
+		}
+	}
+	return p.ensureView()
+}
+
+func (p *Parquet) writeFile(dir, runID string, rows []ParquetArticle) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create directory %s: %w", dir, err)
+	}
+
+	filename := filepath.Join(dir, fmt.Sprintf("%s-%d.parquet", runID, p.seq.Add(1)))
+	tmp := filename + ".tmp"
+	f, err := os.Create(tmp)
+	if err != nil {
+		return fmt.Errorf("failed to create file %s: %w", tmp, err)
+	}
+
+	writer := parquet.NewGenericWriter[ParquetArticle](f)
+	if _, err := writer.Write(rows); err != nil {
+		f.Close()
+		os.Remove(tmp)
+		return fmt.Errorf("failed to write parquet: %w", err)
+	}
+	if err := writer.Close(); err != nil {
+		f.Close()
+		os.Remove(tmp)
+		return fmt.Errorf("failed to close parquet writer: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return os.Rename(tmp, filename)
+}
+
+// Compact rewrites every month partition with more than one file into a
+// single file holding the latest row of each article, then removes the
+// merged files. Files written meanwhile are left for the next compaction.
+func (p *Parquet) Compact() error {
+	p.compactMu.Lock()
+	defer p.compactMu.Unlock()
+
+	dirs, err := filepath.Glob(filepath.Join(p.dir, "year=*", "month=*"))
+	if err != nil {
+		return err
+	}
+	for _, dir := range dirs {
+		if err := p.compactPartition(dir); err != nil {
+			return fmt.Errorf("compact %s: %w", dir, err)
+		}
+	}
+	return nil
+}
+
+func (p *Parquet) compactPartition(dir string) error {
+	files, err := filepath.Glob(filepath.Join(dir, "*.parquet"))
+	if err != nil || len(files) < 2 {
+		return err
+	}
+
+	latest := make(map[string]ParquetArticle)
+	for _, file := range files {
+		// Columns added since a file was written read as zero values
+		rows, err := parquet.ReadFile[ParquetArticle](file)
+		if err != nil {
+			return fmt.Errorf("read %s: %w", file, err)
+		}
+		for _, row := range rows {
+			if cur, ok := latest[row.ID]; !ok || !row.ProcessedAt.Before(cur.ProcessedAt) {
+				latest[row.ID] = row
+			}
+		}
+	}
+	rows := make([]ParquetArticle, 0, len(latest))
+	for _, row := range latest {
+		rows = append(rows, row)
+	}
+	sort.Slice(rows, func(i, j int) bool {
+		if rows[i].PublishedTS != rows[j].PublishedTS {
+			return rows[i].PublishedTS < rows[j].PublishedTS
+		}
+		return rows[i].ID < rows[j].ID
+	})
+
+	// The merged file is in place before the files it replaces go away, so
+	// readers see every article throughout; the view drops the duplicates
+	if err := p.writeFile(dir, fmt.Sprintf("compact-%d", time.Now().Unix()), rows); err != nil {
+		return err
+	}
+	for _, file := range files {
+		if err := os.Remove(file); err != nil {
+			return err
+		}
+	}
+	log.Printf("[NEWS] Compacted %d Parquet files of %s into one (%d articles)", len(files), dir, len(rows))
+	return nil
+}
+
+// AttachDuckDB registers the news_articles view on db. DuckDB binds a view
+// when it is created, so without any files yet the view is created after
+// the first write.
+func (p *Parquet) AttachDuckDB(db *sql.DB) error {
+	p.mu.Lock()
+	p.db = db
+	p.mu.Unlock()
+	return p.ensureView()
+}
+
+func (p *Parquet) ensureView() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.db == nil || p.viewDone {
+		return nil
+	}
+	if matches, _ := filepath.Glob(p.Glob()); len(matches) == 0 {
+		return nil
+	}
+
+	// The glob is expanded on every query, so new files show up in the view
+	_, err := p.db.Exec(fmt.Sprintf(`
+		CREATE OR REPLACE VIEW %s AS
+		SELECT * EXCLUDE (rn) FROM (
+			SELECT *, row_number() OVER (PARTITION BY id ORDER BY processed_at DESC) AS rn
+			FROM read_parquet('%s', hive_partitioning=true, union_by_name=true)
+		)
+		WHERE rn = 1
+	`, ViewName, p.Glob()))
+	if err != nil {
+		return fmt.Errorf("create view %s: %w", ViewName, err)
+	}
+	p.viewDone = true
+	return nil
+}
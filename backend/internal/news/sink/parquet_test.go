@@ -0,0 +1,59 @@
+package sink
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"dx-unified/internal/news/store"
+	"dx-unified/internal/shared/timeutil"
+
+	"github.com/parquet-go/parquet-go"
+)
+
+func TestCompact(t *testing.T) {
+	p := NewParquet(t.TempDir())
+	march := time.Date(2026, 3, 2, 9, 0, 0, 0, timeutil.KST)
+	april := time.Date(2026, 4, 1, 9, 0, 0, 0, timeutil.KST)
+
+	runs := [][]store.ArticleDoc{
+		{{ID: "a", Title: "first", PublishedAt: march}, {ID: "b", PublishedAt: march.Add(time.Hour)}},
+		{{ID: "a", Title: "reprocessed", PublishedAt: march, Sentiment: "positive"}},
+		{{ID: "c", PublishedAt: april}},
+	}
+	for i, docs := range runs {
+		if err := p.Write(fmt.Sprintf("run-%d", i), docs); err != nil {
+			t.Fatal(err)
+		}
+		time.Sleep(time.Millisecond) // distinct processed_at
+	}
+	if files, _ := filepath.Glob(p.Glob()); len(files) != 3 {
+		t.Fatalf("%d files before compaction, want 3", len(files))
+	}
+
+	if err := p.Compact(); err != nil {
+		t.Fatal(err)
+	}
+	files, _ := filepath.Glob(filepath.Join(p.dir, "year=2026", "month=03", "*.parquet"))
+	if len(files) != 1 {
+		t.Fatalf("march files = %v", files)
+	}
+	rows, err := parquet.ReadFile[ParquetArticle](files[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 2 || rows[0].ID != "a" || rows[0].Title != "reprocessed" || rows[0].Sentiment != "positive" || rows[1].ID != "b" {
+		t.Errorf("compacted rows = %+v", rows)
+	}
+
+	// A single file is left alone
+	april1, _ := filepath.Glob(filepath.Join(p.dir, "year=2026", "month=04", "*.parquet"))
+	if err := p.Compact(); err != nil {
+		t.Fatal(err)
+	}
+	april2, _ := filepath.Glob(filepath.Join(p.dir, "year=2026", "month=04", "*.parquet"))
+	if len(april1) != 1 || len(april2) != 1 || april1[0] != april2[0] {
+		t.Errorf("april files %v, then %v", april1, april2)
+	}
+}
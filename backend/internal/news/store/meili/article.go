@@ -1,45 +1,128 @@
 package meili
 
 import (
-	"time"
-)
+	"encoding/json"
+	"errors"
+	"fmt"
 
-type ArticleDoc struct {
-	ID           string    `json:"id"`
-	Title        string    `json:"title"`
-	Summary      string    `json:"summary"`
-	URL          string    `json:"url"`
-	CanonicalURL string    `json:"canonical_url"`
-	Source       string    `json:"source"`
-	Publisher    string    `json:"publisher"`
-	PublishedAt  time.Time `json:"published_at"`
-	FetchedAt    time.Time `json:"fetched_at"`
-
-	DupState string  `json:"dup_state"` // unique, duplicate
-	DupOf    string  `json:"dup_of,omitempty"`
-	DupScore float64 `json:"dup_score,omitempty"`
-
-	Tags []string `json:"tags,omitempty"`
-}
+	"dx-unified/internal/news/store"
 
-type RunLog struct {
-	RunID     string                 `json:"run_id"`
-	StartedAt time.Time              `json:"started_at"`
-	EndedAt   time.Time              `json:"ended_at"`
-	Status    string                 `json:"status"` // success, failed
-	Stats     map[string]interface{} `json:"stats"`
-	Errors    []string               `json:"errors,omitempty"`
-}
+	"github.com/meilisearch/meilisearch-go"
+)
+
+// Store implements store.NewsStore
+var _ store.NewsStore = (*Store)(nil)
 
-func (s *Store) SaveArticles(articles []ArticleDoc) error {
+func (s *Store) SaveArticles(articles []store.ArticleDoc) error {
 	if len(articles) == 0 {
 		return nil
 	}
-	_, err := s.Client.Index(IndexArticles).AddDocuments(articles, nil)
+	docs := make([]store.ArticleDoc, len(articles))
+	for i := range articles {
+		docs[i] = articles[i]
+		docs[i].SetTimestamps()
+	}
+
+	// UpdateDocuments merges with the stored document, so a re-fetched article
+	// without a body (already extracted in an earlier run) keeps its body.
+	_, err := s.Client.Index(IndexArticles).UpdateDocuments(docs, nil)
 	return err
 }
 
-func (s *Store) SaveRun(run *RunLog) error {
-	_, err := s.Client.Index(IndexRuns).AddDocuments([]*RunLog{run}, nil)
+func (s *Store) SaveRun(run *store.RunLog) error {
+	_, err := s.Client.Index(IndexRuns).AddDocuments([]*store.RunLog{run}, nil)
 	return err
 }
+
+func (s *Store) GetArticle(id string) (*store.ArticleDoc, error) {
+	var doc store.ArticleDoc
+	if err := s.Client.Index(IndexArticles).GetDocument(id, nil, &doc); err != nil {
+		var meiliErr *meilisearch.Error
+		if errors.As(err, &meiliErr) && meiliErr.StatusCode == 404 {
+			return nil, store.ErrNotFound
+		}
+		return nil, err
+	}
+	return &doc, nil
+}
+
+func (s *Store) SearchArticles(q *store.SearchQuery) (*store.SearchResult, error) {
+	if err := s.checkFilterable(q); err != nil {
+		return nil, err
+	}
+	searchReq := &meilisearch.SearchRequest{
+		Limit:  int64(q.Limit),
+		Offset: int64(q.Offset),
+		Sort:   articleSort(q.Sort),
+	}
+
+	if filter := articleFilter(q.Filter); len(filter) > 0 {
+		searchReq.Filter = filter
+	}
+	if len(q.Facets) > 0 {
+		searchReq.Facets = q.Facets
+	}
+	if q.Highlight {
+		searchReq.AttributesToHighlight = []string{"title", "summary", "body"}
+		searchReq.AttributesToCrop = []string{"body"}
+		searchReq.CropLength = 40
+		searchReq.HighlightPreTag = store.HighlightPreTag
+		searchReq.HighlightPostTag = store.HighlightPostTag
+	}
+
+	result, err := s.Client.Index(IndexArticles).Search(q.Query, searchReq)
+	if err != nil {
+		return nil, err
+	}
+
+	var hits []store.ArticleDoc
+	if err := result.Hits.Decode(&hits); err != nil {
+		return nil, fmt.Errorf("decode hits: %w", err)
+	}
+
+	res := &store.SearchResult{
+		Total: result.EstimatedTotalHits,
+		Hits:  hits,
+	}
+
+	if len(result.FacetDistribution) > 0 {
+		if err := json.Unmarshal(result.FacetDistribution, &res.Facets); err != nil {
+			return nil, fmt.Errorf("decode facets: %w", err)
+		}
+	}
+
+	if q.Highlight {
+		res.Highlights = make(map[string]store.Highlight, len(hits))
+		for i, hit := range result.Hits {
+			raw, ok := hit["_formatted"]
+			if !ok || i >= len(hits) {
+				continue
+			}
+			var h store.Highlight
+			if err := json.Unmarshal(raw, &h); err != nil {
+				return nil, fmt.Errorf("decode highlight: %w", err)
+			}
+			res.Highlights[hits[i].ID] = h
+		}
+	}
+
+	return res, nil
+}
+
+func (s *Store) ListRuns(limit int) ([]store.RunLog, error) {
+	searchReq := &meilisearch.SearchRequest{
+		Limit: int64(limit),
+		Sort:  []string{"started_at:desc"},
+	}
+
+	result, err := s.Client.Index(IndexRuns).Search("", searchReq)
+	if err != nil {
+		return nil, err
+	}
+
+	var runs []store.RunLog
+	if err := result.Hits.Decode(&runs); err != nil {
+		return nil, fmt.Errorf("decode runs: %w", err)
+	}
+	return runs, nil
+}
@@ -0,0 +1,108 @@
+package meili
+
+import (
+	"fmt"
+	"strings"
+
+	"dx-unified/internal/news/store"
+)
+
+// FilterBuilder assembles a Meilisearch filter expression. Every clause is
+// AND-ed; string values are always quoted and escaped, so user input can
+// never change the structure of the expression.
+type FilterBuilder struct {
+	clauses []string
+}
+
+// Eq adds `field = "value"` when value is non-empty
+func (b *FilterBuilder) Eq(field, value string) *FilterBuilder {
+	if value != "" {
+		b.clauses = append(b.clauses, fmt.Sprintf("%s = %s", field, quote(value)))
+	}
+	return b
+}
+
+// In adds `field IN ["a", "b"]` when values is non-empty
+func (b *FilterBuilder) In(field string, values []string) *FilterBuilder {
+	var quoted []string
+	for _, v := range values {
+		if v != "" {
+			quoted = append(quoted, quote(v))
+		}
+	}
+	if len(quoted) > 0 {
+		b.clauses = append(b.clauses, fmt.Sprintf("%s IN [%s]", field, strings.Join(quoted, ", ")))
+	}
+	return b
+}
+
+// Gte adds `field >= n`
+func (b *FilterBuilder) Gte(field string, n int64) *FilterBuilder {
+	b.clauses = append(b.clauses, fmt.Sprintf("%s >= %d", field, n))
+	return b
+}
+
+// Lte adds `field <= n`
+func (b *FilterBuilder) Lte(field string, n int64) *FilterBuilder {
+	b.clauses = append(b.clauses, fmt.Sprintf("%s <= %d", field, n))
+	return b
+}
+
+// Raw adds a pre-built expression; callers must not pass user input
+func (b *FilterBuilder) Raw(expr string) *FilterBuilder {
+	b.clauses = append(b.clauses, expr)
+	return b
+}
+
+// Build returns the clauses in the array form accepted by SearchRequest.Filter
+func (b *FilterBuilder) Build() []string {
+	return b.clauses
+}
+
+// quote wraps a value in double quotes, escaping backslashes and quotes
+func quote(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, `"`, `\"`)
+	return `"` + s + `"`
+}
+
+// articleFilter translates a store.Filter into Meilisearch filter clauses
+func articleFilter(f store.Filter) []string {
+	b := &FilterBuilder{}
+	b.In("source", f.Sources).
+		In("publisher", f.Publishers).
+		In("tags", f.Tags).
+		Eq("dup_state", f.DupState).
+		Eq("story_id", f.StoryID).
+		In("sentiment", f.Sentiments)
+
+	if !f.PublishedFrom.IsZero() {
+		b.Gte("published_at_ts", f.PublishedFrom.Unix())
+	}
+	if !f.PublishedTo.IsZero() {
+		b.Lte("published_at_ts", f.PublishedTo.Unix())
+	}
+	return b.Build()
+}
+
+// Sorting on the RFC3339 strings is unreliable across time zones, so the
+// public field names map to their numeric counterparts
+var sortFields = map[string]string{
+	"published_at": "published_at_ts",
+	"fetched_at":   "fetched_at_ts",
+}
+
+func articleSort(sorts []string) []string {
+	out := make([]string, 0, len(sorts))
+	for _, s := range sorts {
+		field, dir, _ := strings.Cut(s, ":")
+		if mapped, ok := sortFields[field]; ok {
+			field = mapped
+		}
+		if dir != "asc" {
+			dir = "desc"
+		}
+		out = append(out, field+":"+dir)
+	}
+	return out
+}
@@ -0,0 +1,442 @@
+package meili
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"time"
+
+	"dx-unified/internal/news/store"
+
+	"github.com/google/uuid"
+	"github.com/meilisearch/meilisearch-go"
+)
+
+const migrationBatchSize = 1000
+
+var _ store.IndexMigrator = (*Store)(nil)
+
+// MigrationStatus returns the live articles index version and the recorded
+// migrations
+func (s *Store) MigrationStatus() (*store.MigrationStatus, error) {
+	migrations, err := s.listMigrations()
+	if err != nil {
+		return nil, err
+	}
+
+	status := &store.MigrationStatus{
+		CurrentVersion: currentVersion(migrations),
+		LatestVersion:  latestArticleVersion().Version,
+		Migrations:     migrations,
+	}
+	for _, v := range articleVersions {
+		status.Versions = append(status.Versions, v.IndexVersion)
+	}
+	return status, nil
+}
+
+// listMigrations returns the recorded migrations, newest first
+func (s *Store) listMigrations() ([]store.Migration, error) {
+	var page meilisearch.DocumentsResult
+	if err := s.Client.Index(IndexMigrations).GetDocuments(&meilisearch.DocumentsQuery{Limit: 1000}, &page); err != nil {
+		return nil, fmt.Errorf("read migrations: %w", err)
+	}
+	migrations := []store.Migration{}
+	if err := page.Results.Decode(&migrations); err != nil {
+		return nil, fmt.Errorf("decode migrations: %w", err)
+	}
+
+	// The running migration is more current than its last saved record
+	if running, ok := s.runningMigration(); ok {
+		found := false
+		for i := range migrations {
+			if migrations[i].ID == running.ID {
+				migrations[i] = running
+				found = true
+			}
+		}
+		if !found {
+			migrations = append(migrations, running)
+		}
+	}
+
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].StartedAt.After(migrations[j].StartedAt)
+	})
+	return migrations, nil
+}
+
+// currentVersion replays the migrations oldest first. An index created
+// before versioning is brought to version 1 by EnsureIndexes.
+func currentVersion(migrations []store.Migration) int {
+	version := 1
+	for i := len(migrations) - 1; i >= 0; i-- {
+		switch m := migrations[i]; m.Status {
+		case store.MigrationCompleted:
+			version = m.ToVersion
+		case store.MigrationRolledBack:
+			version = m.FromVersion
+		}
+	}
+	return version
+}
+
+// StartMigration builds the latest articles index version in the background:
+//
+//  1. create articles_v<N> with the new settings and the current synonyms
+//  2. copy the live index and, from the archive source, overlay the
+//     documents rebuilt from the raw archive of the requested days
+//  3. copy articles saved to the live index in the meantime
+//  4. swap the indexes, after which articles_v<N> holds the old version
+//     for rollback
+func (s *Store) StartMigration(opts store.MigrationOptions) (*store.Migration, error) {
+	switch opts.Source {
+	case store.MigrationSourceIndex:
+	case store.MigrationSourceArchive:
+		if opts.Documents == nil {
+			return nil, fmt.Errorf("archive source needs documents")
+		}
+	default:
+		return nil, fmt.Errorf("unknown migration source %q", opts.Source)
+	}
+
+	status, err := s.MigrationStatus()
+	if err != nil {
+		return nil, err
+	}
+	if status.CurrentVersion >= status.LatestVersion {
+		return nil, store.ErrIndexUpToDate
+	}
+	target := latestArticleVersion()
+
+	now := time.Now()
+	m := &store.Migration{
+		ID:          uuid.New().String(),
+		FromVersion: status.CurrentVersion,
+		ToVersion:   target.Version,
+		Source:      opts.Source,
+		Status:      store.MigrationRunning,
+		Phase:       "create",
+		StartedAt:   now,
+		UpdatedAt:   now,
+	}
+
+	s.migrationMu.Lock()
+	if s.migration != nil {
+		s.migrationMu.Unlock()
+		return nil, store.ErrMigrationRunning
+	}
+	s.migration = m
+	started := *m
+	s.migrationMu.Unlock()
+
+	s.saveMigration(&started)
+	log.Printf("[NEWS] Index migration %s: v%d -> v%d from %s", m.ID, m.FromVersion, m.ToVersion, m.Source)
+
+	go func() {
+		err := s.migrate(s.ctx, opts, target)
+		s.finishMigration(func(m *store.Migration) {
+			if err != nil {
+				m.Status = store.MigrationFailed
+				m.Error = err.Error()
+				return
+			}
+			m.Status = store.MigrationCompleted
+			m.Phase = "done"
+		})
+		if err != nil {
+			log.Printf("[NEWS] Index migration %s failed: %v", started.ID, err)
+		} else {
+			log.Printf("[NEWS] Index migration %s completed in %v", started.ID, time.Since(started.StartedAt))
+		}
+	}()
+
+	return &started, nil
+}
+
+func (s *Store) migrate(ctx context.Context, opts store.MigrationOptions, target articleVersion) error {
+	newIndex := versionedIndex(target.Version)
+	startedAt := time.Now()
+
+	// A leftover from a failed attempt or a rolled back version is rebuilt
+	if _, err := s.Client.GetIndex(newIndex); err == nil {
+		if err := s.wait(s.Client.DeleteIndex(newIndex)); err != nil {
+			return fmt.Errorf("delete stale %s: %w", newIndex, err)
+		}
+	}
+	if err := s.createIndex(newIndex, "id", target.settings()); err != nil {
+		return fmt.Errorf("create %s: %w", newIndex, err)
+	}
+	synonyms, err := s.GetSynonyms()
+	if err != nil {
+		return fmt.Errorf("read synonyms: %w", err)
+	}
+	if len(synonyms) == 0 {
+		synonyms = store.DefaultSynonyms
+	}
+	if err := s.wait(s.Client.Index(newIndex).UpdateSynonyms(&synonyms)); err != nil {
+		return fmt.Errorf("copy synonyms: %w", err)
+	}
+
+	s.updateMigration(func(m *store.Migration) { m.Phase = "reindex" })
+	progress := func(n int, total int64) {
+		s.updateMigration(func(m *store.Migration) {
+			m.Indexed += int64(n)
+			if total > 0 {
+				m.Total = total
+			}
+		})
+	}
+	// The archive covers only some days and none before it existed, so the
+	// live index is always copied and the rebuilt documents replace theirs
+	if err := s.copyDocuments(ctx, IndexArticles, newIndex, "", progress); err != nil {
+		return fmt.Errorf("reindex: %w", err)
+	}
+	if opts.Source == store.MigrationSourceArchive {
+		s.updateMigration(func(m *store.Migration) { m.Phase = "replay" })
+		if err := s.indexDocuments(ctx, newIndex, opts.Documents, nil); err != nil {
+			return fmt.Errorf("replay archive: %w", err)
+		}
+	}
+
+	s.updateMigration(func(m *store.Migration) { m.Phase = "catch_up" })
+	catchUpAt := time.Now()
+	if err := s.copyDocuments(ctx, IndexArticles, newIndex, fetchedSince(startedAt), nil); err != nil {
+		return fmt.Errorf("catch up: %w", err)
+	}
+
+	s.updateMigration(func(m *store.Migration) { m.Phase = "swap" })
+	if err := s.swap(newIndex); err != nil {
+		return err
+	}
+	s.updateMigration(func(m *store.Migration) { m.BackupIndex = newIndex })
+
+	// Articles saved between the catch-up and the swap went to the old
+	// version, which is now the backup
+	if err := s.copyDocuments(ctx, newIndex, IndexArticles, fetchedSince(catchUpAt), nil); err != nil {
+		return fmt.Errorf("catch up after swap: %w", err)
+	}
+	return nil
+}
+
+// RollbackMigration swaps the version replaced by the latest completed
+// migration back in, keeping articles saved since the migration
+func (s *Store) RollbackMigration(id string) (*store.Migration, error) {
+	migrations, err := s.listMigrations()
+	if err != nil {
+		return nil, err
+	}
+
+	var target *store.Migration
+	for i := range migrations {
+		if migrations[i].ID == id {
+			target = &migrations[i]
+			break
+		}
+	}
+	if target == nil {
+		return nil, store.ErrNotFound
+	}
+	var lastCompleted *store.Migration
+	for i := range migrations {
+		if migrations[i].Status == store.MigrationCompleted {
+			lastCompleted = &migrations[i]
+			break
+		}
+	}
+	switch {
+	case target.Status != store.MigrationCompleted:
+		return nil, fmt.Errorf("%w: status is %s", store.ErrCannotRollback, target.Status)
+	case lastCompleted.ID != target.ID:
+		return nil, fmt.Errorf("%w: only the latest completed migration can be rolled back", store.ErrCannotRollback)
+	case target.BackupIndex == "":
+		return nil, fmt.Errorf("%w: no previous version was kept", store.ErrCannotRollback)
+	}
+	if _, err := s.Client.GetIndex(target.BackupIndex); err != nil {
+		return nil, fmt.Errorf("%w: backup index %s is gone", store.ErrCannotRollback, target.BackupIndex)
+	}
+
+	m := *target
+	m.Status = store.MigrationRunning
+	m.Phase = "rollback"
+	m.Error = ""
+	s.migrationMu.Lock()
+	if s.migration != nil {
+		s.migrationMu.Unlock()
+		return nil, store.ErrMigrationRunning
+	}
+	s.migration = &m
+	s.migrationMu.Unlock()
+
+	ctx := s.ctx
+	if err := s.swap(target.BackupIndex); err != nil {
+		// Nothing was swapped, the migration stays completed
+		s.finishMigration(func(m *store.Migration) { *m = *target })
+		return nil, err
+	}
+
+	// The backup now holds the rolled back version, so articles saved since
+	// the migration are copied back into the live index
+	catchUpErr := s.copyDocuments(ctx, target.BackupIndex, IndexArticles, fetchedSince(target.EndedAt), nil)
+	result := s.finishMigration(func(m *store.Migration) {
+		m.Status = store.MigrationRolledBack
+		m.Phase = "done"
+		if catchUpErr != nil {
+			m.Error = "catch up after rollback: " + catchUpErr.Error()
+		}
+	})
+
+	log.Printf("[NEWS] Index migration %s rolled back to v%d", id, result.FromVersion)
+	return &result, nil
+}
+
+func (s *Store) runningMigration() (store.Migration, bool) {
+	s.migrationMu.Lock()
+	defer s.migrationMu.Unlock()
+	if s.migration == nil {
+		return store.Migration{}, false
+	}
+	return *s.migration, true
+}
+
+// updateMigration changes the running migration and saves its record
+func (s *Store) updateMigration(fn func(m *store.Migration)) {
+	s.migrationMu.Lock()
+	if s.migration == nil {
+		s.migrationMu.Unlock()
+		return
+	}
+	fn(s.migration)
+	s.migration.UpdatedAt = time.Now()
+	m := *s.migration
+	s.migrationMu.Unlock()
+
+	s.saveMigration(&m)
+}
+
+// finishMigration applies the final changes, saves the record and clears
+// the running migration
+func (s *Store) finishMigration(fn func(m *store.Migration)) store.Migration {
+	s.migrationMu.Lock()
+	fn(s.migration)
+	now := time.Now()
+	s.migration.UpdatedAt = now
+	if s.migration.Status != store.MigrationRolledBack {
+		s.migration.EndedAt = now
+	}
+	m := *s.migration
+	s.migration = nil
+	s.migrationMu.Unlock()
+
+	s.saveMigration(&m)
+	return m
+}
+
+// saveMigration records a migration; progress is best effort and only logged
+// on failure
+func (s *Store) saveMigration(m *store.Migration) {
+	if _, err := s.Client.Index(IndexMigrations).AddDocuments([]*store.Migration{m}, nil); err != nil {
+		log.Printf("[NEWS] Failed to save migration %s: %v", m.ID, err)
+	}
+}
+
+// swap exchanges the live articles index with other atomically
+func (s *Store) swap(other string) error {
+	err := s.wait(s.Client.SwapIndexes([]*meilisearch.SwapIndexesParams{
+		{Indexes: []string{IndexArticles, other}},
+	}))
+	if err != nil {
+		return fmt.Errorf("swap %s with %s: %w", IndexArticles, other, err)
+	}
+	s.resetFilterable()
+	return nil
+}
+
+func fetchedSince(t time.Time) string {
+	return fmt.Sprintf("fetched_at_ts >= %d", t.Unix())
+}
+
+// copyDocuments copies the documents matching filter (all when empty) and
+// reports each batch to progress when set
+func (s *Store) copyDocuments(ctx context.Context, from, to, filter string, progress func(n int, total int64)) error {
+	query := &meilisearch.DocumentsQuery{Limit: migrationBatchSize}
+	if filter != "" {
+		query.Filter = filter
+	}
+
+	for offset := int64(0); ; offset += migrationBatchSize {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		query.Offset = offset
+
+		var page meilisearch.DocumentsResult
+		if err := s.Client.Index(from).GetDocuments(query, &page); err != nil {
+			return fmt.Errorf("read %s at offset %d: %w", from, offset, err)
+		}
+		if len(page.Results) == 0 {
+			return nil
+		}
+		if err := s.wait(s.Client.Index(to).AddDocuments(page.Results, nil)); err != nil {
+			return fmt.Errorf("write %s: %w", to, err)
+		}
+		if progress != nil {
+			progress(len(page.Results), page.Total)
+		}
+		if len(page.Results) < migrationBatchSize {
+			return nil
+		}
+	}
+}
+
+// indexDocuments writes the documents of source to the index. The pipeline
+// does not produce bodies or stories, so those are kept from the live index,
+// as is the sentiment of documents that have none.
+func (s *Store) indexDocuments(ctx context.Context, to string, source store.DocumentSource, progress func(n int, total int64)) error {
+	return source(ctx, func(docs []store.ArticleDoc) error {
+		ids := make([]string, len(docs))
+		for i := range docs {
+			ids[i] = docs[i].ID
+		}
+		var page meilisearch.DocumentsResult
+		if err := s.Client.Index(IndexArticles).GetDocuments(&meilisearch.DocumentsQuery{
+			Ids:   ids,
+			Limit: int64(len(ids)),
+		}, &page); err != nil {
+			return fmt.Errorf("read live documents: %w", err)
+		}
+		var live []store.ArticleDoc
+		if err := page.Results.Decode(&live); err != nil {
+			return fmt.Errorf("decode live documents: %w", err)
+		}
+		byID := make(map[string]store.ArticleDoc, len(live))
+		for _, d := range live {
+			byID[d.ID] = d
+		}
+
+		for i := range docs {
+			d := &docs[i]
+			if old, ok := byID[d.ID]; ok {
+				if d.Body == "" {
+					d.Body, d.BodyStatus, d.BodyError = old.Body, old.BodyStatus, old.BodyError
+				}
+				if d.StoryID == "" {
+					d.StoryID = old.StoryID
+				}
+				if d.Sentiment == "" {
+					d.Sentiment = old.Sentiment
+				}
+			}
+			d.SetTimestamps()
+		}
+
+		if err := s.wait(s.Client.Index(to).AddDocuments(docs, nil)); err != nil {
+			return fmt.Errorf("write %s: %w", to, err)
+		}
+		if progress != nil {
+			progress(len(docs), 0)
+		}
+		return nil
+	})
+}
@@ -0,0 +1,95 @@
+package meili
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"dx-unified/internal/news/store"
+
+	"github.com/meilisearch/meilisearch-go"
+)
+
+const backfillBatchSize = 1000
+
+// BackfillTimestamps adds published_at_ts/fetched_at_ts to documents stored
+// before those fields existed. It is idempotent and cheap when nothing is
+// missing.
+func (s *Store) BackfillTimestamps() error {
+	index := s.Client.Index(IndexArticles)
+
+	// Filtering needs the settings task from EnsureIndexes to be applied
+	// first; give Meilisearch a moment instead of failing on a fresh index.
+	var probe meilisearch.DocumentsResult
+	var err error
+	for attempt := 0; attempt < 10; attempt++ {
+		err = index.GetDocuments(&meilisearch.DocumentsQuery{
+			Limit:  1,
+			Fields: []string{"id"},
+			Filter: "published_at_ts NOT EXISTS",
+		}, &probe)
+		if err == nil {
+			break
+		}
+		time.Sleep(3 * time.Second)
+	}
+	if err != nil {
+		return fmt.Errorf("probe documents: %w", err)
+	}
+	if probe.Total == 0 {
+		return nil
+	}
+
+	log.Printf("[NEWS] Backfilling numeric timestamps for %d articles...", probe.Total)
+
+	updated := 0
+	for offset := int64(0); ; offset += backfillBatchSize {
+		var page meilisearch.DocumentsResult
+		err := index.GetDocuments(&meilisearch.DocumentsQuery{
+			Offset: offset,
+			Limit:  backfillBatchSize,
+			Fields: []string{"id", "published_at", "fetched_at", "published_at_ts"},
+		}, &page)
+		if err != nil {
+			return fmt.Errorf("read documents at offset %d: %w", offset, err)
+		}
+
+		var docs []struct {
+			ID            string    `json:"id"`
+			PublishedAt   time.Time `json:"published_at"`
+			FetchedAt     time.Time `json:"fetched_at"`
+			PublishedAtTS *int64    `json:"published_at_ts"`
+		}
+		if err := page.Results.Decode(&docs); err != nil {
+			return fmt.Errorf("decode documents: %w", err)
+		}
+
+		var patches []map[string]interface{}
+		for _, d := range docs {
+			if d.PublishedAtTS != nil {
+				continue
+			}
+			doc := store.ArticleDoc{PublishedAt: d.PublishedAt, FetchedAt: d.FetchedAt}
+			doc.SetTimestamps()
+			patches = append(patches, map[string]interface{}{
+				"id":              d.ID,
+				"published_at_ts": doc.PublishedAtTS,
+				"fetched_at_ts":   doc.FetchedAtTS,
+			})
+		}
+
+		if len(patches) > 0 {
+			if _, err := index.UpdateDocuments(patches, nil); err != nil {
+				return fmt.Errorf("update documents: %w", err)
+			}
+			updated += len(patches)
+		}
+
+		if int64(len(docs)) < backfillBatchSize {
+			break
+		}
+	}
+
+	log.Printf("[NEWS] Timestamp backfill queued for %d articles", updated)
+	return nil
+}
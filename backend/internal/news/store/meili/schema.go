@@ -0,0 +1,320 @@
+package meili
+
+import (
+	"fmt"
+	"log"
+	"slices"
+	"time"
+
+	"dx-unified/internal/news/store"
+
+	"github.com/meilisearch/meilisearch-go"
+)
+
+const taskPollInterval = 200 * time.Millisecond
+
+// articleVersion is one version of the articles index definition
+type articleVersion struct {
+	store.IndexVersion
+	settings func() *meilisearch.Settings
+}
+
+// articleVersions lists the articles index definitions, oldest first. The
+// live index is never changed in place: changing settings means appending a
+// version and migrating to it through /admin/search/migrations.
+var articleVersions = []articleVersion{
+	{
+		IndexVersion: store.IndexVersion{Version: 1, Description: "initial settings"},
+		settings:     articleSettingsV1,
+	},
+	{
+		IndexVersion: store.IndexVersion{Version: 2, Description: "sentiment facet, typo tolerance for short Korean words"},
+		settings:     articleSettingsV2,
+	},
+}
+
+func latestArticleVersion() articleVersion {
+	return articleVersions[len(articleVersions)-1]
+}
+
+func articleVersionOf(version int) (articleVersion, bool) {
+	for _, v := range articleVersions {
+		if v.Version == version {
+			return v, true
+		}
+	}
+	return articleVersion{}, false
+}
+
+// versionedIndex names the index a version is built in
+func versionedIndex(version int) string {
+	return fmt.Sprintf("%s_v%d", IndexArticles, version)
+}
+
+func articleSettingsV1() *meilisearch.Settings {
+	return &meilisearch.Settings{
+		SearchableAttributes: []string{
+			"title",
+			"summary",
+			"body",
+			"tags",
+		},
+		FilterableAttributes: []string{
+			"source",
+			"publisher",
+			"published_at",
+			"published_at_ts",
+			"fetched_at_ts",
+			"dup_state",
+			"story_id",
+			"tags",
+			"body_status",
+		},
+		SortableAttributes: []string{
+			"published_at",
+			"fetched_at",
+			"published_at_ts",
+			"fetched_at_ts",
+			"market_relevance_score",
+		},
+	}
+}
+
+func articleSettingsV2() *meilisearch.Settings {
+	settings := articleSettingsV1()
+	settings.FilterableAttributes = append(settings.FilterableAttributes, "sentiment")
+	// Korean words are short, so one typo is allowed from 4 characters
+	// (the default of 5 misses most names); tags and tickers stay exact
+	settings.TypoTolerance = &meilisearch.TypoTolerance{
+		Enabled: true,
+		MinWordSizeForTypos: meilisearch.MinWordSizeForTypos{
+			OneTypo:  4,
+			TwoTypos: 8,
+		},
+		DisableOnAttributes: []string{"tags"},
+		DisableOnNumbers:    true,
+	}
+	return settings
+}
+
+func runsSettings() *meilisearch.Settings {
+	return &meilisearch.Settings{
+		FilterableAttributes: []string{
+			"status",
+			"started_at",
+		},
+		SortableAttributes: []string{
+			"started_at",
+			"ended_at",
+		},
+	}
+}
+
+// EnsureIndexes creates missing indexes with their latest settings and waits
+// for them to be ready. An articles index created before versioning is
+// brought to version 1 in place (see adoptUnversioned); other existing
+// indexes are left untouched and an articles index behind the latest
+// version is only reported.
+func (s *Store) EnsureIndexes() error {
+	if _, err := s.ensureIndex(IndexMigrations, "id", nil); err != nil {
+		return fmt.Errorf("failed to create migrations index: %w", err)
+	}
+
+	latest := latestArticleVersion()
+	created, err := s.ensureIndex(IndexArticles, "id", latest.settings())
+	if err != nil {
+		return fmt.Errorf("failed to create articles index: %w", err)
+	}
+	if created {
+		if err := s.wait(s.Client.Index(IndexArticles).UpdateSynonyms(&store.DefaultSynonyms)); err != nil {
+			return fmt.Errorf("failed to update synonyms: %w", err)
+		}
+		now := time.Now()
+		s.saveMigration(&store.Migration{
+			ID:        "init",
+			ToVersion: latest.Version,
+			Source:    "create",
+			Status:    store.MigrationCompleted,
+			Phase:     "done",
+			StartedAt: now,
+			UpdatedAt: now,
+			EndedAt:   now,
+		})
+	} else if status, err := s.MigrationStatus(); err != nil {
+		log.Printf("[NEWS] Failed to read index migrations: %v", err)
+	} else if len(status.Migrations) == 0 {
+		if err := s.adoptUnversioned(); err != nil {
+			return fmt.Errorf("failed to upgrade unversioned articles index: %w", err)
+		}
+		if latest.Version > 1 {
+			log.Printf("[NEWS] Articles index is at v1, v%d is available (POST /admin/search/migrations)", latest.Version)
+		}
+	} else if status.CurrentVersion < status.LatestVersion {
+		log.Printf("[NEWS] Articles index is at v%d, v%d is available (POST /admin/search/migrations)",
+			status.CurrentVersion, status.LatestVersion)
+	}
+
+	if _, err := s.ensureIndex(IndexRuns, "run_id", runsSettings()); err != nil {
+		return fmt.Errorf("failed to create runs index: %w", err)
+	}
+	return nil
+}
+
+// adoptUnversioned brings an articles index deployed before versioning to
+// version 1: the v1 filterable and sortable attributes are added to the
+// ones it has (date filters, the timestamp backfill and the migration
+// catch-up filter on them) and the default synonyms are applied if it has
+// none. Other settings are kept, so the index may be ahead of v1; migrating
+// to the latest version rebuilds it anyway.
+func (s *Store) adoptUnversioned() error {
+	index := s.Client.Index(IndexArticles)
+	current, err := index.GetSettings()
+	if err != nil {
+		return fmt.Errorf("read settings: %w", err)
+	}
+	v1 := articleSettingsV1()
+	update := &meilisearch.Settings{
+		FilterableAttributes: union(current.FilterableAttributes, v1.FilterableAttributes),
+		SortableAttributes:   union(current.SortableAttributes, v1.SortableAttributes),
+	}
+	log.Printf("[NEWS] Applying v1 settings to the unversioned articles index")
+	if err := s.wait(index.UpdateSettings(update)); err != nil {
+		return fmt.Errorf("update settings: %w", err)
+	}
+
+	synonyms, err := s.GetSynonyms()
+	if err != nil {
+		return fmt.Errorf("read synonyms: %w", err)
+	}
+	if len(synonyms) == 0 {
+		if err := s.wait(index.UpdateSynonyms(&store.DefaultSynonyms)); err != nil {
+			return fmt.Errorf("update synonyms: %w", err)
+		}
+	}
+
+	now := time.Now()
+	s.saveMigration(&store.Migration{
+		ID:        "adopt",
+		ToVersion: 1,
+		Source:    "adopt",
+		Status:    store.MigrationCompleted,
+		Phase:     "done",
+		StartedAt: now,
+		UpdatedAt: now,
+		EndedAt:   now,
+	})
+	return nil
+}
+
+// union returns a followed by the values of b it lacks
+func union(a, b []string) []string {
+	out := append([]string{}, a...)
+	seen := make(map[string]bool, len(a))
+	for _, v := range a {
+		seen[v] = true
+	}
+	for _, v := range b {
+		if !seen[v] {
+			out = append(out, v)
+			seen[v] = true
+		}
+	}
+	return out
+}
+
+// ensureIndex creates the index with settings if it does not exist and
+// reports whether it did
+func (s *Store) ensureIndex(uid, primaryKey string, settings *meilisearch.Settings) (bool, error) {
+	if _, err := s.Client.GetIndex(uid); err == nil {
+		return false, nil
+	}
+
+	log.Printf("Creating index: %s", uid)
+	if err := s.createIndex(uid, primaryKey, settings); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (s *Store) createIndex(uid, primaryKey string, settings *meilisearch.Settings) error {
+	if err := s.wait(s.Client.CreateIndex(&meilisearch.IndexConfig{
+		Uid:        uid,
+		PrimaryKey: primaryKey,
+	})); err != nil {
+		return err
+	}
+	if settings == nil {
+		return nil
+	}
+	return s.wait(s.Client.Index(uid).UpdateSettings(settings))
+}
+
+// wait blocks until an enqueued task has been processed and fails unless
+// it succeeded. It takes the results of the call enqueuing the task.
+func (s *Store) wait(info *meilisearch.TaskInfo, err error) error {
+	if err != nil {
+		return err
+	}
+	task, err := s.Client.WaitForTask(info.TaskUID, taskPollInterval)
+	if err != nil {
+		return err
+	}
+	if task.Status != meilisearch.TaskStatusSucceeded {
+		return fmt.Errorf("task %d (%s) %s: %s", info.TaskUID, task.Type, task.Status, task.Error.Message)
+	}
+	return nil
+}
+
+// checkFilterable returns a MigrationRequiredError when q filters or facets
+// on an attribute the live articles index does not have yet, e.g. sentiment
+// on a v1 index, which Meilisearch would reject as an invalid filter
+func (s *Store) checkFilterable(q *store.SearchQuery) error {
+	attributes := append([]string{}, q.Facets...)
+	if len(q.Filter.Sentiments) > 0 {
+		attributes = append(attributes, "sentiment")
+	}
+	if len(attributes) == 0 {
+		return nil
+	}
+
+	filterable, err := s.liveFilterable()
+	if err != nil {
+		return err
+	}
+	for _, a := range attributes {
+		if filterable[a] {
+			continue
+		}
+		for _, v := range articleVersions {
+			if slices.Contains(v.settings().FilterableAttributes, a) {
+				return &store.MigrationRequiredError{Attribute: a, Version: v.Version}
+			}
+		}
+	}
+	return nil
+}
+
+// liveFilterable returns the filterable attributes of the live articles
+// index, read once and again after each swap
+func (s *Store) liveFilterable() (map[string]bool, error) {
+	s.filterableMu.Lock()
+	defer s.filterableMu.Unlock()
+	if s.filterable != nil {
+		return s.filterable, nil
+	}
+	settings, err := s.Client.Index(IndexArticles).GetSettings()
+	if err != nil {
+		return nil, fmt.Errorf("read articles settings: %w", err)
+	}
+	s.filterable = make(map[string]bool, len(settings.FilterableAttributes))
+	for _, a := range settings.FilterableAttributes {
+		s.filterable[a] = true
+	}
+	return s.filterable, nil
+}
+
+func (s *Store) resetFilterable() {
+	s.filterableMu.Lock()
+	s.filterable = nil
+	s.filterableMu.Unlock()
+}
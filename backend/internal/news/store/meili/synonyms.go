@@ -0,0 +1,29 @@
+package meili
+
+import (
+	"dx-unified/internal/news/store"
+)
+
+var _ store.SynonymStore = (*Store)(nil)
+
+// GetSynonyms returns the synonyms configured on the articles index
+func (s *Store) GetSynonyms() (map[string][]string, error) {
+	synonyms, err := s.Client.Index(IndexArticles).GetSynonyms()
+	if err != nil {
+		return nil, err
+	}
+	if synonyms == nil {
+		return map[string][]string{}, nil
+	}
+	return *synonyms, nil
+}
+
+// UpdateSynonyms replaces the synonyms of the articles index
+func (s *Store) UpdateSynonyms(synonyms map[string][]string) error {
+	if len(synonyms) == 0 {
+		_, err := s.Client.Index(IndexArticles).ResetSynonyms()
+		return err
+	}
+	_, err := s.Client.Index(IndexArticles).UpdateSynonyms(&synonyms)
+	return err
+}
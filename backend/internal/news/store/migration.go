@@ -0,0 +1,93 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+var (
+	// ErrMigrationRunning is returned when an index migration is in progress
+	ErrMigrationRunning = errors.New("index migration already running")
+	// ErrIndexUpToDate is returned when the index is at the latest version
+	ErrIndexUpToDate = errors.New("index is already at the latest version")
+	// ErrCannotRollback is returned for migrations that cannot be undone
+	ErrCannotRollback = errors.New("migration cannot be rolled back")
+)
+
+// MigrationRequiredError is returned for a query filtering or faceting on
+// an attribute the live index only supports from a later version
+type MigrationRequiredError struct {
+	Attribute string
+	Version   int // first index version supporting the attribute
+}
+
+func (e *MigrationRequiredError) Error() string {
+	return fmt.Sprintf("%s is available from index version %d, migrate the index (POST /admin/search/migrations)", e.Attribute, e.Version)
+}
+
+// IndexMigrator is implemented by backends with versioned index definitions.
+// A migration builds the latest version next to the live index, then swaps
+// them, so searches keep working throughout.
+type IndexMigrator interface {
+	MigrationStatus() (*MigrationStatus, error)
+	// StartMigration starts migrating to the latest version in the background
+	StartMigration(opts MigrationOptions) (*Migration, error)
+	// RollbackMigration swaps the previous version back in
+	RollbackMigration(id string) (*Migration, error)
+}
+
+// DocumentSource feeds documents to a reindex in batches
+type DocumentSource func(ctx context.Context, fn func([]ArticleDoc) error) error
+
+// Migration sources
+const (
+	MigrationSourceIndex   = "index"   // copy the documents of the live index
+	MigrationSourceArchive = "archive" // copy the live index, then rebuild a date range from the raw archive
+)
+
+// MigrationOptions controls where a migration takes its documents from
+type MigrationOptions struct {
+	Source    string
+	Documents DocumentSource // required for MigrationSourceArchive
+}
+
+// Migration statuses
+const (
+	MigrationRunning    = "running"
+	MigrationCompleted  = "completed"
+	MigrationFailed     = "failed"
+	MigrationRolledBack = "rolled_back"
+)
+
+// Migration records one index migration and its progress
+type Migration struct {
+	ID          string    `json:"id"`
+	FromVersion int       `json:"from_version"`
+	ToVersion   int       `json:"to_version"`
+	Source      string    `json:"source"`
+	Status      string    `json:"status"`
+	Phase       string    `json:"phase,omitempty"` // create, reindex, replay, catch_up, swap, done
+	Indexed     int64     `json:"indexed"`
+	Total       int64     `json:"total"`                  // documents to copy, when known
+	BackupIndex string    `json:"backup_index,omitempty"` // index holding the replaced version after the swap
+	StartedAt   time.Time `json:"started_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+	EndedAt     time.Time `json:"ended_at,omitempty"`
+	Error       string    `json:"error,omitempty"`
+}
+
+// IndexVersion describes one version of the index definition
+type IndexVersion struct {
+	Version     int    `json:"version"`
+	Description string `json:"description"`
+}
+
+// MigrationStatus is the version state of the index
+type MigrationStatus struct {
+	CurrentVersion int            `json:"current_version"`
+	LatestVersion  int            `json:"latest_version"`
+	Versions       []IndexVersion `json:"versions"`
+	Migrations     []Migration    `json:"migrations"` // newest first
+}
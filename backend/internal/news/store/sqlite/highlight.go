@@ -0,0 +1,126 @@
+package sqlite
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+
+	"dx-unified/internal/news/store"
+)
+
+// Body snippets are cropped to about this many words around the first
+// match, like Meilisearch's cropLength
+const (
+	cropWords  = 40
+	cropMarker = "…"
+)
+
+// highlightHits wraps the query terms (and their synonyms) found in each
+// hit in the highlight tags
+func highlightHits(hits []store.ArticleDoc, terms [][]string) map[string]store.Highlight {
+	var words []string
+	for _, alts := range terms {
+		for _, term := range alts {
+			words = append(words, lowerRunes(term))
+		}
+	}
+	// Longer terms first so "삼성전자" wins over "삼성" at the same position
+	sort.Slice(words, func(i, j int) bool { return len([]rune(words[i])) > len([]rune(words[j])) })
+
+	out := make(map[string]store.Highlight, len(hits))
+	for _, h := range hits {
+		out[h.ID] = store.Highlight{
+			Title:   markTerms(h.Title, words),
+			Summary: markTerms(h.Summary, words),
+			Body:    markTerms(cropBody(h.Body, words), words),
+		}
+	}
+	return out
+}
+
+// lowerRunes lower-cases rune by rune so offsets match the original text
+func lowerRunes(s string) string {
+	runes := []rune(s)
+	for i, r := range runes {
+		runes[i] = unicode.ToLower(r)
+	}
+	return string(runes)
+}
+
+// matchAt returns the length in runes of the term matching at i, or 0
+func matchAt(lower []rune, i int, words []string) int {
+	for _, w := range words {
+		wr := []rune(w)
+		if len(wr) == 0 || i+len(wr) > len(lower) {
+			continue
+		}
+		if string(lower[i:i+len(wr)]) == w {
+			return len(wr)
+		}
+	}
+	return 0
+}
+
+func markTerms(text string, words []string) string {
+	if text == "" || len(words) == 0 {
+		return text
+	}
+	runes := []rune(text)
+	lower := []rune(lowerRunes(text))
+
+	var b strings.Builder
+	for i := 0; i < len(runes); {
+		if n := matchAt(lower, i, words); n > 0 {
+			b.WriteString(store.HighlightPreTag)
+			b.WriteString(string(runes[i : i+n]))
+			b.WriteString(store.HighlightPostTag)
+			i += n
+			continue
+		}
+		b.WriteRune(runes[i])
+		i++
+	}
+	return b.String()
+}
+
+// cropBody keeps cropWords words starting a little before the first word
+// containing a match, or the beginning of the body without a match
+func cropBody(body string, words []string) string {
+	fields := strings.Fields(body)
+	if len(fields) <= cropWords {
+		return strings.Join(fields, " ")
+	}
+
+	start := 0
+	for i, f := range fields {
+		lower := []rune(lowerRunes(f))
+		found := false
+		for j := range lower {
+			if matchAt(lower, j, words) > 0 {
+				found = true
+				break
+			}
+		}
+		if found {
+			start = i - cropWords/4
+			break
+		}
+	}
+	if start < 0 {
+		start = 0
+	}
+	end := start + cropWords
+	if end > len(fields) {
+		end = len(fields)
+		start = end - cropWords
+	}
+
+	snippet := strings.Join(fields[start:end], " ")
+	if start > 0 {
+		snippet = cropMarker + snippet
+	}
+	if end < len(fields) {
+		snippet += cropMarker
+	}
+	return snippet
+}
@@ -0,0 +1,542 @@
+package sqlite
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"dx-unified/internal/news/store"
+	"dx-unified/internal/shared/fts"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// Store is a NewsStore backed by SQLite with an FTS5 full-text index.
+// Requires building with the sqlite_fts5 tag (see Dockerfile).
+type Store struct {
+	DB *sql.DB
+
+	mu       sync.RWMutex
+	synonyms map[string][]string // lower-cased term -> alternatives
+}
+
+var (
+	_ store.NewsStore    = (*Store)(nil)
+	_ store.SynonymStore = (*Store)(nil)
+)
+
+// New opens (or creates) the news database at dbPath
+func New(dbPath string) (*Store, error) {
+	if dir := filepath.Dir(dbPath); dir != "." {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return nil, err
+		}
+	}
+
+	db, err := sql.Open("sqlite3", dbPath+"?_journal_mode=WAL&_busy_timeout=5000")
+	if err != nil {
+		return nil, err
+	}
+	if err := db.Ping(); err != nil {
+		return nil, err
+	}
+
+	s := &Store{DB: db}
+	if err := s.initSchema(); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to init news schema: %w", err)
+	}
+	if err := s.loadSynonyms(); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to load synonyms: %w", err)
+	}
+
+	log.Printf("[NEWS] SQLite store opened at %s", dbPath)
+	return s, nil
+}
+
+// Close closes the database
+func (s *Store) Close() error {
+	return s.DB.Close()
+}
+
+func (s *Store) initSchema() error {
+	// The trigram tokenizer indexes every 3-character sequence, which works
+	// for Korean text without a morphological analyzer.
+	schema := `
+	CREATE TABLE IF NOT EXISTS articles (
+		id TEXT PRIMARY KEY,
+		title TEXT NOT NULL DEFAULT '',
+		summary TEXT NOT NULL DEFAULT '',
+		body TEXT NOT NULL DEFAULT '',
+		url TEXT NOT NULL DEFAULT '',
+		canonical_url TEXT NOT NULL DEFAULT '',
+		source TEXT NOT NULL DEFAULT '',
+		publisher TEXT NOT NULL DEFAULT '',
+		published_at INTEGER NOT NULL DEFAULT 0,
+		fetched_at INTEGER NOT NULL DEFAULT 0,
+		dup_state TEXT NOT NULL DEFAULT '',
+		dup_of TEXT NOT NULL DEFAULT '',
+		dup_score REAL NOT NULL DEFAULT 0,
+		story_id TEXT NOT NULL DEFAULT '',
+		tags TEXT NOT NULL DEFAULT '[]',
+		body_status TEXT NOT NULL DEFAULT '',
+		body_error TEXT NOT NULL DEFAULT '',
+		sentiment TEXT NOT NULL DEFAULT ''
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_articles_published_at ON articles(published_at);
+	CREATE INDEX IF NOT EXISTS idx_articles_source ON articles(source);
+	CREATE INDEX IF NOT EXISTS idx_articles_story_id ON articles(story_id);
+
+	CREATE VIRTUAL TABLE IF NOT EXISTS articles_fts USING fts5(
+		title, summary, body, tags,
+		content='articles', content_rowid='rowid',
+		tokenize='trigram'
+	);
+
+	CREATE TRIGGER IF NOT EXISTS articles_ai AFTER INSERT ON articles BEGIN
+		INSERT INTO articles_fts(rowid, title, summary, body, tags)
+		VALUES (new.rowid, new.title, new.summary, new.body, new.tags);
+	END;
+
+	CREATE TRIGGER IF NOT EXISTS articles_ad AFTER DELETE ON articles BEGIN
+		INSERT INTO articles_fts(articles_fts, rowid, title, summary, body, tags)
+		VALUES ('delete', old.rowid, old.title, old.summary, old.body, old.tags);
+	END;
+
+	CREATE TRIGGER IF NOT EXISTS articles_au AFTER UPDATE ON articles BEGIN
+		INSERT INTO articles_fts(articles_fts, rowid, title, summary, body, tags)
+		VALUES ('delete', old.rowid, old.title, old.summary, old.body, old.tags);
+		INSERT INTO articles_fts(rowid, title, summary, body, tags)
+		VALUES (new.rowid, new.title, new.summary, new.body, new.tags);
+	END;
+
+	CREATE TABLE IF NOT EXISTS runs (
+		run_id TEXT PRIMARY KEY,
+		started_at INTEGER NOT NULL,
+		ended_at INTEGER NOT NULL,
+		status TEXT NOT NULL,
+		stats_json TEXT NOT NULL DEFAULT '{}',
+		errors_json TEXT NOT NULL DEFAULT '[]'
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_runs_started_at ON runs(started_at);
+
+	CREATE TABLE IF NOT EXISTS synonyms (
+		term TEXT PRIMARY KEY,
+		synonyms_json TEXT NOT NULL DEFAULT '[]'
+	);
+	`
+
+	// Columns added after the first release of this schema
+	if err := s.ensureColumn("articles", "story_id", "TEXT NOT NULL DEFAULT ''"); err != nil {
+		return err
+	}
+	if err := s.ensureColumn("articles", "sentiment", "TEXT NOT NULL DEFAULT ''"); err != nil {
+		return err
+	}
+
+	_, err := s.DB.Exec(schema)
+	return err
+}
+
+// ensureColumn adds a column to an existing table if it is missing
+func (s *Store) ensureColumn(table, column, definition string) error {
+	rows, err := s.DB.Query(fmt.Sprintf("PRAGMA table_info(%s)", table))
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	found, exists := false, false
+	for rows.Next() {
+		exists = true
+		var cid, notNull, pk int
+		var name, colType string
+		var dflt sql.NullString
+		if err := rows.Scan(&cid, &name, &colType, &notNull, &dflt, &pk); err != nil {
+			return err
+		}
+		if name == column {
+			found = true
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	rows.Close()
+
+	// A missing table is created by the schema with the column included
+	if !exists || found {
+		return nil
+	}
+	_, err = s.DB.Exec(fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s", table, column, definition))
+	return err
+}
+
+const articleColumns = `id, title, summary, body, url, canonical_url, source, publisher,
+	published_at, fetched_at, dup_state, dup_of, dup_score, story_id, tags, body_status, body_error, sentiment`
+
+func (s *Store) SaveArticles(articles []store.ArticleDoc) error {
+	if len(articles) == 0 {
+		return nil
+	}
+
+	tx, err := s.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// Body fields, tags and sentiment are only overwritten when the new
+	// value is non-empty, matching Meilisearch's partial document updates.
+	stmt, err := tx.Prepare(`
+		INSERT INTO articles (` + articleColumns + `)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(id) DO UPDATE SET
+			title = excluded.title,
+			summary = excluded.summary,
+			body = CASE WHEN excluded.body != '' THEN excluded.body ELSE articles.body END,
+			url = excluded.url,
+			canonical_url = excluded.canonical_url,
+			source = excluded.source,
+			publisher = excluded.publisher,
+			published_at = excluded.published_at,
+			fetched_at = excluded.fetched_at,
+			dup_state = excluded.dup_state,
+			dup_of = excluded.dup_of,
+			dup_score = excluded.dup_score,
+			story_id = excluded.story_id,
+			tags = CASE WHEN excluded.tags != '[]' THEN excluded.tags ELSE articles.tags END,
+			body_status = CASE WHEN excluded.body_status != '' THEN excluded.body_status ELSE articles.body_status END,
+			body_error = CASE WHEN excluded.body_status != '' THEN excluded.body_error ELSE articles.body_error END,
+			sentiment = CASE WHEN excluded.sentiment != '' THEN excluded.sentiment ELSE articles.sentiment END
+	`)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	for _, a := range articles {
+		tags := a.Tags
+		if tags == nil {
+			tags = []string{}
+		}
+		tagsJSON, _ := json.Marshal(tags)
+
+		_, err := stmt.Exec(
+			a.ID, a.Title, a.Summary, a.Body, a.URL, a.CanonicalURL, a.Source, a.Publisher,
+			a.PublishedAt.Unix(), a.FetchedAt.Unix(), a.DupState, a.DupOf, a.DupScore, a.StoryID,
+			string(tagsJSON), a.BodyStatus, a.BodyError, a.Sentiment,
+		)
+		if err != nil {
+			return fmt.Errorf("save article %s: %w", a.ID, err)
+		}
+	}
+
+	return tx.Commit()
+}
+
+func (s *Store) SaveRun(run *store.RunLog) error {
+	statsJSON, err := json.Marshal(run.Stats)
+	if err != nil {
+		return err
+	}
+	errorsJSON, err := json.Marshal(run.Errors)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.DB.Exec(`
+		INSERT INTO runs (run_id, started_at, ended_at, status, stats_json, errors_json)
+		VALUES (?, ?, ?, ?, ?, ?)
+		ON CONFLICT(run_id) DO UPDATE SET
+			started_at = excluded.started_at,
+			ended_at = excluded.ended_at,
+			status = excluded.status,
+			stats_json = excluded.stats_json,
+			errors_json = excluded.errors_json
+	`, run.RunID, run.StartedAt.UnixMilli(), run.EndedAt.UnixMilli(), run.Status, string(statsJSON), string(errorsJSON))
+	return err
+}
+
+func (s *Store) GetArticle(id string) (*store.ArticleDoc, error) {
+	row := s.DB.QueryRow(`SELECT `+articleColumns+` FROM articles WHERE id = ?`, id)
+	doc, err := scanArticle(row)
+	if err == sql.ErrNoRows {
+		return nil, store.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
+
+func (s *Store) SearchArticles(q *store.SearchQuery) (*store.SearchResult, error) {
+	terms := s.queryTerms(q.Query)
+	where, args := buildWhere(q, terms)
+
+	var total int64
+	countQuery := `SELECT COUNT(*) FROM articles a WHERE ` + where
+	if err := s.DB.QueryRow(countQuery, args...).Scan(&total); err != nil {
+		return nil, fmt.Errorf("count articles: %w", err)
+	}
+
+	query := `SELECT ` + prefixColumns("a") + ` FROM articles a WHERE ` + where +
+		` ORDER BY ` + buildOrderBy(q.Sort)
+	if q.Limit > 0 {
+		query += fmt.Sprintf(" LIMIT %d OFFSET %d", q.Limit, q.Offset)
+	}
+
+	rows, err := s.DB.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("search articles: %w", err)
+	}
+	defer rows.Close()
+
+	hits := []store.ArticleDoc{}
+	for rows.Next() {
+		doc, err := scanArticle(rows)
+		if err != nil {
+			return nil, err
+		}
+		hits = append(hits, *doc)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	rows.Close()
+
+	res := &store.SearchResult{Total: total, Hits: hits}
+	if len(q.Facets) > 0 {
+		if res.Facets, err = s.facets(q.Facets, where, args); err != nil {
+			return nil, err
+		}
+	}
+	if q.Highlight {
+		res.Highlights = highlightHits(hits, terms)
+	}
+	return res, nil
+}
+
+var facetColumns = map[string]string{
+	"source":    "a.source",
+	"publisher": "a.publisher",
+	"dup_state": "a.dup_state",
+	"sentiment": "a.sentiment",
+	"story_id":  "a.story_id",
+}
+
+// facetLimit matches Meilisearch's default maxValuesPerFacet
+const facetLimit = 100
+
+// facets counts the values of each field over the articles matching where
+func (s *Store) facets(fields []string, where string, args []interface{}) (map[string]map[string]int64, error) {
+	out := make(map[string]map[string]int64, len(fields))
+	for _, field := range fields {
+		var query string
+		if field == "tags" {
+			query = `SELECT t.value, COUNT(*) FROM articles a, json_each(a.tags) t WHERE ` + where +
+				` AND t.value != '' GROUP BY 1 ORDER BY 2 DESC LIMIT ?`
+		} else {
+			col, ok := facetColumns[field]
+			if !ok {
+				return nil, fmt.Errorf("unknown facet: %s", field)
+			}
+			query = `SELECT ` + col + `, COUNT(*) FROM articles a WHERE ` + where +
+				` AND ` + col + ` != '' GROUP BY 1 ORDER BY 2 DESC LIMIT ?`
+		}
+
+		rows, err := s.DB.Query(query, append(args, facetLimit)...)
+		if err != nil {
+			return nil, fmt.Errorf("facet %s: %w", field, err)
+		}
+		counts := make(map[string]int64)
+		for rows.Next() {
+			var value string
+			var n int64
+			if err := rows.Scan(&value, &n); err != nil {
+				rows.Close()
+				return nil, err
+			}
+			counts[value] = n
+		}
+		err = rows.Err()
+		rows.Close()
+		if err != nil {
+			return nil, err
+		}
+		out[field] = counts
+	}
+	return out, nil
+}
+
+func (s *Store) ListRuns(limit int) ([]store.RunLog, error) {
+	rows, err := s.DB.Query(`
+		SELECT run_id, started_at, ended_at, status, stats_json, errors_json
+		FROM runs ORDER BY started_at DESC LIMIT ?
+	`, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	runs := []store.RunLog{}
+	for rows.Next() {
+		var r store.RunLog
+		var startedAt, endedAt int64
+		var statsJSON, errorsJSON string
+		if err := rows.Scan(&r.RunID, &startedAt, &endedAt, &r.Status, &statsJSON, &errorsJSON); err != nil {
+			return nil, err
+		}
+		r.StartedAt = time.UnixMilli(startedAt)
+		r.EndedAt = time.UnixMilli(endedAt)
+		_ = json.Unmarshal([]byte(statsJSON), &r.Stats)
+		_ = json.Unmarshal([]byte(errorsJSON), &r.Errors)
+		runs = append(runs, r)
+	}
+	return runs, rows.Err()
+}
+
+// queryTerms splits the query into terms, each followed by its synonyms
+func (s *Store) queryTerms(query string) [][]string {
+	var terms [][]string
+	for _, term := range strings.Fields(query) {
+		terms = append(terms, s.alternatives(term))
+	}
+	return terms
+}
+
+// buildWhere translates the query terms and filter into a WHERE clause.
+// A term matches when it or any of its synonyms does.
+func buildWhere(q *store.SearchQuery, terms [][]string) (string, []interface{}) {
+	conds := []string{"1=1"}
+	var args []interface{}
+
+	if match, likes := fts.SplitTerms(terms); match != "" || len(likes) > 0 {
+		if match != "" {
+			conds = append(conds, "a.rowid IN (SELECT rowid FROM articles_fts WHERE articles_fts MATCH ?)")
+			args = append(args, match)
+		}
+		// Trigram needs at least 3 characters; terms with shorter Korean
+		// words such as "금리" fall back to LIKE
+		for _, alts := range likes {
+			var ors []string
+			for _, term := range alts {
+				ors = append(ors, `a.title LIKE ? ESCAPE '\' OR a.summary LIKE ? ESCAPE '\' OR a.body LIKE ? ESCAPE '\'`)
+				pattern := "%" + fts.EscapeLike(term) + "%"
+				args = append(args, pattern, pattern, pattern)
+			}
+			conds = append(conds, "("+strings.Join(ors, " OR ")+")")
+		}
+	}
+
+	f := q.Filter
+	conds, args = appendIn(conds, args, "a.source", f.Sources)
+	conds, args = appendIn(conds, args, "a.publisher", f.Publishers)
+	if len(f.Tags) > 0 {
+		conds = append(conds, "EXISTS (SELECT 1 FROM json_each(a.tags) WHERE json_each.value IN ("+placeholders(len(f.Tags))+"))")
+		for _, t := range f.Tags {
+			args = append(args, t)
+		}
+	}
+	if f.DupState != "" {
+		conds = append(conds, "a.dup_state = ?")
+		args = append(args, f.DupState)
+	}
+	if f.StoryID != "" {
+		conds = append(conds, "a.story_id = ?")
+		args = append(args, f.StoryID)
+	}
+	conds, args = appendIn(conds, args, "a.sentiment", f.Sentiments)
+	if !f.PublishedFrom.IsZero() {
+		conds = append(conds, "a.published_at >= ?")
+		args = append(args, f.PublishedFrom.Unix())
+	}
+	if !f.PublishedTo.IsZero() {
+		conds = append(conds, "a.published_at <= ?")
+		args = append(args, f.PublishedTo.Unix())
+	}
+
+	return strings.Join(conds, " AND "), args
+}
+
+func appendIn(conds []string, args []interface{}, column string, values []string) ([]string, []interface{}) {
+	if len(values) == 0 {
+		return conds, args
+	}
+	conds = append(conds, column+" IN ("+placeholders(len(values))+")")
+	for _, v := range values {
+		args = append(args, v)
+	}
+	return conds, args
+}
+
+func placeholders(n int) string {
+	return strings.TrimSuffix(strings.Repeat("?, ", n), ", ")
+}
+
+var sortableColumns = map[string]string{
+	"published_at": "a.published_at",
+	"fetched_at":   "a.fetched_at",
+}
+
+func buildOrderBy(sorts []string) string {
+	var parts []string
+	for _, s := range sorts {
+		field, dir, _ := strings.Cut(s, ":")
+		col, ok := sortableColumns[field]
+		if !ok {
+			continue
+		}
+		if strings.EqualFold(dir, "asc") {
+			parts = append(parts, col+" ASC")
+		} else {
+			parts = append(parts, col+" DESC")
+		}
+	}
+	if len(parts) == 0 {
+		return "a.published_at DESC"
+	}
+	return strings.Join(parts, ", ")
+}
+
+func prefixColumns(alias string) string {
+	cols := strings.Split(articleColumns, ",")
+	for i, c := range cols {
+		cols[i] = alias + "." + strings.TrimSpace(c)
+	}
+	return strings.Join(cols, ", ")
+}
+
+type scanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanArticle(row scanner) (*store.ArticleDoc, error) {
+	var a store.ArticleDoc
+	var publishedAt, fetchedAt int64
+	var tagsJSON string
+
+	err := row.Scan(
+		&a.ID, &a.Title, &a.Summary, &a.Body, &a.URL, &a.CanonicalURL, &a.Source, &a.Publisher,
+		&publishedAt, &fetchedAt, &a.DupState, &a.DupOf, &a.DupScore, &a.StoryID, &tagsJSON, &a.BodyStatus, &a.BodyError, &a.Sentiment,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	a.PublishedAt = time.Unix(publishedAt, 0)
+	a.FetchedAt = time.Unix(fetchedAt, 0)
+	a.SetTimestamps()
+	_ = json.Unmarshal([]byte(tagsJSON), &a.Tags)
+	return &a, nil
+}
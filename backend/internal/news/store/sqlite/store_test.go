@@ -0,0 +1,140 @@
+package sqlite
+
+import (
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"dx-unified/internal/news/store"
+)
+
+// openTestStore needs FTS5: go test -tags sqlite_fts5
+func openTestStore(t *testing.T) *Store {
+	s, err := New(filepath.Join(t.TempDir(), "news.db"))
+	if err != nil && strings.Contains(err.Error(), "fts5") {
+		t.Skip(err)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { s.Close() })
+	return s
+}
+
+var day = time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+
+func testArticles() []store.ArticleDoc {
+	return []store.ArticleDoc{
+		{ID: "1", Title: "삼성전자 2분기 영업이익 급증", Summary: "반도체 업황 회복", Source: "naver", Publisher: "연합뉴스",
+			PublishedAt: day, DupState: "unique", StoryID: "1", Tags: []string{"005930"}, Sentiment: "positive"},
+		{ID: "2", Title: "[속보] 삼성전자 영업이익 급증", Source: "rss", Publisher: "한국경제",
+			PublishedAt: day.Add(time.Hour), DupState: "unique", StoryID: "1", Tags: []string{"005930"}, Sentiment: "positive"},
+		{ID: "3", Title: "한은 기준금리 동결", Summary: "물가 상승 우려", Source: "naver", Publisher: "연합뉴스",
+			PublishedAt: day.Add(2 * time.Hour), DupState: "unique", StoryID: "3", Sentiment: "neutral"},
+		{ID: "4", Title: "SK하이닉스 주가 급락", Source: "rss", Publisher: "매일경제",
+			PublishedAt: day.Add(24 * time.Hour), DupState: "duplicate", DupOf: "5", StoryID: "4", Tags: []string{"000660"}, Sentiment: "negative"},
+	}
+}
+
+func ids(hits []store.ArticleDoc) []string {
+	out := []string{}
+	for _, h := range hits {
+		out = append(out, h.ID)
+	}
+	return out
+}
+
+func TestSaveArticlesUpsert(t *testing.T) {
+	s := openTestStore(t)
+	if err := s.SaveArticles(testArticles()); err != nil {
+		t.Fatal(err)
+	}
+
+	// A reprocessed article without body, tags or sentiment keeps them
+	if err := s.SaveArticles([]store.ArticleDoc{{ID: "1", Title: "삼성전자 2분기 영업이익 급증(종합)", Source: "naver", PublishedAt: day}}); err != nil {
+		t.Fatal(err)
+	}
+	got, err := s.GetArticle("1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Title != "삼성전자 2분기 영업이익 급증(종합)" || !reflect.DeepEqual(got.Tags, []string{"005930"}) || got.Sentiment != "positive" {
+		t.Errorf("after upsert: title %q, tags %v, sentiment %q", got.Title, got.Tags, got.Sentiment)
+	}
+
+	// New tags replace the stored ones
+	if err := s.SaveArticles([]store.ArticleDoc{{ID: "1", Title: got.Title, PublishedAt: day, Tags: []string{"005930", "semiconductor"}}}); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := s.GetArticle("1"); !reflect.DeepEqual(got.Tags, []string{"005930", "semiconductor"}) {
+		t.Errorf("tags = %v", got.Tags)
+	}
+
+	if _, err := s.GetArticle("missing"); err != store.ErrNotFound {
+		t.Errorf("missing article: %v", err)
+	}
+}
+
+func TestSearchArticles(t *testing.T) {
+	s := openTestStore(t)
+	if err := s.SaveArticles(testArticles()); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		q    store.SearchQuery
+		want []string
+	}{
+		{"all, newest first", store.SearchQuery{}, []string{"4", "3", "2", "1"}},
+		{"trigram", store.SearchQuery{Query: "영업이익"}, []string{"2", "1"}},
+		{"trigram in summary", store.SearchQuery{Query: "반도체"}, []string{"1"}},
+		{"short term", store.SearchQuery{Query: "금리"}, []string{"3"}},
+		{"all terms", store.SearchQuery{Query: "삼성전자 2분기"}, []string{"1"}},
+		{"synonym", store.SearchQuery{Query: "삼전"}, []string{"2", "1"}},
+		{"source", store.SearchQuery{Filter: store.Filter{Sources: []string{"rss"}}}, []string{"4", "2"}},
+		{"tag", store.SearchQuery{Filter: store.Filter{Tags: []string{"000660"}}}, []string{"4"}},
+		{"dup state", store.SearchQuery{Filter: store.Filter{DupState: "unique"}}, []string{"3", "2", "1"}},
+		{"story", store.SearchQuery{Filter: store.Filter{StoryID: "1"}}, []string{"2", "1"}},
+		{"sentiment", store.SearchQuery{Filter: store.Filter{Sentiments: []string{"negative", "neutral"}}}, []string{"4", "3"}},
+		{"published range", store.SearchQuery{Filter: store.Filter{PublishedFrom: day.Add(time.Hour), PublishedTo: day.Add(2 * time.Hour)}}, []string{"3", "2"}},
+		{"oldest first", store.SearchQuery{Sort: []string{"published_at:asc"}}, []string{"1", "2", "3", "4"}},
+		{"page", store.SearchQuery{Limit: 2, Offset: 1}, []string{"3", "2"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res, err := s.SearchArticles(&tt.q)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := ids(res.Hits); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("hits = %v, want %v", got, tt.want)
+			}
+			if tt.q.Limit == 0 && res.Total != int64(len(tt.want)) {
+				t.Errorf("total = %d, want %d", res.Total, len(tt.want))
+			}
+		})
+	}
+}
+
+func TestSearchFacets(t *testing.T) {
+	s := openTestStore(t)
+	if err := s.SaveArticles(testArticles()); err != nil {
+		t.Fatal(err)
+	}
+
+	res, err := s.SearchArticles(&store.SearchQuery{Limit: 1, Facets: []string{"source", "tags", "sentiment"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]map[string]int64{
+		"source":    {"naver": 2, "rss": 2},
+		"tags":      {"005930": 2, "000660": 1},
+		"sentiment": {"positive": 2, "neutral": 1, "negative": 1},
+	}
+	if res.Total != 4 || !reflect.DeepEqual(res.Facets, want) {
+		t.Errorf("total %d, facets %v", res.Total, res.Facets)
+	}
+}
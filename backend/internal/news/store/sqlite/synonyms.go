@@ -0,0 +1,87 @@
+package sqlite
+
+import (
+	"encoding/json"
+	"strings"
+
+	"dx-unified/internal/news/store"
+)
+
+// GetSynonyms returns the stored synonyms
+func (s *Store) GetSynonyms() (map[string][]string, error) {
+	rows, err := s.DB.Query(`SELECT term, synonyms_json FROM synonyms`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	synonyms := make(map[string][]string)
+	for rows.Next() {
+		var term, synonymsJSON string
+		if err := rows.Scan(&term, &synonymsJSON); err != nil {
+			return nil, err
+		}
+		var alts []string
+		_ = json.Unmarshal([]byte(synonymsJSON), &alts)
+		synonyms[term] = alts
+	}
+	return synonyms, rows.Err()
+}
+
+// UpdateSynonyms replaces the stored synonyms
+func (s *Store) UpdateSynonyms(synonyms map[string][]string) error {
+	tx, err := s.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`DELETE FROM synonyms`); err != nil {
+		return err
+	}
+	for term, alts := range synonyms {
+		altsJSON, _ := json.Marshal(alts)
+		if _, err := tx.Exec(`INSERT INTO synonyms (term, synonyms_json) VALUES (?, ?)`, term, string(altsJSON)); err != nil {
+			return err
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	s.setSynonyms(synonyms)
+	return nil
+}
+
+// loadSynonyms caches the stored synonyms, applying the defaults to an
+// empty table
+func (s *Store) loadSynonyms() error {
+	synonyms, err := s.GetSynonyms()
+	if err != nil {
+		return err
+	}
+	if len(synonyms) == 0 {
+		return s.UpdateSynonyms(store.DefaultSynonyms)
+	}
+	s.setSynonyms(synonyms)
+	return nil
+}
+
+func (s *Store) setSynonyms(synonyms map[string][]string) {
+	lookup := make(map[string][]string, len(synonyms))
+	for term, alts := range synonyms {
+		key := strings.ToLower(term)
+		lookup[key] = append(lookup[key], alts...)
+	}
+
+	s.mu.Lock()
+	s.synonyms = lookup
+	s.mu.Unlock()
+}
+
+// alternatives returns the term followed by its synonyms
+func (s *Store) alternatives(term string) []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return append([]string{term}, s.synonyms[strings.ToLower(term)]...)
+}
@@ -0,0 +1,114 @@
+package store
+
+import (
+	"errors"
+	"time"
+)
+
+// Backends selectable via config (NEWS_STORE)
+const (
+	BackendMeili  = "meili"
+	BackendSQLite = "sqlite"
+)
+
+// ErrNotFound is returned when a document does not exist
+var ErrNotFound = errors.New("not found")
+
+// NewsStore is the persistence layer for articles and run logs
+type NewsStore interface {
+	// SaveArticles upserts articles; empty body fields keep the stored body
+	SaveArticles(articles []ArticleDoc) error
+	SaveRun(run *RunLog) error
+	GetArticle(id string) (*ArticleDoc, error)
+	SearchArticles(q *SearchQuery) (*SearchResult, error)
+	ListRuns(limit int) ([]RunLog, error)
+}
+
+// SynonymStore is implemented by backends that expand queries with
+// synonyms. Keys map to the terms they also match, e.g. "삼전" -> ["삼성전자"].
+type SynonymStore interface {
+	GetSynonyms() (map[string][]string, error)
+	// UpdateSynonyms replaces the whole synonym set
+	UpdateSynonyms(synonyms map[string][]string) error
+}
+
+// FacetFields are the fields facet counts can be requested for
+var FacetFields = []string{"source", "publisher", "tags", "dup_state", "sentiment", "story_id"}
+
+// DefaultSynonyms are market nicknames applied to a store without synonyms
+var DefaultSynonyms = MutualSynonyms(
+	[]string{"삼전", "삼성전자"},
+	[]string{"하닉", "SK하이닉스", "하이닉스"},
+	[]string{"현차", "현대차", "현대자동차"},
+	[]string{"엘지엔솔", "LG에너지솔루션", "LG엔솔"},
+	[]string{"셀트", "셀트리온"},
+	[]string{"삼바", "삼성바이오로직스"},
+	[]string{"연준", "Fed", "FOMC"},
+	[]string{"한은", "한국은행"},
+)
+
+// MutualSynonyms expands groups of equivalent terms into the one-directional
+// form stored by the backends
+func MutualSynonyms(groups ...[]string) map[string][]string {
+	out := make(map[string][]string)
+	for _, group := range groups {
+		for _, term := range group {
+			for _, other := range group {
+				if other != term {
+					out[term] = append(out[term], other)
+				}
+			}
+		}
+	}
+	return out
+}
+
+// SearchQuery describes a full-text search with filters, sort and paging.
+// An empty Query lists articles matching the filter.
+type SearchQuery struct {
+	Query  string
+	Filter Filter
+	Sort   []string // "field:asc" or "field:desc", e.g. "published_at:desc"
+	Limit  int
+	Offset int
+
+	Facets    []string // fields from FacetFields to count values for
+	Highlight bool     // return highlighted title/summary and a body snippet
+}
+
+// Filter restricts search results; zero values are ignored. Values within a
+// list are OR-ed, fields are AND-ed.
+type Filter struct {
+	Sources       []string
+	Publishers    []string
+	Tags          []string
+	DupState      string
+	StoryID       string
+	Sentiments    []string
+	PublishedFrom time.Time // inclusive
+	PublishedTo   time.Time // inclusive
+}
+
+// SearchResult holds one page of matching articles
+type SearchResult struct {
+	Total int64        `json:"total"`
+	Hits  []ArticleDoc `json:"hits"`
+
+	// Facets maps field -> value -> number of matching articles
+	Facets map[string]map[string]int64 `json:"facets,omitempty"`
+	// Highlights are keyed by article ID; matches are wrapped in <em></em>
+	Highlights map[string]Highlight `json:"highlights,omitempty"`
+}
+
+// Highlight holds the formatted fields of one hit
+type Highlight struct {
+	Title   string `json:"title"`
+	Summary string `json:"summary"`
+	Body    string `json:"body,omitempty"` // cropped around the matches
+}
+
+// Highlight tags
+const (
+	HighlightPreTag  = "<em>"
+	HighlightPostTag = "</em>"
+)
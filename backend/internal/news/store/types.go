@@ -0,0 +1,48 @@
+package store
+
+import (
+	"time"
+)
+
+type ArticleDoc struct {
+	ID           string    `json:"id"`
+	Title        string    `json:"title"`
+	Summary      string    `json:"summary"`
+	Body         string    `json:"body,omitempty"`
+	URL          string    `json:"url"`
+	CanonicalURL string    `json:"canonical_url"`
+	Source       string    `json:"source"`
+	Publisher    string    `json:"publisher"`
+	PublishedAt  time.Time `json:"published_at"`
+	FetchedAt    time.Time `json:"fetched_at"`
+
+	// Unix seconds, used for range filters and sorting
+	PublishedAtTS int64 `json:"published_at_ts"`
+	FetchedAtTS   int64 `json:"fetched_at_ts"`
+
+	DupState string  `json:"dup_state"` // unique, duplicate
+	DupOf    string  `json:"dup_of,omitempty"`
+	DupScore float64 `json:"dup_score,omitempty"`
+	StoryID  string  `json:"story_id,omitempty"`
+
+	Tags      []string `json:"tags,omitempty"`
+	Sentiment string   `json:"sentiment,omitempty"` // positive, negative, neutral
+
+	BodyStatus string `json:"body_status,omitempty"` // ok, failed (empty when extraction is disabled)
+	BodyError  string `json:"body_error,omitempty"`
+}
+
+// SetTimestamps derives the numeric timestamp fields from the time fields
+func (a *ArticleDoc) SetTimestamps() {
+	a.PublishedAtTS = a.PublishedAt.Unix()
+	a.FetchedAtTS = a.FetchedAt.Unix()
+}
+
+type RunLog struct {
+	RunID     string                 `json:"run_id"`
+	StartedAt time.Time              `json:"started_at"`
+	EndedAt   time.Time              `json:"ended_at"`
+	Status    string                 `json:"status"` // success, failed
+	Stats     map[string]interface{} `json:"stats"`
+	Errors    []string               `json:"errors,omitempty"`
+}
@@ -0,0 +1,212 @@
+package trending
+
+import (
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// Korean noun extraction without a morphological analyser: a token that is
+// a dictionary word followed by a particle, ending or noun suffix is cut to
+// that word, otherwise trailing particles (josa) and common predicate
+// endings are stripped. Other tokens merely starting with a dictionary word
+// ("개인정보", "skt") are left whole.
+
+// Particles and endings, longest first within each length class
+var koreanSuffixes = []string{
+	"으로부터", "에서부터", "이라는", "이라고", "에서는", "에게서", "으로는", "으로도", "까지는", "부터는",
+	"했다고", "한다고", "된다고", "됐다고", "하겠다", "했으며", "하면서", "되면서", "이었다", "였다",
+	"에서", "에게", "한테", "께서", "으로", "부터", "까지", "마저", "조차", "처럼", "보다", "이나", "이며", "이다",
+	"라는", "라고", "에는", "에도", "와의", "과의", "과는", "와는", "들이", "들은", "들을", "들의",
+	"했다", "한다", "하는", "하고", "하며", "해서", "하자", "하기", "했고", "된다", "됐다", "되는", "되며", "되고",
+	"은", "는", "이", "가", "을", "를", "에", "의", "로", "와", "과", "도", "만", "들", "서",
+}
+
+// Suffixes that keep the preceding dictionary word the subject, as in
+// "반도체주" or "배터리업계"
+var nounSuffixes = []string{"주", "株", "업종", "업계", "시장", "주가"}
+
+// dictionarySuffixes may follow a dictionary word
+var dictionarySuffixes = toSet(append(append([]string{}, koreanSuffixes...), nounSuffixes...)...)
+
+var koreanStopwords = toSet(
+	"기자", "뉴스", "오늘", "어제", "내일", "지난", "이번", "올해", "지난해", "내년", "관련", "위해", "대한",
+	"통해", "따라", "또한", "그리고", "하지만", "이날", "최근", "현재", "경우", "가운데", "이후", "이상", "이하",
+	"전년", "대비", "기준", "수준", "예정", "계획", "발표", "밝혔다", "전했다", "말했다", "있다", "없다", "있는",
+	"것으로", "것이", "것은", "것을", "등의", "했다", "한다", "이라며", "무단", "전재", "배포", "금지",
+	"사진", "제공", "연합뉴스", "뉴시스", "뉴스1", "종합", "속보", "단독", "오전", "오후", "가량", "정도", "우리",
+)
+
+var englishStopwords = toSet(
+	"the", "and", "for", "are", "but", "not", "you", "all", "any", "can", "had", "her", "was", "one",
+	"our", "out", "has", "have", "his", "how", "its", "may", "new", "now", "say", "says", "said", "she",
+	"that", "this", "with", "from", "they", "will", "would", "there", "their", "what", "when", "which",
+	"who", "why", "been", "were", "than", "then", "them", "into", "over", "after", "before", "about",
+	"more", "most", "also", "just", "some", "such", "only", "other", "could", "should", "while", "year",
+	"amid", "news", "report", "reports", "inc", "corp", "ltd", "co", "per", "via", "top",
+)
+
+// Economic terms recognised even when glued to particles or other nouns
+var defaultTerms = []string{
+	"기준금리", "금리", "환율", "물가", "인플레이션", "경기침체", "반도체", "이차전지", "배터리", "전기차",
+	"코스피", "코스닥", "나스닥", "다우", "연준", "한국은행", "기재부", "금융위", "금감원", "공매도",
+	"유상증자", "무상증자", "자사주", "인수합병", "상장폐지", "신규상장", "배당", "실적", "영업이익",
+	"순이익", "매출", "수출", "수입", "무역수지", "국채", "부동산", "가상자산", "비트코인", "관세",
+	"외국인", "기관", "개인", "순매수", "순매도", "목표주가", "밸류업", "인공지능", "로봇", "바이오",
+}
+
+// Dictionary holds known nouns and company names mapped to tickers
+type Dictionary struct {
+	mu      sync.RWMutex
+	words   map[string]bool
+	tickers map[string]string // name -> stock code
+	maxLen  int               // longest word in runes
+}
+
+// NewDictionary creates a dictionary with the default economic terms
+// plus extra
+func NewDictionary(extra []string) *Dictionary {
+	d := &Dictionary{words: make(map[string]bool), tickers: make(map[string]string)}
+	for _, w := range append(append([]string{}, defaultTerms...), extra...) {
+		d.addWord(w)
+	}
+	return d
+}
+
+// AddTickers registers company names (e.g. from DART corp codes) so they
+// are extracted as nouns and resolved to stock codes
+func (d *Dictionary) AddTickers(names map[string]string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	for name, code := range names {
+		name = strings.TrimSpace(name)
+		if len([]rune(name)) < 2 || code == "" {
+			continue
+		}
+		d.addWordLocked(name)
+		d.tickers[strings.ToLower(name)] = code
+	}
+}
+
+// Ticker returns the stock code for a company name term
+func (d *Dictionary) Ticker(term string) (string, bool) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	code, ok := d.tickers[term]
+	return code, ok
+}
+
+func (d *Dictionary) addWord(w string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.addWordLocked(w)
+}
+
+func (d *Dictionary) addWordLocked(w string) {
+	w = strings.ToLower(strings.TrimSpace(w))
+	if w == "" {
+		return
+	}
+	d.words[w] = true
+	if n := len([]rune(w)); n > d.maxLen {
+		d.maxLen = n
+	}
+}
+
+// longestPrefix returns the longest dictionary word token starts with whose
+// remainder is empty or a particle, ending or noun suffix
+func (d *Dictionary) longestPrefix(token []rune) string {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	n := len(token)
+	if n > d.maxLen {
+		n = d.maxLen
+	}
+	for i := n; i >= 2; i-- {
+		if w := string(token[:i]); d.words[w] && (i == len(token) || dictionarySuffixes[string(token[i:])]) {
+			return w
+		}
+	}
+	return ""
+}
+
+// Tokenize extracts distinct candidate terms from text, in order of first
+// appearance
+func (d *Dictionary) Tokenize(text string) []string {
+	fields := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	seen := make(map[string]bool)
+	var terms []string
+	for _, f := range fields {
+		term := d.term(f)
+		if term == "" || seen[term] {
+			continue
+		}
+		seen[term] = true
+		terms = append(terms, term)
+	}
+	return terms
+}
+
+func (d *Dictionary) term(field string) string {
+	runes := []rune(field)
+
+	if w := d.longestPrefix(runes); w != "" {
+		return w
+	}
+
+	if !hasHangul(runes) {
+		if len(runes) < 3 || englishStopwords[field] || isNumeric(runes) {
+			return ""
+		}
+		return field
+	}
+
+	for _, suffix := range koreanSuffixes {
+		if stem, ok := strings.CutSuffix(field, suffix); ok && len([]rune(stem)) >= 2 {
+			field = stem
+			break
+		}
+	}
+
+	runes = []rune(field)
+	if len(runes) < 2 || koreanStopwords[field] {
+		return ""
+	}
+	for _, r := range runes {
+		// Mixed tokens like "3분기" or "10조원" are mostly numbers and units
+		if unicode.IsDigit(r) {
+			return ""
+		}
+	}
+	return field
+}
+
+func hasHangul(runes []rune) bool {
+	for _, r := range runes {
+		if unicode.Is(unicode.Hangul, r) {
+			return true
+		}
+	}
+	return false
+}
+
+func isNumeric(runes []rune) bool {
+	for _, r := range runes {
+		if !unicode.IsDigit(r) {
+			return false
+		}
+	}
+	return true
+}
+
+func toSet(words ...string) map[string]bool {
+	set := make(map[string]bool, len(words))
+	for _, w := range words {
+		set[w] = true
+	}
+	return set
+}
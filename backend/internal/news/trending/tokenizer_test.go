@@ -0,0 +1,48 @@
+package trending
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestTokenize(t *testing.T) {
+	d := NewDictionary([]string{"개인"})
+	d.AddTickers(map[string]string{"SK": "034730", "SK하이닉스": "000660", "삼성전자": "005930"})
+
+	tests := []struct {
+		text string
+		want []string
+	}{
+		{"삼성전자가 반도체를 증산", []string{"삼성전자", "반도체", "증산"}},
+		{"SK하이닉스의 실적은", []string{"sk하이닉스", "실적"}},
+		{"반도체주 강세", []string{"반도체", "강세"}},
+		{"기준금리를 동결했다", []string{"기준금리", "동결"}},
+		// Dictionary words that only start a longer word are not cut
+		{"개인정보 유출", []string{"개인정보", "유출"}},
+		{"개인이 순매수", []string{"개인", "순매수"}},
+		{"SKT skip SK", []string{"skt", "skip", "sk"}},
+		// Stopwords, numbers and short English words are dropped
+		{"기자 2024 3분기 the Fed", []string{"fed"}},
+		{"금리 금리에 금리가", []string{"금리"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			if got := d.Tokenize(tt.text); !slices.Equal(got, tt.want) {
+				t.Errorf("Tokenize(%q) = %q, want %q", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTicker(t *testing.T) {
+	d := NewDictionary(nil)
+	d.AddTickers(map[string]string{"삼성전자": "005930", "X": "000001"})
+
+	if code, ok := d.Ticker("삼성전자"); !ok || code != "005930" {
+		t.Errorf("Ticker(삼성전자) = %q, %v", code, ok)
+	}
+	// Names shorter than two characters are not registered
+	if _, ok := d.Ticker("x"); ok {
+		t.Errorf("one-character name registered")
+	}
+}
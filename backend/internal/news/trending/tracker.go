@@ -0,0 +1,425 @@
+package trending
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"dx-unified/internal/news/store"
+)
+
+const (
+	// Hourly counts are kept for a week so a window can be compared with
+	// the same window over previous days
+	retentionHours = 7 * 24
+	// Article references (for samples and tickers) are only kept for the
+	// longest allowed window
+	MaxWindowHours = 48
+
+	samplesPerTermHour = 3
+)
+
+// ArticleRef is a stored article shown as a sample for a term
+type ArticleRef struct {
+	ID          string    `json:"id"`
+	Title       string    `json:"title"`
+	URL         string    `json:"url"`
+	Source      string    `json:"source"`
+	PublishedAt time.Time `json:"published_at"`
+	Tickers     []string  `json:"tickers,omitempty"`
+}
+
+type hourBucket struct {
+	Docs     int              `json:"docs"`
+	Terms    map[string]int   `json:"terms"`
+	Articles []ArticleRef     `json:"articles,omitempty"`
+	Samples  map[string][]int `json:"samples,omitempty"` // term -> indexes into Articles
+}
+
+type snapshot struct {
+	Since int64                 `json:"since"` // first hour observed
+	Hours map[int64]*hourBucket `json:"hours"`
+	Seen  map[string]int64      `json:"seen"` // article id -> hour
+}
+
+// Tracker maintains hourly term counts over the news stream
+type Tracker struct {
+	path string
+	dict *Dictionary
+
+	mu    sync.RWMutex
+	state snapshot
+}
+
+// NewTracker creates a tracker persisted at path, loading any saved state
+func NewTracker(path string, dict *Dictionary) *Tracker {
+	t := &Tracker{
+		path: path,
+		dict: dict,
+		state: snapshot{
+			Hours: make(map[int64]*hourBucket),
+			Seen:  make(map[string]int64),
+		},
+	}
+
+	if data, err := os.ReadFile(path); err == nil {
+		var s snapshot
+		if err := json.Unmarshal(data, &s); err == nil && s.Hours != nil {
+			if s.Seen == nil {
+				s.Seen = make(map[string]int64)
+			}
+			t.state = s
+		}
+	}
+	return t
+}
+
+// Dictionary returns the tokenizer dictionary
+func (t *Tracker) Dictionary() *Dictionary {
+	return t.dict
+}
+
+// Empty reports whether nothing has been counted yet
+func (t *Tracker) Empty() bool {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return len(t.state.Hours) == 0
+}
+
+func hourOf(ts time.Time) int64 {
+	return ts.Unix() / 3600 * 3600
+}
+
+// Add counts the terms of newly stored articles. Articles already counted
+// and duplicates are ignored. It returns the number of articles counted.
+func (t *Tracker) Add(docs []store.ArticleDoc) int {
+	now := time.Now()
+	current := hourOf(now)
+	oldest := current - retentionHours*3600
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	added := 0
+	for _, doc := range docs {
+		if doc.DupState == "duplicate" || doc.ID == "" {
+			continue
+		}
+		if _, ok := t.state.Seen[doc.ID]; ok {
+			continue
+		}
+
+		published := doc.PublishedAt
+		if published.IsZero() || published.After(now) {
+			published = now
+		}
+		hour := hourOf(published)
+		if hour < oldest {
+			continue
+		}
+
+		b := t.state.Hours[hour]
+		if b == nil {
+			b = &hourBucket{Terms: make(map[string]int), Samples: make(map[string][]int)}
+			t.state.Hours[hour] = b
+		}
+		if b.Samples == nil {
+			b.Samples = make(map[string][]int)
+		}
+
+		terms := t.dict.Tokenize(doc.Title + " " + doc.Summary)
+
+		var tickers []string
+		for _, term := range terms {
+			if code, ok := t.dict.Ticker(term); ok {
+				tickers = append(tickers, code)
+			}
+		}
+
+		idx := len(b.Articles)
+		b.Articles = append(b.Articles, ArticleRef{
+			ID:          doc.ID,
+			Title:       doc.Title,
+			URL:         doc.URL,
+			Source:      doc.Source,
+			PublishedAt: doc.PublishedAt,
+			Tickers:     tickers,
+		})
+		b.Docs++
+		for _, term := range terms {
+			b.Terms[term]++
+			if len(b.Samples[term]) < samplesPerTermHour {
+				b.Samples[term] = append(b.Samples[term], idx)
+			}
+		}
+
+		t.state.Seen[doc.ID] = hour
+		if t.state.Since == 0 || hour < t.state.Since {
+			t.state.Since = hour
+		}
+		added++
+	}
+
+	t.pruneLocked(current)
+	return added
+}
+
+func (t *Tracker) pruneLocked(current int64) {
+	oldest := current - retentionHours*3600
+	sampleCutoff := current - MaxWindowHours*3600
+
+	for hour, b := range t.state.Hours {
+		switch {
+		case hour < oldest:
+			delete(t.state.Hours, hour)
+		case hour < sampleCutoff:
+			b.Articles = nil
+			b.Samples = nil
+		}
+	}
+	for id, hour := range t.state.Seen {
+		if hour < oldest {
+			delete(t.state.Seen, id)
+		}
+	}
+	if t.state.Since < oldest {
+		t.state.Since = oldest
+	}
+}
+
+// Save writes the counts to disk atomically
+func (t *Tracker) Save() error {
+	t.mu.RLock()
+	data, err := json.Marshal(t.state)
+	t.mu.RUnlock()
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(t.path), 0755); err != nil {
+		return err
+	}
+	tmp := t.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, t.path)
+}
+
+// Options controls burst detection
+type Options struct {
+	WindowHours int
+	MinCount    int // minimum occurrences within the window
+	Limit       int
+}
+
+// Trend is a term whose frequency in the window is unusually high
+type Trend struct {
+	Term           string       `json:"term"`
+	Count          int          `json:"count"`
+	BaselineMean   float64      `json:"baseline_mean"`
+	BaselineStd    float64      `json:"baseline_std"`
+	ZScore         float64      `json:"z_score"`
+	Ticker         string       `json:"ticker,omitempty"` // when the term is a company name
+	RelatedTickers []string     `json:"related_tickers,omitempty"`
+	SampleArticles []ArticleRef `json:"sample_articles"`
+}
+
+// Report is the result of a trending query
+type Report struct {
+	Window          string    `json:"window"`
+	From            time.Time `json:"from"`
+	To              time.Time `json:"to"`
+	Docs            int       `json:"docs"`             // articles in the window
+	BaselineWindows int       `json:"baseline_windows"` // earlier windows used for the baseline
+	Terms           []Trend   `json:"terms"`
+}
+
+// Trending compares each term's count in the latest window with its counts
+// in the preceding windows of the same length (a rolling baseline) and
+// returns the terms with the highest z-scores
+func (t *Tracker) Trending(opts Options) *Report {
+	if opts.WindowHours < 1 {
+		opts.WindowHours = 6
+	}
+	if opts.WindowHours > MaxWindowHours {
+		opts.WindowHours = MaxWindowHours
+	}
+	if opts.MinCount < 1 {
+		opts.MinCount = 3
+	}
+	if opts.Limit < 1 {
+		opts.Limit = 20
+	}
+
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	w := int64(opts.WindowHours) * 3600
+	current := hourOf(time.Now())
+	windowStart := current - w + 3600
+
+	report := &Report{
+		Window: fmt.Sprintf("%dh", opts.WindowHours),
+		From:   time.Unix(windowStart, 0),
+		To:     time.Now(),
+	}
+
+	// Count terms in the current window
+	counts := make(map[string]int)
+	for hour := windowStart; hour <= current; hour += 3600 {
+		if b := t.state.Hours[hour]; b != nil {
+			report.Docs += b.Docs
+			for term, n := range b.Terms {
+				counts[term] += n
+			}
+		}
+	}
+
+	// Baseline windows must lie entirely within observed history, otherwise
+	// missing data would look like zero counts
+	var baselines []map[string]int
+	for k := int64(1); ; k++ {
+		start := windowStart - k*w
+		if start < t.state.Since || start < current-retentionHours*3600 {
+			break
+		}
+		window := make(map[string]int)
+		for hour := start; hour < start+w; hour += 3600 {
+			if b := t.state.Hours[hour]; b != nil {
+				for term, n := range b.Terms {
+					window[term] += n
+				}
+			}
+		}
+		baselines = append(baselines, window)
+	}
+	report.BaselineWindows = len(baselines)
+
+	var trends []Trend
+	for term, count := range counts {
+		if count < opts.MinCount {
+			continue
+		}
+
+		mean, std := 0.0, 0.0
+		if len(baselines) > 0 {
+			for _, b := range baselines {
+				mean += float64(b[term])
+			}
+			mean /= float64(len(baselines))
+			for _, b := range baselines {
+				d := float64(b[term]) - mean
+				std += d * d
+			}
+			std = math.Sqrt(std / float64(len(baselines)))
+		}
+
+		// Floor the deviation so rare terms do not explode: a term seen once
+		// in every baseline window is not news when it shows up twice
+		scale := math.Max(std, math.Max(math.Sqrt(mean), 1))
+		z := (float64(count) - mean) / scale
+		if z <= 0 {
+			continue
+		}
+
+		trends = append(trends, Trend{
+			Term:         term,
+			Count:        count,
+			BaselineMean: round(mean),
+			BaselineStd:  round(std),
+			ZScore:       round(z),
+		})
+	}
+
+	sort.Slice(trends, func(i, j int) bool {
+		if trends[i].ZScore != trends[j].ZScore {
+			return trends[i].ZScore > trends[j].ZScore
+		}
+		return trends[i].Count > trends[j].Count
+	})
+	if len(trends) > opts.Limit {
+		trends = trends[:opts.Limit]
+	}
+
+	for i := range trends {
+		t.fillSamples(&trends[i], windowStart, current)
+	}
+	report.Terms = trends
+	return report
+}
+
+// fillSamples attaches the newest sample articles and the tickers they mention
+func (t *Tracker) fillSamples(tr *Trend, from, to int64) {
+	tickerCount := make(map[string]int)
+	tr.SampleArticles = []ArticleRef{}
+
+	for hour := to; hour >= from; hour -= 3600 {
+		b := t.state.Hours[hour]
+		if b == nil {
+			continue
+		}
+		for _, idx := range b.Samples[tr.Term] {
+			if idx >= len(b.Articles) {
+				continue
+			}
+			a := b.Articles[idx]
+			for _, code := range a.Tickers {
+				tickerCount[code]++
+			}
+			if len(tr.SampleArticles) < 5 {
+				tr.SampleArticles = append(tr.SampleArticles, a)
+			}
+		}
+	}
+
+	if code, ok := t.dict.Ticker(tr.Term); ok {
+		tr.Ticker = code
+	}
+	for code := range tickerCount {
+		if code != tr.Ticker {
+			tr.RelatedTickers = append(tr.RelatedTickers, code)
+		}
+	}
+	sort.Slice(tr.RelatedTickers, func(i, j int) bool {
+		a, b := tr.RelatedTickers[i], tr.RelatedTickers[j]
+		if tickerCount[a] != tickerCount[b] {
+			return tickerCount[a] > tickerCount[b]
+		}
+		return a < b
+	})
+	if len(tr.RelatedTickers) > 5 {
+		tr.RelatedTickers = tr.RelatedTickers[:5]
+	}
+}
+
+func round(v float64) float64 {
+	return math.Round(v*100) / 100
+}
+
+// Backfill counts the articles stored over the retention period, used when
+// the tracker starts without saved state. Each slice is one query, so very
+// busy slices are truncated at the store's page limit.
+func (t *Tracker) Backfill(st store.NewsStore) (int, error) {
+	const slice = 6 * time.Hour
+
+	end := time.Now()
+	total := 0
+	for from := end.Add(-retentionHours * time.Hour); from.Before(end); from = from.Add(slice) {
+		result, err := st.SearchArticles(&store.SearchQuery{
+			Filter: store.Filter{PublishedFrom: from, PublishedTo: from.Add(slice - time.Second)},
+			Sort:   []string{"published_at:asc"},
+			Limit:  1000,
+		})
+		if err != nil {
+			return total, err
+		}
+		total += t.Add(result.Hits)
+	}
+	return total, t.Save()
+}
@@ -0,0 +1,106 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"dx-unified/internal/report"
+
+	"github.com/gin-gonic/gin"
+)
+
+var contentTypes = map[string]string{
+	"html": "text/html; charset=utf-8",
+	"md":   "text/markdown; charset=utf-8",
+	"json": "application/json; charset=utf-8",
+}
+
+// Handler serves the generated market reports
+type Handler struct {
+	builder *report.Builder
+}
+
+// NewHandler creates a new report handler
+func NewHandler(builder *report.Builder) *Handler {
+	return &Handler{builder: builder}
+}
+
+// RegisterRoutes registers all report routes
+func (h *Handler) RegisterRoutes(rg *gin.RouterGroup) {
+	reports := rg.Group("/reports")
+	{
+		reports.GET("/daily", h.ListDaily)
+		reports.GET("/daily/:date", h.GetDaily)
+		reports.POST("/daily/:date", h.GenerateDaily)
+	}
+}
+
+// ListDaily returns the dates with a generated digest, newest first
+func (h *Handler) ListDaily(c *gin.Context) {
+	dates, err := h.builder.Dates()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"count": len(dates), "dates": dates})
+}
+
+// GetDaily serves the generated digest of a date (YYYY-MM-DD or "today")
+// as format=html (default), md or json. Digests are generated by the
+// scheduled job or POST.
+func (h *Handler) GetDaily(c *gin.Context) {
+	day, format, ok := h.parse(c)
+	if !ok {
+		return
+	}
+
+	date := day.Format("2006-01-02")
+	path, err := h.builder.Path(date, format)
+	if errors.Is(err, report.ErrNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "no report for " + date + ", POST /reports/daily/" + date + " to generate it"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Header("Content-Type", contentTypes[format])
+	c.File(path)
+}
+
+// GenerateDaily generates or regenerates the digest of a date, e.g. after
+// late data arrived, and returns it as JSON
+func (h *Handler) GenerateDaily(c *gin.Context) {
+	day, _, ok := h.parse(c)
+	if !ok {
+		return
+	}
+
+	r, err := h.builder.Generate(day)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, r)
+}
+
+func (h *Handler) parse(c *gin.Context) (time.Time, string, bool) {
+	day, err := report.ParseDate(c.Param("date"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return time.Time{}, "", false
+	}
+	if day.After(time.Now()) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "date is in the future"})
+		return time.Time{}, "", false
+	}
+
+	format := c.DefaultQuery("format", "html")
+	if _, ok := contentTypes[format]; !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "format must be html, md or json"})
+		return time.Time{}, "", false
+	}
+	return day, format, true
+}
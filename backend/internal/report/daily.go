@@ -0,0 +1,543 @@
+package report
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	candleDB "dx-unified/internal/candle/database"
+	"dx-unified/internal/candle/model"
+	judalDB "dx-unified/internal/judal/database"
+	"dx-unified/internal/news/quota"
+	newsStore "dx-unified/internal/news/store"
+	"dx-unified/internal/shared/timeutil"
+
+	"gorm.io/gorm"
+)
+
+const (
+	topThemes       = 10
+	themeMinStocks  = 3
+	topMovers       = 15
+	maxFilings      = 50
+	newsThemes      = 5 // rising and falling themes each that get news
+	storiesPerTheme = 3
+	newsStaleAfter  = 2 * time.Hour
+)
+
+// ErrNotFound is returned when no report was generated for a date
+var ErrNotFound = errors.New("report not found")
+
+// Report names of filings that are notable even without extracted events
+var notableFiling = regexp.MustCompile(`주요사항보고서|유상증자|무상증자|감자|자기주식|단일판매|공급계약|합병|분할|최대주주|잠정|실적|전환사채|신주인수권|교환사채|소송|상장폐지|거래정지|불성실공시|조회공시`)
+
+// Daily is the market digest of one KST trading date
+type Daily struct {
+	Date        string       `json:"date"` // YYYY-MM-DD
+	GeneratedAt time.Time    `json:"generated_at"`
+	Themes      ThemeSection `json:"themes"`
+	Movers      MoverSection `json:"movers"`
+	Filings     []Filing     `json:"filings"`
+	News        []ThemeNews  `json:"news"`
+	Warnings    []string     `json:"warnings"`
+}
+
+// ThemeSection holds the Judal themes with the largest average moves
+type ThemeSection struct {
+	SnapshotDate string                     `json:"snapshot_date,omitempty"` // Judal crawl date used
+	Rising       []judalDB.ThemePerformance `json:"rising"`
+	Falling      []judalDB.ThemePerformance `json:"falling"`
+}
+
+// MoverSection holds the largest close-to-close moves from the candle data
+type MoverSection struct {
+	Market  string               `json:"market"`
+	Symbols int                  `json:"symbols"` // symbols with a close on the date
+	Gainers []candleDB.DailyMove `json:"gainers"`
+	Losers  []candleDB.DailyMove `json:"losers"`
+}
+
+// Filing is a notable DART filing of the date
+type Filing struct {
+	RceptNo   string  `json:"rcept_no"`
+	CorpName  string  `json:"corp_name"`
+	StockCode string  `json:"stock_code"`
+	ReportNm  string  `json:"report_nm"`
+	FlrNm     string  `json:"flr_nm"`
+	URL       string  `json:"url"`
+	Events    []Event `json:"events,omitempty"`
+}
+
+// Event is an event extracted from a filing
+type Event struct {
+	Type    string                 `json:"type"`
+	Payload map[string]interface{} `json:"payload,omitempty"`
+}
+
+// ThemeNews lists the top news stories about a theme
+type ThemeNews struct {
+	Theme         string  `json:"theme"`
+	AvgChangeRate float64 `json:"avg_change_rate"`
+	Stories       []Story `json:"stories"`
+}
+
+// Story is the newest article of a news story
+type Story struct {
+	Title       string    `json:"title"`
+	URL         string    `json:"url"`
+	Publisher   string    `json:"publisher"`
+	PublishedAt time.Time `json:"published_at"`
+	Articles    int       `json:"articles"` // articles of the story on the date
+}
+
+// Builder assembles daily digests from the Judal, candle, DART and news
+// data. Any source may be unavailable; its section is then empty and a
+// warning is added.
+type Builder struct {
+	dir       string
+	judal     *judalDB.Repository // nil when Judal is disabled
+	dart      *gorm.DB            // nil when DART is disabled
+	news      newsStore.NewsStore // nil when news is disabled
+	newsQuota *quota.Manager
+}
+
+// NewBuilder creates a builder storing reports under storageDir/reports/daily
+func NewBuilder(storageDir string, judal *judalDB.Repository, dart *gorm.DB, news newsStore.NewsStore, newsQuota *quota.Manager) *Builder {
+	return &Builder{
+		dir:       filepath.Join(storageDir, "reports", "daily"),
+		judal:     judal,
+		dart:      dart,
+		news:      news,
+		newsQuota: newsQuota,
+	}
+}
+
+// ParseDate parses a YYYY-MM-DD date (or "today") as a KST day
+func ParseDate(s string) (time.Time, error) {
+	if s == "today" {
+		return timeutil.Today(), nil
+	}
+	return timeutil.ParseDate(s)
+}
+
+// Build assembles the digest of a KST date without storing it
+func (b *Builder) Build(day time.Time) *Daily {
+	day = day.In(timeutil.KST)
+	r := &Daily{
+		Date:        day.Format("2006-01-02"),
+		GeneratedAt: time.Now(),
+		Warnings:    []string{},
+	}
+
+	b.buildThemes(r, day)
+	b.buildMovers(r, day)
+	b.buildFilings(r, day)
+	b.buildNews(r, day)
+	return r
+}
+
+func (r *Daily) warn(format string, args ...interface{}) {
+	r.Warnings = append(r.Warnings, fmt.Sprintf(format, args...))
+}
+
+// buildThemes uses the first Judal snapshot after the session. The daily
+// crawl runs at midnight, so the snapshot of day+1 holds the day's close.
+func (b *Builder) buildThemes(r *Daily, day time.Time) {
+	r.Themes = ThemeSection{Rising: []judalDB.ThemePerformance{}, Falling: []judalDB.ThemePerformance{}}
+	if b.judal == nil || judalDB.DB == nil {
+		r.warn("Judal database is not available")
+		return
+	}
+
+	want := day.AddDate(0, 0, 1).Format("2006-01-02")
+	snapshot, err := b.judal.GetLatestHistoryDate(want)
+	if err != nil {
+		r.warn("Judal snapshot lookup failed: %v", err)
+		return
+	}
+	if snapshot == "" {
+		r.warn("No Judal snapshot on or before %s", want)
+		return
+	}
+	if snapshot != want {
+		r.warn("Judal snapshot is from %s and does not include the %s close yet", snapshot, r.Date)
+	}
+	r.Themes.SnapshotDate = snapshot
+
+	themes, err := b.judal.GetThemePerformance(snapshot, themeMinStocks)
+	if err != nil {
+		r.warn("Judal theme query failed: %v", err)
+		return
+	}
+	for i := 0; i < len(themes) && i < topThemes && themes[i].AvgChangeRate > 0; i++ {
+		r.Themes.Rising = append(r.Themes.Rising, themes[i])
+	}
+	for i := len(themes) - 1; i >= 0 && len(themes)-1-i < topThemes && themes[i].AvgChangeRate < 0; i-- {
+		r.Themes.Falling = append(r.Themes.Falling, themes[i])
+	}
+}
+
+func (b *Builder) buildMovers(r *Daily, day time.Time) {
+	r.Movers = MoverSection{Market: model.MarketKR, Gainers: []candleDB.DailyMove{}, Losers: []candleDB.DailyMove{}}
+	if candleDB.DB == nil {
+		r.warn("Candle database is not available")
+		return
+	}
+
+	// Daily bars when they were collected, otherwise minute bars
+	var moves []candleDB.DailyMove
+	for _, timeframe := range []string{"1d", "1m"} {
+		var err error
+		moves, err = candleDB.QueryDailyMoves(model.MarketKR, timeframe, day.Unix(), day.AddDate(0, 0, 1).Unix())
+		if err != nil {
+			r.warn("Candle query failed: %v", err)
+			return
+		}
+		if len(moves) > 0 {
+			break
+		}
+	}
+	r.Movers.Symbols = len(moves)
+	if len(moves) == 0 {
+		r.warn("No %s candles for %s", model.MarketKR, r.Date)
+		return
+	}
+	for i := 0; i < len(moves) && i < topMovers && moves[i].ChangePct > 0; i++ {
+		r.Movers.Gainers = append(r.Movers.Gainers, moves[i])
+	}
+	for i := len(moves) - 1; i >= 0 && len(moves)-1-i < topMovers && moves[i].ChangePct < 0; i-- {
+		r.Movers.Losers = append(r.Movers.Losers, moves[i])
+	}
+}
+
+// buildFilings lists filings of listed companies that have extracted events
+// or a notable report name, those with events first
+func (b *Builder) buildFilings(r *Daily, day time.Time) {
+	r.Filings = []Filing{}
+	if b.dart == nil {
+		r.warn("DART database is not available")
+		return
+	}
+
+	var rows []struct {
+		RceptNo   string
+		CorpName  string
+		StockCode string
+		ReportNm  string
+		FlrNm     string
+	}
+	err := b.dart.Table("filings").
+		Select("filings.rcept_no, filings.corp_name, corps.stock_code, filings.report_nm, filings.flr_nm").
+		Joins("JOIN corps ON corps.corp_code = filings.corp_code").
+		Where("filings.rcept_dt = ? AND corps.stock_code <> ''", day.Format("20060102")).
+		Order("filings.rcept_no DESC").
+		Scan(&rows).Error
+	if err != nil {
+		r.warn("DART filing query failed: %v", err)
+		return
+	}
+	if len(rows) == 0 {
+		var latest sql.NullString
+		_ = b.dart.Table("filings").Select("MAX(rcept_dt)").Row().Scan(&latest)
+		if latest.String == "" {
+			latest.String = "none"
+		}
+		r.warn("No DART filings of listed companies for %s (latest filing date %s)", r.Date, latest.String)
+		return
+	}
+
+	rceptNos := make([]string, len(rows))
+	for i, row := range rows {
+		rceptNos[i] = row.RceptNo
+	}
+	var events []struct {
+		RceptNo     string
+		EventType   string
+		PayloadJSON string
+	}
+	if err := b.dart.Table("extracted_events").
+		Select("rcept_no, event_type, payload_json").
+		Where("rcept_no IN ?", rceptNos).
+		Order("id").
+		Scan(&events).Error; err != nil {
+		r.warn("DART event query failed: %v", err)
+	}
+	byFiling := make(map[string][]Event)
+	for _, e := range events {
+		ev := Event{Type: e.EventType}
+		_ = json.Unmarshal([]byte(e.PayloadJSON), &ev.Payload)
+		byFiling[e.RceptNo] = append(byFiling[e.RceptNo], ev)
+	}
+
+	var withEvents, notable []Filing
+	for _, row := range rows {
+		f := Filing{
+			RceptNo:   row.RceptNo,
+			CorpName:  row.CorpName,
+			StockCode: row.StockCode,
+			ReportNm:  strings.TrimSpace(row.ReportNm),
+			FlrNm:     row.FlrNm,
+			URL:       "https://dart.fss.or.kr/dsaf001/main.do?rcpNo=" + row.RceptNo,
+			Events:    byFiling[row.RceptNo],
+		}
+		switch {
+		case len(f.Events) > 0:
+			withEvents = append(withEvents, f)
+		case notableFiling.MatchString(f.ReportNm):
+			notable = append(notable, f)
+		}
+	}
+	r.Filings = append(withEvents, notable...)
+	if len(r.Filings) > maxFilings {
+		r.Filings = r.Filings[:maxFilings]
+	}
+}
+
+// buildNews finds the day's stories for the themes that moved most. Each
+// story is represented by its newest article.
+func (b *Builder) buildNews(r *Daily, day time.Time) {
+	r.News = []ThemeNews{}
+	if b.news == nil {
+		r.warn("News store is not available")
+		return
+	}
+
+	b.checkNewsFreshness(r, day)
+
+	var themes []judalDB.ThemePerformance
+	for i := 0; i < len(r.Themes.Rising) && i < newsThemes; i++ {
+		themes = append(themes, r.Themes.Rising[i])
+	}
+	for i := 0; i < len(r.Themes.Falling) && i < newsThemes; i++ {
+		themes = append(themes, r.Themes.Falling[i])
+	}
+
+	for _, theme := range themes {
+		result, err := b.news.SearchArticles(&newsStore.SearchQuery{
+			Query: theme.Name,
+			Filter: newsStore.Filter{
+				DupState:      "unique",
+				PublishedFrom: day,
+				PublishedTo:   day.AddDate(0, 0, 1).Add(-time.Second),
+			},
+			Sort:  []string{"published_at:desc"},
+			Limit: 50,
+		})
+		if err != nil {
+			r.warn("News search for %s failed: %v", theme.Name, err)
+			continue
+		}
+
+		tn := ThemeNews{Theme: theme.Name, AvgChangeRate: theme.AvgChangeRate, Stories: []Story{}}
+		index := make(map[string]int)
+		for _, a := range result.Hits {
+			key := storyKey(a)
+			if i, ok := index[key]; ok {
+				tn.Stories[i].Articles++
+				continue
+			}
+			index[key] = len(tn.Stories)
+			tn.Stories = append(tn.Stories, Story{
+				Title:       a.Title,
+				URL:         a.URL,
+				Publisher:   a.Publisher,
+				PublishedAt: a.PublishedAt,
+				Articles:    1,
+			})
+		}
+		// Bigger stories first, newest first among equals
+		sort.SliceStable(tn.Stories, func(i, j int) bool {
+			return tn.Stories[i].Articles > tn.Stories[j].Articles
+		})
+		if len(tn.Stories) > storiesPerTheme {
+			tn.Stories = tn.Stories[:storiesPerTheme]
+		}
+		if len(tn.Stories) > 0 {
+			r.News = append(r.News, tn)
+		}
+	}
+}
+
+// storyKey groups the articles of a story. Articles stored before stories
+// were assigned, and not reprocessed since, have no story ID and are grouped
+// on their title without bracketed labels, spaces and punctuation.
+func storyKey(a newsStore.ArticleDoc) string {
+	if a.StoryID != "" {
+		return a.StoryID
+	}
+	var key strings.Builder
+	depth := 0
+	for _, r := range strings.ToLower(a.Title) {
+		switch r {
+		case '[', '(', '【', '<':
+			depth++
+			continue
+		case ']', ')', '】', '>':
+			if depth > 0 {
+				depth--
+			}
+			continue
+		}
+		if depth == 0 && (unicode.IsLetter(r) || unicode.IsDigit(r)) {
+			key.WriteRune(r)
+		}
+	}
+	if key.Len() == 0 {
+		return a.ID
+	}
+	return "title:" + key.String()
+}
+
+func (b *Builder) checkNewsFreshness(r *Daily, day time.Time) {
+	runs, err := b.news.ListRuns(1)
+	switch {
+	case err != nil:
+		r.warn("News run lookup failed: %v", err)
+	case len(runs) == 0:
+		r.warn("No news fetch runs recorded")
+	case runs[0].EndedAt.Before(day.AddDate(0, 0, 1)) && time.Since(runs[0].EndedAt) > newsStaleAfter:
+		r.warn("Last news fetch ended %s ago (%s)", time.Since(runs[0].EndedAt).Round(time.Minute),
+			runs[0].EndedAt.In(timeutil.KST).Format("2006-01-02 15:04"))
+	}
+
+	if b.newsQuota == nil {
+		return
+	}
+	report := b.newsQuota.Report()
+	sources := make([]string, 0, len(report))
+	for source := range report {
+		sources = append(sources, source)
+	}
+	sort.Strings(sources)
+	for _, source := range sources {
+		if h := report[source]; h.Status != "ok" {
+			msg := fmt.Sprintf("News source %s is %s", source, h.Status)
+			if h.Reason != "" {
+				msg += ": " + h.Reason
+			}
+			r.Warnings = append(r.Warnings, msg)
+		}
+	}
+}
+
+// Generate builds the digest of a date and stores it as JSON, Markdown and HTML
+func (b *Builder) Generate(day time.Time) (*Daily, error) {
+	r := b.Build(day)
+	if err := b.save(r); err != nil {
+		return nil, err
+	}
+	log.Printf("[REPORT] Daily digest for %s generated with %d warnings", r.Date, len(r.Warnings))
+	return r, nil
+}
+
+func (b *Builder) save(r *Daily) error {
+	dir := filepath.Join(b.dir, r.Date)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return err
+	}
+	md, err := RenderMarkdown(r)
+	if err != nil {
+		return err
+	}
+	html, err := RenderHTML(r)
+	if err != nil {
+		return err
+	}
+
+	files := map[string][]byte{"report.json": data, "report.md": md, "report.html": html}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		tmp := path + ".tmp"
+		if err := os.WriteFile(tmp, content, 0644); err != nil {
+			return err
+		}
+		if err := os.Rename(tmp, path); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Path returns the stored file of a report in the given format (json, md
+// or html), or ErrNotFound
+func (b *Builder) Path(date, format string) (string, error) {
+	path := filepath.Join(b.dir, date, "report."+format)
+	if _, err := os.Stat(path); err != nil {
+		if os.IsNotExist(err) {
+			return "", ErrNotFound
+		}
+		return "", err
+	}
+	return path, nil
+}
+
+// Load reads a stored report
+func (b *Builder) Load(date string) (*Daily, error) {
+	path, err := b.Path(date, "json")
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var r Daily
+	if err := json.Unmarshal(data, &r); err != nil {
+		return nil, err
+	}
+	return &r, nil
+}
+
+// Dates lists the dates with a stored report, newest first
+func (b *Builder) Dates() ([]string, error) {
+	entries, err := os.ReadDir(b.dir)
+	if os.IsNotExist(err) {
+		return []string{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	dates := []string{}
+	for _, e := range entries {
+		if _, err := time.Parse("2006-01-02", e.Name()); err == nil && e.IsDir() {
+			dates = append(dates, e.Name())
+		}
+	}
+	sort.Sort(sort.Reverse(sort.StringSlice(dates)))
+	return dates, nil
+}
+
+// RunScheduled generates today's digest after the close and regenerates
+// the previous weekday's digest if it was built before all data arrived
+// (the Judal snapshot of a session is only crawled at midnight).
+func (b *Builder) RunScheduled() {
+	today, _ := ParseDate("today")
+
+	prev := today.AddDate(0, 0, -1)
+	for prev.Weekday() == time.Saturday || prev.Weekday() == time.Sunday {
+		prev = prev.AddDate(0, 0, -1)
+	}
+	if old, err := b.Load(prev.Format("2006-01-02")); err == nil && len(old.Warnings) > 0 {
+		if _, err := b.Generate(prev); err != nil {
+			log.Printf("[REPORT] Failed to regenerate digest for %s: %v", old.Date, err)
+		}
+	}
+
+	if _, err := b.Generate(today); err != nil {
+		log.Printf("[REPORT] Failed to generate digest for %s: %v", today.Format("2006-01-02"), err)
+	}
+}
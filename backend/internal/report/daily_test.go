@@ -0,0 +1,26 @@
+package report
+
+import (
+	"testing"
+
+	newsStore "dx-unified/internal/news/store"
+)
+
+func TestStoryKey(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b newsStore.ArticleDoc
+		same bool
+	}{
+		{"story id", newsStore.ArticleDoc{ID: "1", StoryID: "s", Title: "A"}, newsStore.ArticleDoc{ID: "2", StoryID: "s", Title: "B"}, true},
+		{"different stories", newsStore.ArticleDoc{ID: "1", StoryID: "s", Title: "A"}, newsStore.ArticleDoc{ID: "2", StoryID: "t", Title: "A"}, false},
+		{"title without labels", newsStore.ArticleDoc{ID: "1", Title: "[속보] 삼성전자, 2분기 실적 발표"}, newsStore.ArticleDoc{ID: "2", Title: "삼성전자 2분기 실적 발표(종합)"}, true},
+		{"different titles", newsStore.ArticleDoc{ID: "1", Title: "삼성전자 실적 발표"}, newsStore.ArticleDoc{ID: "2", Title: "SK하이닉스 실적 발표"}, false},
+		{"labels only", newsStore.ArticleDoc{ID: "1", Title: "[포토]"}, newsStore.ArticleDoc{ID: "2", Title: "[포토]"}, false},
+	}
+	for _, tt := range tests {
+		if got := storyKey(tt.a) == storyKey(tt.b); got != tt.same {
+			t.Errorf("%s: same = %v, want %v (%q, %q)", tt.name, got, tt.same, storyKey(tt.a), storyKey(tt.b))
+		}
+	}
+}
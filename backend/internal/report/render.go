@@ -0,0 +1,197 @@
+package report
+
+import (
+	"bytes"
+	"fmt"
+	htmltemplate "html/template"
+	"strings"
+	"text/template"
+	"time"
+
+	"dx-unified/internal/shared/timeutil"
+)
+
+var funcs = map[string]interface{}{
+	"pct": func(v float64) string { return fmt.Sprintf("%+.2f%%", v) },
+	"price": func(v float64) string {
+		if v == float64(int64(v)) {
+			return fmt.Sprintf("%d", int64(v))
+		}
+		return fmt.Sprintf("%.2f", v)
+	},
+	"kst": func(t time.Time) string { return t.In(timeutil.KST).Format("2006-01-02 15:04") },
+	// md escapes the characters that break Markdown table cells and links
+	"md": func(s string) string {
+		return strings.NewReplacer("|", "\\|", "[", "\\[", "]", "\\]", "\n", " ").Replace(s)
+	},
+	"events": func(events []Event) string {
+		types := make([]string, len(events))
+		for i, e := range events {
+			types[i] = e.Type
+		}
+		return strings.Join(types, ", ")
+	},
+}
+
+const markdownTemplate = `# 일일 시장 리포트 {{.Date}}
+
+생성 시각: {{kst .GeneratedAt}} KST
+{{if .Warnings}}
+## 데이터 경고
+{{range .Warnings}}
+- {{.}}
+{{- end}}
+{{end}}
+## 테마 등락{{if .Themes.SnapshotDate}} (Judal {{.Themes.SnapshotDate}}){{end}}
+
+### 상승 테마
+{{if .Themes.Rising}}
+| 테마 | 평균 등락률 | 종목 수 | 상승/하락 | 대장주 |
+|---|---:|---:|---:|---|
+{{- range .Themes.Rising}}
+| {{md .Name}} | {{pct .AvgChangeRate}} | {{.StockCount}} | {{.Advancers}}/{{.Decliners}} | {{md .LeaderName}} ({{pct .LeaderRate}}) |
+{{- end}}
+{{else}}
+없음
+{{end}}
+### 하락 테마
+{{if .Themes.Falling}}
+| 테마 | 평균 등락률 | 종목 수 | 상승/하락 | 대장주 |
+|---|---:|---:|---:|---|
+{{- range .Themes.Falling}}
+| {{md .Name}} | {{pct .AvgChangeRate}} | {{.StockCount}} | {{.Advancers}}/{{.Decliners}} | {{md .LeaderName}} ({{pct .LeaderRate}}) |
+{{- end}}
+{{else}}
+없음
+{{end}}
+## 급등락 종목 ({{.Movers.Market}}, {{.Movers.Symbols}}종목)
+
+### 상승
+{{if .Movers.Gainers}}
+| 종목 | 종가 | 등락률 | 거래량 |
+|---|---:|---:|---:|
+{{- range .Movers.Gainers}}
+| {{md .Name}} ({{.Symbol}}) | {{price .Close}} | {{pct .ChangePct}} | {{printf "%.0f" .Volume}} |
+{{- end}}
+{{else}}
+없음
+{{end}}
+### 하락
+{{if .Movers.Losers}}
+| 종목 | 종가 | 등락률 | 거래량 |
+|---|---:|---:|---:|
+{{- range .Movers.Losers}}
+| {{md .Name}} ({{.Symbol}}) | {{price .Close}} | {{pct .ChangePct}} | {{printf "%.0f" .Volume}} |
+{{- end}}
+{{else}}
+없음
+{{end}}
+## 주요 공시
+{{if .Filings}}
+| 회사 | 보고서 | 추출 이벤트 |
+|---|---|---|
+{{- range .Filings}}
+| {{md .CorpName}} ({{.StockCode}}) | [{{md .ReportNm}}]({{.URL}}) | {{events .Events}} |
+{{- end}}
+{{else}}
+없음
+{{end}}
+## 테마별 뉴스
+{{range .News}}
+### {{.Theme}} ({{pct .AvgChangeRate}})
+{{range .Stories}}
+- [{{md .Title}}]({{.URL}}) — {{.Publisher}}{{if gt .Articles 1}} 외 {{.Articles}}건{{end}}
+{{- end}}
+{{else}}
+없음
+{{end}}`
+
+const htmlTemplate = `<!DOCTYPE html>
+<html lang="ko">
+<head>
+<meta charset="utf-8">
+<title>일일 시장 리포트 {{.Date}}</title>
+<style>
+body { font-family: sans-serif; max-width: 960px; margin: 2em auto; color: #222; }
+table { border-collapse: collapse; width: 100%; margin-bottom: 1em; }
+th, td { border-bottom: 1px solid #ddd; padding: 4px 8px; text-align: left; }
+td.num { text-align: right; }
+.up { color: #d32f2f; }
+.down { color: #1565c0; }
+.warnings { background: #fff8e1; border: 1px solid #ffb300; padding: 0.5em 1em; }
+</style>
+</head>
+<body>
+<h1>일일 시장 리포트 {{.Date}}</h1>
+<p>생성 시각: {{kst .GeneratedAt}} KST</p>
+{{if .Warnings}}
+<div class="warnings">
+<h2>데이터 경고</h2>
+<ul>{{range .Warnings}}<li>{{.}}</li>{{end}}</ul>
+</div>
+{{end}}
+<h2>테마 등락{{if .Themes.SnapshotDate}} (Judal {{.Themes.SnapshotDate}}){{end}}</h2>
+{{define "themes"}}
+{{if .}}
+<table>
+<tr><th>테마</th><th>평균 등락률</th><th>종목 수</th><th>상승/하락</th><th>대장주</th></tr>
+{{range .}}<tr><td>{{.Name}}</td><td class="num {{if gt .AvgChangeRate 0.0}}up{{else}}down{{end}}">{{pct .AvgChangeRate}}</td><td class="num">{{.StockCount}}</td><td class="num">{{.Advancers}}/{{.Decliners}}</td><td>{{.LeaderName}} ({{pct .LeaderRate}})</td></tr>
+{{end}}</table>
+{{else}}<p>없음</p>{{end}}
+{{end}}
+<h3>상승 테마</h3>
+{{template "themes" .Themes.Rising}}
+<h3>하락 테마</h3>
+{{template "themes" .Themes.Falling}}
+<h2>급등락 종목 ({{.Movers.Market}}, {{.Movers.Symbols}}종목)</h2>
+{{define "movers"}}
+{{if .}}
+<table>
+<tr><th>종목</th><th>종가</th><th>등락률</th><th>거래량</th></tr>
+{{range .}}<tr><td>{{.Name}} ({{.Symbol}})</td><td class="num">{{price .Close}}</td><td class="num {{if gt .ChangePct 0.0}}up{{else}}down{{end}}">{{pct .ChangePct}}</td><td class="num">{{printf "%.0f" .Volume}}</td></tr>
+{{end}}</table>
+{{else}}<p>없음</p>{{end}}
+{{end}}
+<h3>상승</h3>
+{{template "movers" .Movers.Gainers}}
+<h3>하락</h3>
+{{template "movers" .Movers.Losers}}
+<h2>주요 공시</h2>
+{{if .Filings}}
+<table>
+<tr><th>회사</th><th>보고서</th><th>추출 이벤트</th></tr>
+{{range .Filings}}<tr><td>{{.CorpName}} ({{.StockCode}})</td><td><a href="{{.URL}}">{{.ReportNm}}</a></td><td>{{events .Events}}</td></tr>
+{{end}}</table>
+{{else}}<p>없음</p>{{end}}
+<h2>테마별 뉴스</h2>
+{{range .News}}
+<h3>{{.Theme}} ({{pct .AvgChangeRate}})</h3>
+<ul>{{range .Stories}}<li><a href="{{.URL}}">{{.Title}}</a> — {{.Publisher}}{{if gt .Articles 1}} 외 {{.Articles}}건{{end}}</li>{{end}}</ul>
+{{else}}<p>없음</p>
+{{end}}
+</body>
+</html>
+`
+
+var (
+	mdTmpl   = template.Must(template.New("daily.md").Funcs(funcs).Parse(markdownTemplate))
+	htmlTmpl = htmltemplate.Must(htmltemplate.New("daily.html").Funcs(funcs).Parse(htmlTemplate))
+)
+
+// RenderMarkdown renders a report as Markdown
+func RenderMarkdown(r *Daily) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := mdTmpl.Execute(&buf, r); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// RenderHTML renders a report as a standalone HTML page
+func RenderHTML(r *Daily) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := htmlTmpl.Execute(&buf, r); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
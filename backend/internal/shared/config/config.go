@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"log"
 	"os"
+	"strconv"
 
 	"github.com/joho/godotenv"
 )
@@ -24,6 +25,10 @@ type Config struct {
 	MeiliHost   string `json:"meili_host"`
 	MeiliAPIKey string `json:"meili_api_key"`
 
+	// News Store backend: "meili" (default) or "sqlite"
+	NewsStoreBackend string `json:"news_store_backend"`
+	NewsDBPath       string `json:"news_db_path"`
+
 	// DART API
 	DartAPIKey string `json:"dart_api_key"`
 
@@ -50,9 +55,16 @@ type Config struct {
 	// NewsAPI
 	NewsAPIKey string `json:"newsapi_key"`
 
+	// RSS/Atom publisher feeds
+	RSSFeeds []RSSFeed `json:"rss_feeds"`
+
 	// Judal
 	CrawlDelay int `json:"crawl_delay"`
 
+	// News Fetch Cursors (per-query high-water marks)
+	NewsCursorPath string `json:"news_cursor_path"`
+	NaverMaxPages  int    `json:"naver_max_pages"` // pages of 100 per query and run, capped by the API at 10
+
 	// News Filtering
 	NaverQueries       []string `json:"naver_queries"`
 	EconKeywordsAllow  []string `json:"econ_keywords_allow"`
@@ -60,8 +72,61 @@ type Config struct {
 	TitleKeywordsBlock []string `json:"title_keywords_block"`
 	GenericNewsBlock   []string `json:"generic_news_block"`
 
+	// News Body Extraction (optional enrichment stage)
+	NewsBodyExtraction bool              `json:"news_body_extraction"`
+	NewsBodyHostDelay  int               `json:"news_body_host_delay"` // ms between requests to the same host
+	NewsBodySelectors  map[string]string `json:"news_body_selectors"`  // host -> CSS selector override
+
 	// News Fetch Interval (Cron expression)
 	NewsFetchCron string `json:"news_fetch_cron"`
+
+	// News Fetch Execution
+	NewsFetchConcurrency int            `json:"news_fetch_concurrency"` // fetchers running at once
+	NewsFetchTimeout     int            `json:"news_fetch_timeout"`     // seconds per fetcher
+	NewsFetcherTimeouts  map[string]int `json:"news_fetcher_timeouts"`  // fetcher name -> seconds
+	NewsFetchRetries     int            `json:"news_fetch_retries"`     // attempts per request on 429/5xx
+
+	// Benchmark symbols for news event studies, keyed by KOSPI, KOSDAQ or US
+	EventStudyBenchmarks map[string]string `json:"event_study_benchmarks"`
+
+	// Trending keywords
+	NewsTrendingPath  string   `json:"news_trending_path"`  // hourly term counts snapshot
+	NewsTrendingTerms []string `json:"news_trending_terms"` // extra dictionary nouns
+
+	// News source request budgets (source -> requests), 0 or missing means unlimited
+	NewsQuotaPath      string         `json:"news_quota_path"`      // request counters snapshot
+	NewsDailyBudgets   map[string]int `json:"news_daily_budgets"`   // per KST day
+	NewsMonthlyBudgets map[string]int `json:"news_monthly_budgets"` // per KST month
+
+	// Runtime source/query registry, seeded from NaverQueries and RSSFeeds
+	NewsRegistryPath string `json:"news_registry_path"`
+
+	// Hive-partitioned Parquet copy of processed articles, read through DuckDB
+	NewsParquetDir string `json:"news_parquet_dir"`
+
+	// Daily market digest, generated after the KRX close
+	ReportDailyCron string `json:"report_daily_cron"`
+
+	// OpenDART requests per KST day shared by all DART jobs, 0 means unlimited
+	DartDailyLimit int    `json:"dart_daily_limit"`
+	DartBudgetPath string `json:"dart_budget_path"` // request counters snapshot
+
+	// DART document blobs: "local" (under StorageDir) or "s3" for
+	// S3-compatible object storage such as MinIO
+	DartDocStore string `json:"dart_doc_store"`
+	S3Endpoint   string `json:"s3_endpoint"` // e.g. http://localhost:9000
+	S3Region     string `json:"s3_region"`
+	S3Bucket     string `json:"s3_bucket"`
+	S3Prefix     string `json:"s3_prefix"` // key prefix inside the bucket
+	S3AccessKey  string `json:"s3_access_key"`
+	S3SecretKey  string `json:"s3_secret_key"`
+}
+
+// RSSFeed describes a single RSS 2.0 or Atom feed to poll
+type RSSFeed struct {
+	Name      string `json:"name"`
+	URL       string `json:"url"`
+	Publisher string `json:"publisher"` // falls back to the feed title when empty
 }
 
 // Load reads configuration from environment variables and optional config.json
@@ -92,6 +157,56 @@ func Load() *Config {
 		NaverQueries:      []string{"주식", "증시", "경제", "코스피", "코스닥"},
 		EconKeywordsAllow: []string{"금리", "투자", "실적", "상장", "매수", "매도"},
 		EconKeywordsBlock: []string{"부고", "인사", "결혼", "모집"},
+		// Economic dailies only. Exchange notice (KIND) and Bank of Korea
+		// feeds are addressed by board and menu IDs that change with site
+		// updates, so they are added per deployment through rss_feeds or
+		// POST /news/sources rather than shipped as defaults that go stale.
+		RSSFeeds: []RSSFeed{
+			{Name: "hankyung_economy", URL: "https://www.hankyung.com/feed/economy", Publisher: "한국경제"},
+			{Name: "hankyung_finance", URL: "https://www.hankyung.com/feed/finance", Publisher: "한국경제"},
+			{Name: "mk_stock", URL: "https://www.mk.co.kr/rss/50200011/", Publisher: "매일경제"},
+			{Name: "yna_economy", URL: "https://www.yna.co.kr/rss/economy.xml", Publisher: "연합뉴스"},
+		},
+		NewsStoreBackend:     getEnv("NEWS_STORE", "meili"),
+		NewsDBPath:           getEnv("NEWS_DB_PATH", "./data/news.db"),
+		NewsCursorPath:       getEnv("NEWS_CURSOR_PATH", "./data/news_cursors.json"),
+		NaverMaxPages:        10,
+		NewsBodyExtraction:   os.Getenv("NEWS_BODY_EXTRACTION") == "true",
+		NewsFetchConcurrency: 3,
+		NewsFetchTimeout:     180,
+		NewsFetcherTimeouts:  map[string]int{},
+		NewsFetchRetries:     3,
+		NewsBodyHostDelay:    2000,
+		NewsBodySelectors: map[string]string{
+			"n.news.naver.com": "#dic_area",
+			"www.hankyung.com": "#articletxt",
+			"www.mk.co.kr":     "div.news_cnt_detail_wrap",
+			"www.yna.co.kr":    "div.story-news.article",
+		},
+		EventStudyBenchmarks: map[string]string{
+			"KOSPI":  "069500", // KODEX 200
+			"KOSDAQ": "229200", // KODEX KOSDAQ150
+			"US":     "SPY",
+		},
+		NewsTrendingPath: getEnv("NEWS_TRENDING_PATH", "./data/news_trending.json"),
+		NewsQuotaPath:    getEnv("NEWS_QUOTA_PATH", "./data/news_quota.json"),
+		NewsDailyBudgets: map[string]int{
+			"newsapi":          100,   // developer plan
+			"naver_search_api": 25000, // search API daily limit
+		},
+		NewsMonthlyBudgets: map[string]int{},
+		NewsRegistryPath:   getEnv("NEWS_REGISTRY_PATH", "./data/news_registry.json"),
+		NewsParquetDir:     getEnv("NEWS_PARQUET_DIR", "./data/news_parquet"),
+		ReportDailyCron:    getEnv("REPORT_DAILY_CRON", "CRON_TZ=Asia/Seoul 0 18 * * 1-5"),
+		DartDailyLimit:     getEnvInt("DART_DAILY_LIMIT", 20000), // OpenDART limit per API key
+		DartBudgetPath:     getEnv("DART_BUDGET_PATH", "./data/dart_budget.json"),
+		DartDocStore:       getEnv("DART_DOC_STORE", "local"),
+		S3Endpoint:         os.Getenv("S3_ENDPOINT"),
+		S3Region:           getEnv("S3_REGION", "us-east-1"),
+		S3Bucket:           os.Getenv("S3_BUCKET"),
+		S3Prefix:           os.Getenv("S3_PREFIX"),
+		S3AccessKey:        os.Getenv("S3_ACCESS_KEY"),
+		S3SecretKey:        os.Getenv("S3_SECRET_KEY"),
 	}
 
 	// Try loading from data/config.json to override
@@ -101,14 +216,19 @@ func Load() *Config {
 
 	// Check if file exists
 	if _, err := os.Stat(configPath); err == nil {
-		file, err := os.Open(configPath)
+		data, err := os.ReadFile(configPath)
 		if err == nil {
-			defer file.Close()
 			var jsonCfg Config
-			decoder := json.NewDecoder(file)
-			if err := decoder.Decode(&jsonCfg); err == nil {
+			// Fields where an explicit zero differs from leaving them out
+			var explicit struct {
+				DartDailyLimit *int `json:"dart_daily_limit"`
+			}
+			if err := json.Unmarshal(data, &jsonCfg); err == nil {
 				log.Println("Loaded configuration overrides from config.json")
 				overrideConfig(cfg, &jsonCfg)
+				if json.Unmarshal(data, &explicit) == nil && explicit.DartDailyLimit != nil {
+					cfg.DartDailyLimit = *explicit.DartDailyLimit // 0 turns the limit off
+				}
 			} else {
 				log.Printf("Failed to parse config.json: %v", err)
 			}
@@ -155,9 +275,96 @@ func overrideConfig(base, override *Config) {
 	if override.NewsAPIKey != "" {
 		base.NewsAPIKey = override.NewsAPIKey
 	}
+	if override.NewsStoreBackend != "" {
+		base.NewsStoreBackend = override.NewsStoreBackend
+	}
+	if override.NewsDBPath != "" {
+		base.NewsDBPath = override.NewsDBPath
+	}
+	if override.NewsCursorPath != "" {
+		base.NewsCursorPath = override.NewsCursorPath
+	}
+	if override.NaverMaxPages > 0 {
+		base.NaverMaxPages = override.NaverMaxPages
+	}
+	if len(override.RSSFeeds) > 0 {
+		base.RSSFeeds = override.RSSFeeds
+	}
 	if override.NewsFetchCron != "" {
 		base.NewsFetchCron = override.NewsFetchCron
 	}
+	if override.NewsFetchConcurrency > 0 {
+		base.NewsFetchConcurrency = override.NewsFetchConcurrency
+	}
+	if override.NewsFetchTimeout > 0 {
+		base.NewsFetchTimeout = override.NewsFetchTimeout
+	}
+	for name, secs := range override.NewsFetcherTimeouts {
+		base.NewsFetcherTimeouts[name] = secs
+	}
+	if override.NewsFetchRetries > 0 {
+		base.NewsFetchRetries = override.NewsFetchRetries
+	}
+	if override.NewsBodyExtraction {
+		base.NewsBodyExtraction = true
+	}
+	if override.NewsBodyHostDelay > 0 {
+		base.NewsBodyHostDelay = override.NewsBodyHostDelay
+	}
+	for host, selector := range override.NewsBodySelectors {
+		base.NewsBodySelectors[host] = selector
+	}
+	for key, symbol := range override.EventStudyBenchmarks {
+		base.EventStudyBenchmarks[key] = symbol
+	}
+	if override.NewsTrendingPath != "" {
+		base.NewsTrendingPath = override.NewsTrendingPath
+	}
+	if len(override.NewsTrendingTerms) > 0 {
+		base.NewsTrendingTerms = override.NewsTrendingTerms
+	}
+	if override.NewsQuotaPath != "" {
+		base.NewsQuotaPath = override.NewsQuotaPath
+	}
+	for source, n := range override.NewsDailyBudgets {
+		base.NewsDailyBudgets[source] = n
+	}
+	for source, n := range override.NewsMonthlyBudgets {
+		base.NewsMonthlyBudgets[source] = n
+	}
+	if override.NewsRegistryPath != "" {
+		base.NewsRegistryPath = override.NewsRegistryPath
+	}
+	if override.NewsParquetDir != "" {
+		base.NewsParquetDir = override.NewsParquetDir
+	}
+	if override.ReportDailyCron != "" {
+		base.ReportDailyCron = override.ReportDailyCron
+	}
+	if override.DartBudgetPath != "" {
+		base.DartBudgetPath = override.DartBudgetPath
+	}
+	if override.DartDocStore != "" {
+		base.DartDocStore = override.DartDocStore
+	}
+	if override.S3Endpoint != "" {
+		base.S3Endpoint = override.S3Endpoint
+	}
+	if override.S3Region != "" {
+		base.S3Region = override.S3Region
+	}
+	if override.S3Bucket != "" {
+		base.S3Bucket = override.S3Bucket
+	}
+	if override.S3Prefix != "" {
+		base.S3Prefix = override.S3Prefix
+	}
+	if override.S3AccessKey != "" {
+		base.S3AccessKey = override.S3AccessKey
+	}
+	if override.S3SecretKey != "" {
+		base.S3SecretKey = override.S3SecretKey
+	}
 	if override.CrawlDelay > 0 {
 		base.CrawlDelay = override.CrawlDelay
 	}
@@ -170,3 +377,17 @@ func getEnv(key, defaultVal string) string {
 	}
 	return defaultVal
 }
+
+// getEnvInt reads an integer variable; a set "0" is kept, unlike getEnv
+func getEnvInt(key string, defaultVal int) int {
+	val := os.Getenv(key)
+	if val == "" {
+		return defaultVal
+	}
+	n, err := strconv.Atoi(val)
+	if err != nil {
+		log.Printf("Invalid %s %q, using %d", key, val, defaultVal)
+		return defaultVal
+	}
+	return n
+}
@@ -0,0 +1,39 @@
+package fts
+
+import "strings"
+
+// Query helpers shared by the SQLite FTS5 trigram indexes of news articles
+// and DART filings
+
+// SplitTerms builds an FTS5 MATCH expression from the terms whose
+// alternatives (a term and its synonyms) all have 3+ characters, the
+// shortest the trigram tokenizer matches. A term matches when any of its
+// alternatives does and all terms must match. The other terms are returned
+// separately for a LIKE fallback.
+func SplitTerms(terms [][]string) (string, [][]string) {
+	var groups []string
+	var short [][]string
+	for _, alts := range terms {
+		var phrases []string
+		for _, term := range alts {
+			if len([]rune(term)) < 3 {
+				phrases = nil
+				break
+			}
+			phrases = append(phrases, `"`+strings.ReplaceAll(term, `"`, `""`)+`"`)
+		}
+		if phrases == nil {
+			short = append(short, alts)
+			continue
+		}
+		groups = append(groups, "("+strings.Join(phrases, " OR ")+")")
+	}
+	return strings.Join(groups, " AND "), short
+}
+
+// EscapeLike escapes the LIKE wildcards of s for use with ESCAPE '\'
+func EscapeLike(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, "%", `\%`)
+	return strings.ReplaceAll(s, "_", `\_`)
+}
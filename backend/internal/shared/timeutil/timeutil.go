@@ -0,0 +1,42 @@
+package timeutil
+
+import (
+	"fmt"
+	"time"
+)
+
+// KST is Korea Standard Time, the zone of KRX trading days, OpenDART dates
+// and the daily quotas. A fixed +09:00 zone is used when the tz database
+// is missing (Korea has no daylight saving time).
+var KST = func() *time.Location {
+	loc, err := time.LoadLocation("Asia/Seoul")
+	if err != nil {
+		return time.FixedZone("KST", 9*60*60)
+	}
+	return loc
+}()
+
+// Today returns the start of the current KST day
+func Today() time.Time {
+	now := time.Now().In(KST)
+	return time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, KST)
+}
+
+// ParseDate parses a YYYY-MM-DD or YYYYMMDD date as the start of a KST day
+func ParseDate(s string) (time.Time, error) {
+	for _, layout := range []string{"2006-01-02", "20060102"} {
+		if d, err := time.ParseInLocation(layout, s, KST); err == nil {
+			return d, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid date %q, expected YYYY-MM-DD or YYYYMMDD", s)
+}
+
+// DashDate turns a YYYYMMDD date, as OpenDART returns it, into YYYY-MM-DD.
+// Other values are returned unchanged.
+func DashDate(yyyymmdd string) string {
+	if len(yyyymmdd) != 8 {
+		return yyyymmdd
+	}
+	return yyyymmdd[:4] + "-" + yyyymmdd[4:6] + "-" + yyyymmdd[6:]
+}
@@ -0,0 +1,23 @@
+package timeutil
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseDate(t *testing.T) {
+	want := time.Date(2026, 3, 2, 0, 0, 0, 0, KST)
+	for _, s := range []string{"2026-03-02", "20260302"} {
+		if got, err := ParseDate(s); err != nil || !got.Equal(want) {
+			t.Errorf("ParseDate(%q) = %v, %v", s, got, err)
+		}
+	}
+	for _, s := range []string{"", "2026/03/02", "2026-13-01"} {
+		if _, err := ParseDate(s); err == nil {
+			t.Errorf("ParseDate(%q) succeeded", s)
+		}
+	}
+	if got := DashDate("20260302"); got != "2026-03-02" {
+		t.Errorf("DashDate = %q", got)
+	}
+}
@@ -0,0 +1,281 @@
+package dart
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"dx-unified/internal/shared/timeutil"
+)
+
+// Priority orders the work sharing the daily request quota. Lower
+// priorities stop earlier so the remaining requests go to new filings.
+type Priority int
+
+const (
+	PriorityFilings   Priority = iota // recent filing lists and corp codes
+	PriorityDocuments                 // document downloads
+	PriorityBackfill                  // historical backfills
+)
+
+// Share of the daily limit each priority may use
+var priorityShare = map[Priority]float64{
+	PriorityFilings:   1.0,
+	PriorityDocuments: 0.9,
+	PriorityBackfill:  0.7,
+}
+
+func (p Priority) String() string {
+	switch p {
+	case PriorityFilings:
+		return "filings"
+	case PriorityDocuments:
+		return "documents"
+	case PriorityBackfill:
+		return "backfill"
+	}
+	return fmt.Sprintf("priority(%d)", int(p))
+}
+
+// StatusOverLimit is the OpenDART status for an exceeded request quota
+const StatusOverLimit = "020"
+
+// ErrBudgetExhausted is returned instead of making a request once the
+// daily budget of the caller's priority is used up
+var ErrBudgetExhausted = errors.New("OpenDART daily request budget exhausted")
+
+type budgetState struct {
+	Day        string         `json:"day"` // KST date the counters belong to
+	Used       int            `json:"used"`
+	ByPriority map[string]int `json:"by_priority"`
+	Refused    map[string]int `json:"refused"`
+	OverLimit  bool           `json:"over_limit"` // OpenDART answered 020 today
+	LastAt     time.Time      `json:"last_at"`
+	History    map[string]int `json:"history"` // requests of previous days
+}
+
+// budgetSaveInterval is how often Run writes changed counters to disk
+const budgetSaveInterval = 30 * time.Second
+
+// Budget counts the OpenDART requests of one API key per KST day, which is
+// when the quota resets. It is shared by every job using the key and
+// persisted so restarts keep the count: Run saves the counters in the
+// background, requests themselves never write to disk.
+type Budget struct {
+	path  string
+	limit int
+	now   func() time.Time // clock, replaced in tests
+
+	mu     sync.Mutex
+	state  budgetState
+	dirty  bool       // counters changed since the last save
+	saveMu sync.Mutex // serializes writes of the snapshot file
+}
+
+// NewBudget creates a budget of limit requests per day (0 means unlimited)
+// persisted at path, loading any saved counters
+func NewBudget(path string, limit int) *Budget {
+	b := &Budget{path: path, limit: limit, now: time.Now}
+	if data, err := os.ReadFile(path); err == nil {
+		_ = json.Unmarshal(data, &b.state)
+	}
+	return b
+}
+
+// rolloverLocked resets the counters when the KST day changed
+func (b *Budget) rolloverLocked(now time.Time) {
+	day := now.In(timeutil.KST).Format("2006-01-02")
+	if b.state.Day == day {
+		return
+	}
+	if b.state.History == nil {
+		b.state.History = make(map[string]int)
+	}
+	if b.state.Day != "" {
+		b.state.History[b.state.Day] = b.state.Used
+	}
+	// Keep a week of history
+	oldest := now.In(timeutil.KST).AddDate(0, 0, -7).Format("2006-01-02")
+	for d := range b.state.History {
+		if d < oldest {
+			delete(b.state.History, d)
+		}
+	}
+	b.state.Day = day
+	b.state.Used = 0
+	b.state.ByPriority = make(map[string]int)
+	b.state.Refused = make(map[string]int)
+	b.state.OverLimit = false
+}
+
+// Acquire counts one request of priority p, or returns ErrBudgetExhausted
+// when the priority's share of the limit is used up or OpenDART already
+// reported the quota exceeded today
+func (b *Budget) Acquire(p Priority) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := b.now()
+	b.rolloverLocked(now)
+	if b.pausedLocked(p) {
+		b.state.Refused[p.String()]++
+		b.dirty = true
+		return fmt.Errorf("%w (%s, %d/%d)", ErrBudgetExhausted, p, b.state.Used, b.limit)
+	}
+	b.state.Used++
+	b.state.ByPriority[p.String()]++
+	b.state.LastAt = now
+	b.dirty = true
+	return nil
+}
+
+// pausedLocked reports whether priority p has used its share of the limit
+func (b *Budget) pausedLocked(p Priority) bool {
+	return b.state.OverLimit || (b.limit > 0 && float64(b.state.Used) >= priorityShare[p]*float64(b.limit))
+}
+
+// MarkOverLimit records that OpenDART refused a request with status 020;
+// no more requests are made until the next KST day
+func (b *Budget) MarkOverLimit() {
+	b.mu.Lock()
+	b.rolloverLocked(b.now())
+	b.state.OverLimit = true
+	b.mu.Unlock()
+	b.Save()
+}
+
+// Available reports whether a request of priority p would be allowed now
+func (b *Budget) Available(p Priority) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.rolloverLocked(b.now())
+	return !b.pausedLocked(p)
+}
+
+// BudgetReport is the request budget state for /admin/status
+type BudgetReport struct {
+	Day        string         `json:"day"`
+	Limit      int            `json:"limit"`
+	Used       int            `json:"used"`
+	Remaining  int            `json:"remaining"`         // -1 when unlimited
+	Paused     []string       `json:"paused"`            // priorities currently refused
+	OverLimit  bool           `json:"over_limit"`        // OpenDART returned 020 today
+	ByPriority map[string]int `json:"by_priority"`       // requests made today
+	Refused    map[string]int `json:"refused"`           // requests refused today
+	History    map[string]int `json:"history,omitempty"` // requests of the previous days
+	LastAt     time.Time      `json:"last_request_at"`
+	ResetsAt   time.Time      `json:"resets_at"`
+}
+
+// Report returns today's usage
+func (b *Budget) Report() BudgetReport {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := b.now()
+	b.rolloverLocked(now)
+	local := now.In(timeutil.KST)
+
+	r := BudgetReport{
+		Day:        b.state.Day,
+		Limit:      b.limit,
+		Used:       b.state.Used,
+		Remaining:  -1,
+		Paused:     []string{},
+		OverLimit:  b.state.OverLimit,
+		ByPriority: copyCounts(b.state.ByPriority),
+		Refused:    copyCounts(b.state.Refused),
+		History:    copyCounts(b.state.History),
+		LastAt:     b.state.LastAt,
+		ResetsAt:   time.Date(local.Year(), local.Month(), local.Day()+1, 0, 0, 0, 0, timeutil.KST),
+	}
+	if b.limit > 0 {
+		r.Remaining = b.limit - b.state.Used
+		if r.Remaining < 0 {
+			r.Remaining = 0
+		}
+	}
+	for _, p := range []Priority{PriorityFilings, PriorityDocuments, PriorityBackfill} {
+		if b.pausedLocked(p) {
+			r.Paused = append(r.Paused, p.String())
+		}
+	}
+	return r
+}
+
+func copyCounts(m map[string]int) map[string]int {
+	out := make(map[string]int, len(m))
+	for k, v := range m {
+		out[k] = v
+	}
+	return out
+}
+
+// Run saves changed counters every budgetSaveInterval until ctx is done.
+// Callers still Save once on shutdown for the requests of the last interval.
+func (b *Budget) Run(ctx context.Context) {
+	ticker := time.NewTicker(budgetSaveInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			b.mu.Lock()
+			dirty := b.dirty
+			b.mu.Unlock()
+			if !dirty {
+				continue
+			}
+			if err := b.Save(); err != nil {
+				log.Printf("[DART] Failed to save request budget: %v", err)
+			}
+		}
+	}
+}
+
+// Save writes the counters to disk atomically
+func (b *Budget) Save() error {
+	b.saveMu.Lock()
+	defer b.saveMu.Unlock()
+
+	b.mu.Lock()
+	data, err := json.Marshal(b.state)
+	b.dirty = false
+	b.mu.Unlock()
+	if err == nil {
+		err = b.write(data)
+	}
+	if err != nil {
+		// Try again with the next save
+		b.mu.Lock()
+		b.dirty = true
+		b.mu.Unlock()
+	}
+	return err
+}
+
+func (b *Budget) write(data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(b.path), 0755); err != nil {
+		return err
+	}
+	tmp := b.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, b.path)
+}
+
+// IsBudgetError reports whether err means the daily quota is used up, by
+// our budget or by OpenDART itself, rather than a failure of the request
+func IsBudgetError(err error) bool {
+	var apiErr *APIError
+	return errors.Is(err, ErrBudgetExhausted) || (errors.As(err, &apiErr) && apiErr.Status == StatusOverLimit)
+}
@@ -0,0 +1,123 @@
+package dart
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"dx-unified/internal/shared/timeutil"
+)
+
+// TestBudgetSave checks that requests only count in memory until the
+// counters are saved, and that saved counters survive a restart
+func TestBudgetSave(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "budget.json")
+	b := NewBudget(path, 10)
+	for i := 0; i < 3; i++ {
+		if err := b.Acquire(PriorityFilings); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Fatalf("budget written before Save: %v", err)
+	}
+	if !b.dirty {
+		t.Error("budget not dirty after requests")
+	}
+	if err := b.Save(); err != nil {
+		t.Fatal(err)
+	}
+	if b.dirty {
+		t.Error("budget dirty after Save")
+	}
+	if r := NewBudget(path, 10).Report(); r.Used != 3 || r.Remaining != 7 {
+		t.Errorf("reloaded budget used %d, remaining %d; want 3, 7", r.Used, r.Remaining)
+	}
+}
+
+func TestBudgetUnlimited(t *testing.T) {
+	b := NewBudget(filepath.Join(t.TempDir(), "budget.json"), 0)
+	for i := 0; i < 100; i++ {
+		if err := b.Acquire(PriorityBackfill); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if r := b.Report(); r.Remaining != -1 || len(r.Paused) != 0 {
+		t.Errorf("Report = %+v", r)
+	}
+}
+
+// TestBudgetPriorities checks the share of a limit of 10 requests each
+// priority may use, and that a 020 answer stops every priority
+func TestBudgetPriorities(t *testing.T) {
+	tests := []struct {
+		name      string
+		used      int
+		overLimit bool
+		filings   bool
+		documents bool
+		backfill  bool
+	}{
+		{"unused", 0, false, true, true, true},
+		{"below 70%", 6, false, true, true, true},
+		{"at 70%", 7, false, true, true, false},
+		{"at 90%", 9, false, true, false, false},
+		{"at the limit", 10, false, false, false, false},
+		{"over limit answered", 1, true, false, false, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := NewBudget(filepath.Join(t.TempDir(), "budget.json"), 10)
+			for i := 0; i < tt.used; i++ {
+				if err := b.Acquire(PriorityFilings); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if tt.overLimit {
+				b.MarkOverLimit()
+			}
+			for p, want := range map[Priority]bool{PriorityFilings: tt.filings, PriorityDocuments: tt.documents, PriorityBackfill: tt.backfill} {
+				if got := b.Available(p); got != want {
+					t.Errorf("Available(%s) = %v, want %v", p, got, want)
+				}
+				err := b.Acquire(p)
+				if want && err != nil {
+					t.Errorf("Acquire(%s) = %v", p, err)
+				}
+				if !want && !IsBudgetError(err) {
+					t.Errorf("Acquire(%s) = %v, want a budget error", p, err)
+				}
+				if err == nil {
+					b.state.Used-- // keep the count of the case
+				}
+			}
+		})
+	}
+}
+
+// TestBudgetRollover checks that the counters and a 020 answer reset at
+// midnight KST, not UTC
+func TestBudgetRollover(t *testing.T) {
+	b := NewBudget(filepath.Join(t.TempDir(), "budget.json"), 10)
+	now := time.Date(2026, 3, 2, 23, 59, 0, 0, timeutil.KST)
+	b.now = func() time.Time { return now }
+
+	for i := 0; i < 10; i++ {
+		b.Acquire(PriorityFilings)
+	}
+	b.MarkOverLimit()
+	if b.Available(PriorityFilings) {
+		t.Fatal("filings available at the limit")
+	}
+
+	// 2026-03-03 00:01 KST is still 2026-03-02 in UTC
+	now = now.Add(2 * time.Minute)
+	if !b.Available(PriorityBackfill) {
+		t.Error("backfill not available on the next KST day")
+	}
+	r := b.Report()
+	if r.Day != "2026-03-03" || r.Used != 0 || r.History["2026-03-02"] != 10 {
+		t.Errorf("Report = %+v", r)
+	}
+}
@@ -6,11 +6,13 @@ import (
 	"crypto/tls"
 	"encoding/json"
 	"encoding/xml"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"net/url"
 	"os"
+	"strings"
 	"time"
 
 	"dx-unified/internal/dart/models"
@@ -21,6 +23,9 @@ const BaseURL = "https://opendart.fss.or.kr/api"
 type Client struct {
 	APIKey     string
 	HTTPClient *http.Client
+	Budget     *Budget // shared daily request budget, nil means unlimited
+
+	priority Priority
 }
 
 func NewClient(apiKey string) *Client {
@@ -44,6 +49,34 @@ func NewClient(apiKey string) *Client {
 	}
 }
 
+// WithPriority returns a client sharing c's budget whose requests count
+// as priority p
+func (c *Client) WithPriority(p Priority) *Client {
+	cp := *c
+	cp.priority = p
+	return &cp
+}
+
+// get makes a GET request against the budget
+func (c *Client) get(apiURL string) (*http.Response, error) {
+	if c.Budget != nil {
+		if err := c.Budget.Acquire(c.priority); err != nil {
+			return nil, err
+		}
+	}
+	return c.HTTPClient.Get(apiURL)
+}
+
+// checkStatus stops further requests for the day when OpenDART reports
+// the quota exceeded
+func (c *Client) checkStatus(err error) error {
+	var apiErr *APIError
+	if c.Budget != nil && errors.As(err, &apiErr) && apiErr.Status == StatusOverLimit {
+		c.Budget.MarkOverLimit()
+	}
+	return err
+}
+
 // Responses for Corp Code
 type corpCodeResult struct {
 	XMLName xml.Name `xml:"result"`
@@ -62,13 +95,14 @@ type filingListResponse struct {
 	PageNo  int             `json:"page_no"`
 	PageCo  int             `json:"page_count"`
 	TotalCo int             `json:"total_count"`
+	TotalPg int             `json:"total_page"`
 	List    []models.Filing `json:"list"`
 }
 
 // GetCorpCode downloads the ZIP file, extracts XML, and parses it
 func (c *Client) GetCorpCode() ([]models.Corp, error) {
 	apiURL := fmt.Sprintf("%s/corpCode.xml?crtfc_key=%s", BaseURL, c.APIKey)
-	resp, err := c.HTTPClient.Get(apiURL)
+	resp, err := c.get(apiURL)
 	if err != nil {
 		return nil, fmt.Errorf("failed to download corpCode: %w", err)
 	}
@@ -86,6 +120,10 @@ func (c *Client) GetCorpCode() ([]models.Corp, error) {
 	// Unzip
 	zipReader, err := zip.NewReader(bytes.NewReader(bodyBytes), int64(len(bodyBytes)))
 	if err != nil {
+		var apiErr APIError
+		if xml.Unmarshal(bodyBytes, &apiErr) == nil && apiErr.Status != "" {
+			return nil, c.checkStatus(&apiErr)
+		}
 		return nil, fmt.Errorf("failed to parse zip: %w", err)
 	}
 
@@ -120,20 +158,42 @@ func (c *Client) GetCorpCode() ([]models.Corp, error) {
 		corps = append(corps, models.Corp{
 			CorpCode:   item.CorpCode,
 			CorpName:   item.CorpName,
-			StockCode:  item.StockCode,
+			StockCode:  strings.TrimSpace(item.StockCode), // " " for unlisted corps
 			ModifiedAt: modTime,
 		})
 	}
 	return corps, nil
 }
 
+// ListParams selects filings of list.json. Dates are YYYYMMDD; CorpCode
+// and FilingType (pblntf_ty, e.g. "A" periodic, "B" major events) are
+// optional. list.json reports neither pblntf_ty nor pblntf_detail_ty, so
+// FilingType only narrows the request; the taxonomy derives both fields.
+type ListParams struct {
+	BeginDate  string
+	EndDate    string
+	CorpCode   string
+	FilingType string
+}
+
 // GetDailyFilings fetches filings for a specific date (YYYYMMDD)
 func (c *Client) GetDailyFilings(date string) ([]models.Filing, error) {
+	return c.GetFilings(ListParams{BeginDate: date, EndDate: date})
+}
+
+// GetFilings fetches all pages of filings matching p
+func (c *Client) GetFilings(p ListParams) ([]models.Filing, error) {
 	queryParams := url.Values{}
 	queryParams.Add("crtfc_key", c.APIKey)
-	queryParams.Add("bgn_de", date)
-	queryParams.Add("end_de", date)
+	queryParams.Add("bgn_de", p.BeginDate)
+	queryParams.Add("end_de", p.EndDate)
 	queryParams.Add("page_count", "100")
+	if p.CorpCode != "" {
+		queryParams.Add("corp_code", p.CorpCode)
+	}
+	if p.FilingType != "" {
+		queryParams.Add("pblntf_ty", p.FilingType)
+	}
 
 	var allFilings []models.Filing
 	page := 1
@@ -142,7 +202,7 @@ func (c *Client) GetDailyFilings(date string) ([]models.Filing, error) {
 		queryParams.Set("page_no", fmt.Sprintf("%d", page))
 		apiURL := fmt.Sprintf("%s/list.json?%s", BaseURL, queryParams.Encode())
 
-		resp, err := c.HTTPClient.Get(apiURL)
+		resp, err := c.get(apiURL)
 		if err != nil {
 			return nil, fmt.Errorf("failed to fetch list: %w", err)
 		}
@@ -161,12 +221,12 @@ func (c *Client) GetDailyFilings(date string) ([]models.Filing, error) {
 			if result.Status == "013" {
 				break
 			}
-			return nil, fmt.Errorf("API error %s: %s", result.Status, result.Message)
+			return nil, c.checkStatus(&APIError{Status: result.Status, Message: result.Message})
 		}
 
 		allFilings = append(allFilings, result.List...)
 
-		if page >= result.PageCo {
+		if page >= result.TotalPg || len(result.List) == 0 {
 			break
 		}
 		page++
@@ -175,11 +235,37 @@ func (c *Client) GetDailyFilings(date string) ([]models.Filing, error) {
 	return allFilings, nil
 }
 
-// DownloadDocument downloads the document ZIP for a given rcept_no
+// APIError is an error status returned by OpenDART, e.g. "013" (no data),
+// "014" (file not found) or "020" (request limit exceeded)
+type APIError struct {
+	Status  string `xml:"status"`
+	Message string `xml:"message"`
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("API error %s: %s", e.Status, e.Message)
+}
+
+// OpenDART statuses for a request that will not succeed when repeated
+const (
+	StatusNoData       = "013"
+	StatusFileNotFound = "014"
+)
+
+// IsPermanentError reports whether OpenDART answered that the requested
+// data or file does not exist, so retrying the request does not help
+func IsPermanentError(err error) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && (apiErr.Status == StatusNoData || apiErr.Status == StatusFileNotFound)
+}
+
+// DownloadDocument downloads the document ZIP for a given rcept_no. The
+// file is written next to destPath and renamed into place only once it is
+// a valid zip; OpenDART reports errors as an XML body with status 200.
 func (c *Client) DownloadDocument(rceptNo string, destPath string) error {
 	apiURL := fmt.Sprintf("%s/document.xml?crtfc_key=%s&rcept_no=%s", BaseURL, c.APIKey, rceptNo)
 
-	resp, err := c.HTTPClient.Get(apiURL)
+	resp, err := c.get(apiURL)
 	if err != nil {
 		return err
 	}
@@ -189,12 +275,42 @@ func (c *Client) DownloadDocument(rceptNo string, destPath string) error {
 		return fmt.Errorf("status %d", resp.StatusCode)
 	}
 
-	out, err := os.Create(destPath)
+	tmpPath := destPath + ".tmp"
+	out, err := os.Create(tmpPath)
 	if err != nil {
 		return err
 	}
-	defer out.Close()
-
 	_, err = io.Copy(out, resp.Body)
-	return err
+	if closeErr := out.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = validateZip(tmpPath)
+	}
+	if err != nil {
+		os.Remove(tmpPath)
+		return c.checkStatus(err)
+	}
+	return os.Rename(tmpPath, destPath)
+}
+
+// validateZip checks that path is a readable, non-empty zip archive
+func validateZip(path string) error {
+	zr, err := zip.OpenReader(path)
+	if err != nil {
+		data, readErr := os.ReadFile(path)
+		if readErr == nil {
+			var apiErr APIError
+			if xml.Unmarshal(data, &apiErr) == nil && apiErr.Status != "" {
+				return &apiErr
+			}
+		}
+		return fmt.Errorf("invalid zip: %w", err)
+	}
+	defer zr.Close()
+
+	if len(zr.File) == 0 {
+		return fmt.Errorf("invalid zip: no files")
+	}
+	return nil
 }
@@ -0,0 +1,175 @@
+package dart
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"dx-unified/internal/dart/models"
+)
+
+// apiStatus is the status part of OpenDART JSON responses
+type apiStatus struct {
+	Status  string `json:"status"`
+	Message string `json:"message"`
+}
+
+// apiError returns the error of the response; "013" (no data) is none
+func (s *apiStatus) apiError() *APIError {
+	if s.Status == "000" || s.Status == "013" {
+		return nil
+	}
+	return &APIError{Status: s.Status, Message: s.Message}
+}
+
+// majorstock.json: reports of holders of 5% or more (주식등의 대량보유상황보고서)
+type majorStockResponse struct {
+	apiStatus
+	List []struct {
+		RceptNo    string `json:"rcept_no"`
+		RceptDt    string `json:"rcept_dt"` // YYYY-MM-DD
+		CorpCode   string `json:"corp_code"`
+		CorpName   string `json:"corp_name"`
+		ReportTp   string `json:"report_tp"` // 일반, 약식
+		Repror     string `json:"repror"`
+		Stkqy      string `json:"stkqy"`
+		StkqyIrds  string `json:"stkqy_irds"`
+		Stkrt      string `json:"stkrt"`
+		StkrtIrds  string `json:"stkrt_irds"`
+		ReportResn string `json:"report_resn"`
+	} `json:"list"`
+}
+
+// elestock.json: ownership reports of executives and major shareholders
+// (임원ㆍ주요주주 소유보고)
+type executiveStockResponse struct {
+	apiStatus
+	List []struct {
+		RceptNo       string `json:"rcept_no"`
+		RceptDt       string `json:"rcept_dt"` // YYYY-MM-DD
+		CorpCode      string `json:"corp_code"`
+		CorpName      string `json:"corp_name"`
+		Repror        string `json:"repror"`
+		ExctvRgistAt  string `json:"isu_exctv_rgist_at"` // 등기임원, 비등기임원
+		ExctvOfcps    string `json:"isu_exctv_ofcps"`    // position
+		MainShrholdr  string `json:"isu_main_shrholdr"`  // 주요주주 relation
+		StockCnt      string `json:"sp_stock_lmp_cnt"`
+		StockIrdsCnt  string `json:"sp_stock_lmp_irds_cnt"`
+		StockRate     string `json:"sp_stock_lmp_rate"`
+		StockIrdsRate string `json:"sp_stock_lmp_irds_rate"`
+	} `json:"list"`
+}
+
+// GetMajorHoldings fetches the 5% holder reports of a corp
+func (c *Client) GetMajorHoldings(corpCode string) ([]models.HoldingChange, error) {
+	var result majorStockResponse
+	if err := c.getJSON("majorstock.json", corpCode, &result); err != nil {
+		return nil, err
+	}
+
+	changes := make([]models.HoldingChange, 0, len(result.List))
+	for _, item := range result.List {
+		changes = append(changes, models.HoldingChange{
+			RceptNo:      item.RceptNo,
+			RceptDt:      compactDate(item.RceptDt),
+			CorpCode:     item.CorpCode,
+			CorpName:     item.CorpName,
+			Kind:         models.HoldingMajor,
+			Holder:       strings.TrimSpace(item.Repror),
+			Relation:     item.ReportTp,
+			Shares:       parseAmount(item.Stkqy),
+			SharesChange: parseAmount(item.StkqyIrds),
+			Ratio:        parseRatio(item.Stkrt),
+			RatioChange:  parseRatio(item.StkrtIrds),
+			Reason:       strings.TrimSpace(item.ReportResn),
+		})
+	}
+	return changes, nil
+}
+
+// GetInsiderHoldings fetches the executive and major shareholder
+// ownership reports of a corp
+func (c *Client) GetInsiderHoldings(corpCode string) ([]models.HoldingChange, error) {
+	var result executiveStockResponse
+	if err := c.getJSON("elestock.json", corpCode, &result); err != nil {
+		return nil, err
+	}
+
+	changes := make([]models.HoldingChange, 0, len(result.List))
+	for _, item := range result.List {
+		relation := item.ExctvRgistAt
+		if item.MainShrholdr != "" && item.MainShrholdr != "-" {
+			relation = item.MainShrholdr
+		}
+		changes = append(changes, models.HoldingChange{
+			RceptNo:      item.RceptNo,
+			RceptDt:      compactDate(item.RceptDt),
+			CorpCode:     item.CorpCode,
+			CorpName:     item.CorpName,
+			Kind:         models.HoldingInsider,
+			Holder:       strings.TrimSpace(item.Repror),
+			Position:     strings.TrimSpace(item.ExctvOfcps),
+			Relation:     relation,
+			Shares:       parseAmount(item.StockCnt),
+			SharesChange: parseAmount(item.StockIrdsCnt),
+			Ratio:        parseRatio(item.StockRate),
+			RatioChange:  parseRatio(item.StockIrdsRate),
+		})
+	}
+	return changes, nil
+}
+
+// getJSON requests an endpoint taking only corp_code and decodes it into
+// result; "013" (no data) leaves result empty
+func (c *Client) getJSON(endpoint, corpCode string, result interface{ apiError() *APIError }) error {
+	queryParams := url.Values{}
+	queryParams.Add("crtfc_key", c.APIKey)
+	queryParams.Add("corp_code", corpCode)
+	apiURL := fmt.Sprintf("%s/%s?%s", BaseURL, endpoint, queryParams.Encode())
+
+	resp, err := c.get(apiURL)
+	if err != nil {
+		return fmt.Errorf("failed to fetch %s: %w", endpoint, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("API status %d", resp.StatusCode)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(result); err != nil {
+		return fmt.Errorf("json decode error: %w", err)
+	}
+	if apiErr := result.apiError(); apiErr != nil {
+		return c.checkStatus(apiErr)
+	}
+	return nil
+}
+
+// compactDate turns YYYY-MM-DD into YYYYMMDD like rcept_dt of list.json
+func compactDate(s string) string {
+	return strings.ReplaceAll(strings.TrimSpace(s), "-", "")
+}
+
+// parseAmount parses share counts such as "1,234,567" or "-12,000"; "-"
+// and empty values are 0
+func parseAmount(s string) int64 {
+	s = strings.ReplaceAll(strings.TrimSpace(s), ",", "")
+	n, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return 0
+	}
+	return n
+}
+
+// parseRatio parses percentages such as "5.12" or "-0.30"
+func parseRatio(s string) float64 {
+	s = strings.ReplaceAll(strings.TrimSpace(s), ",", "")
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0
+	}
+	return f
+}